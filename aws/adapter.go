@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net"
 	"net/http"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +25,7 @@ import (
 	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/linki/instrumented_http"
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando-incubator/kube-ingress-aws-controller/certs"
@@ -36,6 +41,7 @@ type Adapter struct {
 	acm            ACMAPI
 	iam            IAMAPI
 	cloudformation CloudFormationAPI
+	s3             S3API
 
 	manifest                    *manifest
 	healthCheckPath             string
@@ -55,6 +61,7 @@ type Adapter struct {
 	deregistrationDelayTimeout  time.Duration
 	TargetedAutoScalingGroups   map[string]*autoScalingGroupDetails
 	OwnedAutoScalingGroups      map[string]*autoScalingGroupDetails
+	FleetAutoScalingGroups      map[string]map[string]*autoScalingGroupDetails
 	ec2Details                  map[string]*instanceDetails
 	singleInstances             map[string]*instanceDetails
 	obsoleteInstances           []string
@@ -66,6 +73,8 @@ type Adapter struct {
 	albLogsS3Bucket             string
 	albLogsS3Prefix             string
 	nlbZoneAffinity             string
+	targetProtocolVersion       string
+	healthCheckMatcher          string
 	httpRedirectToHTTPS         bool
 	nlbCrossZone                bool
 	nlbHTTPEnabled              bool
@@ -75,7 +84,19 @@ type Adapter struct {
 	denyInternalRespBody        string
 	denyInternalRespContentType string
 	denyInternalRespStatusCode  int
+	sourceRangesDenyResponse    denyResp
+	maintenanceResponse         denyResp
+	targetGroupAttributes       map[string]map[string]string
+	loadBalancerAttributes      map[string]string
+	responseHeaders             map[string]string
+	subnetSelectors             map[string]*SubnetSelector
+	caBundleS3Bucket            string
+	caBundleS3Prefix            string
 	TargetCNI                   *TargetCNIconfig
+	cniTargetPorts              bool
+	FleetTargetCNI              map[string]*TargetCNIconfig
+	fleets                      map[string]string
+	fleetFilterTags             map[string]map[string][]string
 }
 
 type TargetCNIconfig struct {
@@ -116,7 +137,7 @@ const (
 	// accepted by an SSL endpoint.
 	// See; https://docs.aws.amazon.com/elasticloadbalancing/latest/application/create-https-listener.html#describe-ssl-policies
 	DefaultSslPolicy = "ELBSecurityPolicy-2016-08"
-	// DefaultIpAddressType sets IpAddressType to "ipv4", it is either ipv4 or dualstack
+	// DefaultIpAddressType sets IpAddressType to "ipv4", see IPAddressTypes
 	DefaultIpAddressType = "ipv4"
 	// DefaultAlbS3LogsBucket is a blank string, and must be set if enabled
 	DefaultAlbS3LogsBucket = ""
@@ -126,7 +147,14 @@ const (
 	DefaultCustomFilter = ""
 
 	// DefaultZoneAffinity specifies dns_record.client_routing_policy, see also https://docs.aws.amazon.com/elasticloadbalancing/latest/network/network-load-balancers.html#load-balancer-attributes
-	DefaultZoneAffinity = "any_availability_zone"
+	DefaultZoneAffinity = ZoneAffinityAnyAvailabilityZone
+
+	// ZoneAffinityAvailabilityZone is 100% zonal affinity
+	ZoneAffinityAvailabilityZone = "availability_zone_affinity"
+	// ZoneAffinityPartialAvailabilityZone is 85% zonal affinity
+	ZoneAffinityPartialAvailabilityZone = "partial_availability_zone_affinity"
+	// ZoneAffinityAnyAvailabilityZone is 0% zonal affinity
+	ZoneAffinityAnyAvailabilityZone = "any_availability_zone"
 
 	// DefaultNLBCrossZone specifies the default configuration for cross
 	// zone load balancing: https://docs.aws.amazon.com/elasticloadbalancing/latest/network/network-load-balancers.html#load-balancer-attributes
@@ -134,11 +162,12 @@ const (
 	DefaultNLBCrossZone   = false
 	DefaultNLBHTTPEnabled = false
 
-	nameTag                     = "Name"
-	LoadBalancerTypeApplication = "application"
-	LoadBalancerTypeNetwork     = "network"
-	IPAddressTypeIPV4           = "ipv4"
-	IPAddressTypeDualstack      = "dualstack"
+	nameTag                                 = "Name"
+	LoadBalancerTypeApplication             = "application"
+	LoadBalancerTypeNetwork                 = "network"
+	IPAddressTypeIPV4                       = "ipv4"
+	IPAddressTypeDualstack                  = "dualstack"
+	IPAddressTypeDualstackWithoutPublicIPv4 = "dualstack-without-public-ipv4"
 
 	TargetAccessModeAWSCNI   = "AWSCNI"
 	TargetAccessModeHostPort = "HostPort"
@@ -202,6 +231,12 @@ var (
 		"ELBSecurityPolicy-TLS13-1-0-2021-06",
 		"ELBSecurityPolicy-TLS13-1-3-2021-06",
 	}
+	// ZoneAffinities is a list of valid NLB zone affinity settings
+	ZoneAffinities = []string{
+		ZoneAffinityAvailabilityZone,
+		ZoneAffinityPartialAvailabilityZone,
+		ZoneAffinityAnyAvailabilityZone,
+	}
 )
 
 func newConfigProvider(ctx context.Context, debug, disableInstrumentedHttpClient bool) (*aws.Config, error) {
@@ -248,6 +283,7 @@ func NewAdapter(ctx context.Context, clusterID, newControllerID, vpcID string, d
 		acm:                        acm.NewFromConfig(cfg),
 		iam:                        iam.NewFromConfig(cfg),
 		cloudformation:             cloudformation.NewFromConfig(cfg),
+		s3:                         s3.NewFromConfig(cfg),
 		healthCheckPath:            DefaultHealthCheckPath,
 		healthCheckPort:            DefaultHealthCheckPort,
 		targetPort:                 DefaultTargetPort,
@@ -267,8 +303,11 @@ func NewAdapter(ctx context.Context, clusterID, newControllerID, vpcID string, d
 		albLogsS3Prefix:            DefaultAlbS3LogsPrefix,
 		nlbCrossZone:               DefaultNLBCrossZone,
 		nlbZoneAffinity:            DefaultZoneAffinity,
+		targetProtocolVersion:      DefaultTargetProtocolVersion,
 		nlbHTTPEnabled:             DefaultNLBHTTPEnabled,
 		customFilter:               DefaultCustomFilter,
+		sourceRangesDenyResponse:   defaultSourceRangesDenyResponse,
+		maintenanceResponse:        defaultMaintenanceResponse,
 		TargetCNI: &TargetCNIconfig{
 			Enabled:       false,
 			TargetGroupCh: make(chan []string, 10),
@@ -434,9 +473,19 @@ func (a *Adapter) WithStackTags(tags map[string]string) *Adapter {
 	return a
 }
 
-// WithIpAddressType returns the receiver with ipv4 or dualstack configuration, defaults to ipv4.
+// WithSubnetSelector returns the receiver adapter after setting the subnet
+// selector used for load balancers with the given scheme.
+func (a *Adapter) WithSubnetSelector(scheme string, selector *SubnetSelector) *Adapter {
+	if a.subnetSelectors == nil {
+		a.subnetSelectors = make(map[string]*SubnetSelector)
+	}
+	a.subnetSelectors[scheme] = selector
+	return a
+}
+
+// WithIpAddressType returns the receiver with one of the IPAddressTypes, defaults to ipv4.
 func (a *Adapter) WithIpAddressType(ipAddressType string) *Adapter {
-	if ipAddressType == IPAddressTypeDualstack {
+	if slices.Contains(IPAddressTypes, ipAddressType) {
 		a.ipAddressType = ipAddressType
 	}
 	return a
@@ -454,6 +503,15 @@ func (a *Adapter) WithAlbLogsS3Prefix(prefix string) *Adapter {
 	return a
 }
 
+// WithCABundleS3Location returns the receiver adapter after setting the S3
+// bucket and prefix the CA bundles of mutual TLS trust stores are uploaded
+// to.
+func (a *Adapter) WithCABundleS3Location(bucket, prefix string) *Adapter {
+	a.caBundleS3Bucket = bucket
+	a.caBundleS3Prefix = prefix
+	return a
+}
+
 // WithHTTPRedirectToHTTPS returns the receiver adapter after changing the flag to effect HTTP->HTTPS redirection
 func (a *Adapter) WithHTTPRedirectToHTTPS(httpRedirectToHTTPS bool) *Adapter {
 	a.httpRedirectToHTTPS = httpRedirectToHTTPS
@@ -474,6 +532,23 @@ func (a *Adapter) WithNLBZoneAffinity(nlbZoneAffinity string) *Adapter {
 	return a
 }
 
+// WithTargetProtocolVersion returns the receiver adapter after setting the
+// default protocol version of the target groups of application load
+// balancers, see TargetProtocolVersions.
+func (a *Adapter) WithTargetProtocolVersion(protocolVersion string) *Adapter {
+	a.targetProtocolVersion = protocolVersion
+	return a
+}
+
+// WithHealthCheckMatcher returns the receiver adapter after setting the
+// default success codes of the health checks of application load balancer
+// target groups with the default protocol version, see
+// ParseHealthCheckMatcher.
+func (a *Adapter) WithHealthCheckMatcher(matcher string) *Adapter {
+	a.healthCheckMatcher = matcher
+	return a
+}
+
 // WithNLBHTTPEnabled returns the receiver adapter after setting the
 // nlbHTTPEnabled config.
 func (a *Adapter) WithNLBHTTPEnabled(nlbHTTPEnabled bool) *Adapter {
@@ -490,6 +565,28 @@ func (a *Adapter) WithCustomFilter(customFilter string) *Adapter {
 	return a
 }
 
+// WithFleets returns the receiver adapter after defining the fleets which
+// ingresses can select to serve their hosts, see ParseFleets. In the AWSCNI
+// target access mode the targets of the fleets are set through the channels
+// of FleetTargetCNI, otherwise their Auto Scaling Groups are discovered with
+// the tag filters of the fleets. Auto Scaling Groups of fleets are not
+// targeted by the other target groups.
+func (a *Adapter) WithFleets(fleets map[string]string) *Adapter {
+	a.fleets = fleets
+	a.fleetFilterTags = make(map[string]map[string][]string, len(fleets))
+	a.FleetTargetCNI = make(map[string]*TargetCNIconfig, len(fleets))
+	for name, selector := range fleets {
+		if filterTags, err := ParseFilterTags(selector); err == nil {
+			a.fleetFilterTags[name] = filterTags
+		}
+		a.FleetTargetCNI[name] = &TargetCNIconfig{
+			Enabled:       a.TargetCNI.Enabled,
+			TargetGroupCh: make(chan []string, 10),
+		}
+	}
+	return a
+}
+
 // WithInternalDomains returns the receiver adapter after changing the
 // internal domains that will be used by the resources created by the
 // adapter.
@@ -501,6 +598,9 @@ func (a *Adapter) WithInternalDomains(domains []string) *Adapter {
 // WithTargetAccessMode returns the receiver adapter after defining the target access mode
 func (a *Adapter) WithTargetAccessMode(mode string) *Adapter {
 	a.TargetCNI.Enabled = mode == TargetAccessModeAWSCNI
+	for _, cfg := range a.FleetTargetCNI {
+		cfg.Enabled = a.TargetCNI.Enabled
+	}
 
 	switch mode {
 	case TargetAccessModeHostPort:
@@ -513,6 +613,14 @@ func (a *Adapter) WithTargetAccessMode(mode string) *Adapter {
 	return a
 }
 
+// WithCNITargetPorts returns the receiver adapter after setting whether the
+// CNI targets are registered on the ports of their endpoints instead of the
+// port of the target group.
+func (a *Adapter) WithCNITargetPorts(enabled bool) *Adapter {
+	a.cniTargetPorts = enabled
+	return a
+}
+
 // WithDenyInternalDomains returns the receiver adapter after setting
 // the denyInternalDomains config.
 func (a *Adapter) WithDenyInternalDomains(deny bool) *Adapter {
@@ -547,6 +655,56 @@ func (a *Adapter) WithInternalDomainsDenyResponseContenType(contentType string)
 	return a
 }
 
+// WithSourceRangesDenyResponse returns the receiver adapter after changing
+// the response returned by application load balancers to requests from
+// outside of their source ranges.
+func (a *Adapter) WithSourceRangesDenyResponse(statusCode int, contentType, body string) *Adapter {
+	a.sourceRangesDenyResponse = denyResp{
+		statusCode:  statusCode,
+		contentType: contentType,
+		body:        body,
+	}
+	return a
+}
+
+// WithTargetGroupAttributes returns the receiver adapter after setting the
+// default target group attributes of load balancers of the given type, see
+// ParseTargetGroupAttributes.
+func (a *Adapter) WithTargetGroupAttributes(loadBalancerType string, attributes map[string]string) *Adapter {
+	if a.targetGroupAttributes == nil {
+		a.targetGroupAttributes = make(map[string]map[string]string)
+	}
+	a.targetGroupAttributes[loadBalancerType] = attributes
+	return a
+}
+
+// WithLoadBalancerAttributes returns the receiver adapter after setting the
+// default attributes of application load balancers, see
+// ParseLoadBalancerAttributes.
+func (a *Adapter) WithLoadBalancerAttributes(attributes map[string]string) *Adapter {
+	a.loadBalancerAttributes = attributes
+	return a
+}
+
+// WithResponseHeaders returns the receiver adapter after setting the default
+// response headers added by the HTTPS listeners of application load
+// balancers, see ParseResponseHeaders.
+func (a *Adapter) WithResponseHeaders(headers map[string]string) *Adapter {
+	a.responseHeaders = headers
+	return a
+}
+
+// WithMaintenanceResponse returns the receiver adapter after changing the
+// response returned by application load balancers in maintenance.
+func (a *Adapter) WithMaintenanceResponse(statusCode int, contentType, body string) *Adapter {
+	a.maintenanceResponse = denyResp{
+		statusCode:  statusCode,
+		contentType: contentType,
+		body:        body,
+	}
+	return a
+}
+
 // WithCustomEc2Client returns an Adapter that will use the provided
 // EC2 client, instead of the EC2 client provided by AWS.
 func (a *Adapter) WithCustomEc2Client(c EC2API) *Adapter {
@@ -706,40 +864,66 @@ func (a *Adapter) GetStackLBStates(ctx context.Context, stacks []*Stack) ([]*Sta
 // instances (that do not belong to ASG) in relevant Target Groups.
 func (a *Adapter) UpdateTargetGroupsAndAutoScalingGroups(ctx context.Context, stacks []*Stack, problems *problem.List) {
 	allTargetGroupARNs := make([]string, 0, len(stacks))
+	var allFleetTargetGroupARNs []string
+	fleetTargetGroupARNs := make(map[string][]string)
 	for _, stack := range stacks {
 		if len(stack.TargetGroupARNs) > 0 {
 			allTargetGroupARNs = append(allTargetGroupARNs, stack.TargetGroupARNs...)
 		}
+		for fleet, arn := range stack.FleetTargetGroupARNs {
+			fleetTargetGroupARNs[fleet] = append(fleetTargetGroupARNs[fleet], arn)
+			allFleetTargetGroupARNs = append(allFleetTargetGroupARNs, arn)
+		}
 	}
 	// split the full list into TG types
-	targetTypesARNs, err := categorizeTargetTypeInstance(ctx, a.elbv2, allTargetGroupARNs)
+	targetTypesARNs, err := categorizeTargetTypeInstance(ctx, a.elbv2, append(slices.Clone(allTargetGroupARNs), allFleetTargetGroupARNs...))
 	if err != nil {
 		problems.Add("failed to categorize Target Type Instance: %w", err)
 		return
 	}
+	ipTargetGroupARNs := targetTypesARNs[elbv2Types.TargetTypeEnumIp]
 
-	// update the CNI TG list
+	// update the CNI TG lists
 	if a.TargetCNI.Enabled {
-		a.TargetCNI.TargetGroupCh <- targetTypesARNs[elbv2Types.TargetTypeEnumIp]
+		a.TargetCNI.TargetGroupCh <- intersection(ipTargetGroupARNs, allTargetGroupARNs)
+		for fleet, cfg := range a.FleetTargetCNI {
+			cfg.TargetGroupCh <- intersection(ipTargetGroupARNs, fleetTargetGroupARNs[fleet])
+		}
 	}
 
 	// remove the IP TGs from the list keeping all other TGs including problematic #127 and nonexistent #436
-	targetGroupARNs := difference(allTargetGroupARNs, targetTypesARNs[elbv2Types.TargetTypeEnumIp])
+	targetGroupARNs := difference(allTargetGroupARNs, ipTargetGroupARNs)
 
 	ownerTags := map[string]string{
 		clusterIDTagPrefix + a.ClusterID(): resourceLifecycleOwned,
 		kubernetesCreatorTag:               a.controllerID,
 	}
 
-	for _, asg := range a.TargetedAutoScalingGroups {
+	// the ASGs of fleets only get the target groups of their fleets
+	asgTargetGroupARNs := make(map[string][]string)
+	for name := range a.TargetedAutoScalingGroups {
+		asgTargetGroupARNs[name] = targetGroupARNs
+	}
+	for fleet, asgs := range a.FleetAutoScalingGroups {
+		for name := range asgs {
+			asgTargetGroupARNs[name] = append(asgTargetGroupARNs[name], difference(fleetTargetGroupARNs[fleet], ipTargetGroupARNs)...)
+		}
+	}
+
+	for name, arns := range asgTargetGroupARNs {
 		// This call is idempotent and safe to execute every time
-		if err := updateTargetGroupsForAutoScalingGroup(ctx, a.autoscaling, a.elbv2, targetGroupARNs, asg.name, ownerTags); err != nil {
-			problems.Add("failed to update target groups for autoscaling group %q: %w", asg.name, err)
+		if err := updateTargetGroupsForAutoScalingGroup(ctx, a.autoscaling, a.elbv2, arns, name, ownerTags); err != nil {
+			problems.Add("failed to update target groups for autoscaling group %q: %w", name, err)
 		}
 	}
 
 	// remove owned TGs from non-targeted ASGs
-	nonTargetedASGs := nonTargetedASGs(a.OwnedAutoScalingGroups, a.TargetedAutoScalingGroups)
+	targetedASGs := make(map[string]*autoScalingGroupDetails)
+	maps.Copy(targetedASGs, a.TargetedAutoScalingGroups)
+	for _, asgs := range a.FleetAutoScalingGroups {
+		maps.Copy(targetedASGs, asgs)
+	}
+	nonTargetedASGs := nonTargetedASGs(a.OwnedAutoScalingGroups, targetedASGs)
 	for _, asg := range nonTargetedASGs {
 		// This call is idempotent and safe to execute every time
 		if err := updateTargetGroupsForAutoScalingGroup(ctx, a.autoscaling, a.elbv2, nil, asg.name, ownerTags); err != nil {
@@ -764,18 +948,121 @@ func (a *Adapter) UpdateTargetGroupsAndAutoScalingGroups(ctx context.Context, st
 	}
 }
 
+// StackSettings contains the load balancer specific settings used to create
+// or update a CloudFormation stack.
+type StackSettings struct {
+	Scheme           string
+	SecurityGroup    string
+	Owner            string
+	SSLPolicy        string
+	IPAddressType    string
+	WAFWebACLID      string
+	CWAlarms         CloudWatchAlarmList
+	LoadBalancerType string
+	HTTP2            bool
+	// AccessLogsS3Bucket and AccessLogsS3Prefix override the
+	// globally configured access log destination when not empty.
+	AccessLogsS3Bucket string
+	AccessLogsS3Prefix string
+	// AccessLogsDisabled disables access logs regardless of the
+	// configured destination.
+	AccessLogsDisabled bool
+	// SubnetSelector selects the subnets of the load balancer, see
+	// ParseSubnetSelector. The selector configured for the scheme is
+	// used when empty.
+	SubnetSelector string
+	// EIPAllocations and PrivateIPv4Addresses map Elastic IPs or private
+	// IPv4 addresses to the subnets of a network load balancer, one per
+	// subnet.
+	EIPAllocations       []string
+	PrivateIPv4Addresses []string
+	// NLBCrossZone ("true" or "false") and NLBZoneAffinity override the
+	// global settings of network load balancers when not empty.
+	NLBCrossZone    string
+	NLBZoneAffinity string
+	// TargetProtocolVersion and HealthCheckMatcher override the global
+	// settings of application load balancer target groups when not
+	// empty, see TargetProtocolVersions and ParseHealthCheckMatcher.
+	TargetProtocolVersion string
+	HealthCheckMatcher    string
+	// Listeners defines the listeners of the load balancer, see
+	// ParseListeners. HTTP on port 80 and HTTPS on port 443 are used
+	// when empty.
+	Listeners string
+	// HTTPListenerMode overrides the HTTP to HTTPS redirect setting of
+	// application load balancers or disables their HTTP listener, see
+	// HTTPListenerModes.
+	HTTPListenerMode string
+	// MTLSMode enables mutual TLS authentication on the HTTPS listeners
+	// of application load balancers, see MTLSModes. MTLSCABundle is the
+	// PEM encoded CA bundle of the trust store used in verify mode and
+	// MTLSCABundleRef identifies its source.
+	MTLSMode        string
+	MTLSCABundleRef string
+	MTLSCABundle    string
+	// Auth enables the authentication of users on the HTTPS listeners of
+	// application load balancers.
+	Auth *AuthConfig
+	// StrictHosts only forwards requests for the Hostnames of the
+	// application load balancer to the targets, other requests get a
+	// 404 response.
+	StrictHosts bool
+	Hostnames   []string
+	// SourceRanges restricts the access to the load balancer to the comma
+	// separated CIDRs, see ParseSourceRanges.
+	SourceRanges string
+	// OriginHeader restricts the access to the application load balancer
+	// to requests with the secret header, OriginHeaderRef identifies its
+	// source.
+	OriginHeaderRef string
+	OriginHeader    *OriginHeader
+	// Maintenance makes the application load balancer respond to all
+	// requests with the maintenance response, MaintenanceHosts only to
+	// the requests for the hosts.
+	Maintenance      bool
+	MaintenanceHosts []string
+	// CapacityUnits is the minimum capacity reserved for the load
+	// balancer, see ReservedCapacityUnits. 0 releases the reservation.
+	CapacityUnits int
+	// NLBSecurityGroup attaches the SecurityGroup to the network load
+	// balancer. It can't be changed after the load balancer is created.
+	NLBSecurityGroup bool
+	// TargetGroupAttributes override the default target group attributes,
+	// see ParseTargetGroupAttributes.
+	TargetGroupAttributes string
+	// LoadBalancerAttributes override the default load balancer
+	// attributes, see ParseLoadBalancerAttributes.
+	LoadBalancerAttributes string
+	// ResponseHeaders override the default response headers added by the
+	// HTTPS listeners, see ParseResponseHeaders.
+	ResponseHeaders string
+	// Fleets are the hostnames of the application load balancer by the
+	// name of the fleet serving them. Every fleet gets its own target
+	// group and the listeners forward requests for its hosts there.
+	Fleets map[string][]string
+	// FleetWeights are the percentages of the requests not matching any
+	// host rule which the listeners of the application load balancer
+	// forward to fleets, see ParseFleetWeights.
+	FleetWeights string
+	// Tags are custom tags added to the stack and propagated to the
+	// load balancer and target groups. Tags configured for the
+	// controller take precedence.
+	Tags map[string]string
+}
+
 // CreateStack creates a new Application Load Balancer using CloudFormation.
 // The stack name is derived from the Cluster ID and a has of the certificate
 // ARNs (when available).
 // All the required resources (listeners and target group) are created in a
 // transactional fashion.
 // Failure to create the stack causes it to be deleted automatically.
-func (a *Adapter) CreateStack(ctx context.Context, certificateARNs []string, scheme, securityGroup, owner, sslPolicy, ipAddressType, wafWebACLID string, cwAlarms CloudWatchAlarmList, loadBalancerType string, http2 bool) (string, error) {
+func (a *Adapter) CreateStack(ctx context.Context, certificateARNs []string, settings *StackSettings) (string, error) {
 	certARNs := make(map[string]time.Time, len(certificateARNs))
 	for _, arn := range certificateARNs {
 		certARNs[arn] = time.Time{}
 	}
 
+	sslPolicy := settings.SSLPolicy
 	if sslPolicy == "" {
 		sslPolicy = a.sslPolicy
 	}
@@ -784,70 +1071,70 @@ func (a *Adapter) CreateStack(ctx context.Context, certificateARNs []string, sch
 		return "", fmt.Errorf("invalid SSLPolicy '%s' defined", sslPolicy)
 	}
 
-	spec := &stackSpec{
-		name:            a.stackName(),
-		scheme:          scheme,
-		ownerIngress:    owner,
-		certificateARNs: certARNs,
-		securityGroupID: securityGroup,
-		subnets:         a.FindLBSubnets(scheme),
-		vpcID:           a.VpcID(),
-		clusterID:       a.ClusterID(),
-		healthCheck: &healthCheck{
-			path:     a.healthCheckPath,
-			port:     a.healthCheckPort,
-			interval: a.healthCheckInterval,
-			timeout:  a.healthCheckTimeout,
-		},
-		albHealthyThresholdCount:          a.albHealthyThresholdCount,
-		albUnhealthyThresholdCount:        a.albUnhealthyThresholdCount,
-		nlbHealthyThresholdCount:          a.nlbHealthyThresholdCount,
-		targetType:                        a.targetType,
-		targetPort:                        a.targetPort,
-		targetHTTPS:                       a.targetHTTPS,
-		httpDisabled:                      a.httpDisabled(loadBalancerType),
-		httpTargetPort:                    a.httpTargetPort(loadBalancerType),
-		timeoutInMinutes:                  int32(a.creationTimeout.Minutes()),
-		stackTerminationProtection:        a.stackTerminationProtection,
-		idleConnectionTimeoutSeconds:      uint(a.idleConnectionTimeout.Seconds()),
-		deregistrationDelayTimeoutSeconds: uint(a.deregistrationDelayTimeout.Seconds()),
-		controllerID:                      a.controllerID,
-		sslPolicy:                         sslPolicy,
-		ipAddressType:                     ipAddressType,
-		loadbalancerType:                  loadBalancerType,
-		albLogsS3Bucket:                   a.albLogsS3Bucket,
-		albLogsS3Prefix:                   a.albLogsS3Prefix,
-		wafWebAclId:                       wafWebACLID,
-		cwAlarms:                          cwAlarms,
-		httpRedirectToHTTPS:               a.httpRedirectToHTTPS,
-		nlbCrossZone:                      a.nlbCrossZone,
-		nlbZoneAffinity:                   a.nlbZoneAffinity,
-		http2:                             http2,
-		tags:                              a.stackTags,
-		internalDomains:                   a.internalDomains,
-		denyInternalDomains:               a.denyInternalDomains,
-		denyInternalDomainsResponse: denyResp{
-			body:        a.denyInternalRespBody,
-			statusCode:  a.denyInternalRespStatusCode,
-			contentType: a.denyInternalRespContentType,
-		},
+	spec, err := a.newStackSpec(a.stackName(), certARNs, settings)
+	if err != nil {
+		return "", err
+	}
+	spec.sslPolicy = sslPolicy
+
+	if err := a.uploadCABundle(ctx, spec); err != nil {
+		return "", err
 	}
 
 	return createStack(ctx, a.cloudformation, spec)
 }
 
-func (a *Adapter) UpdateStack(ctx context.Context, stackName string, certificateARNs map[string]time.Time, scheme, securityGroup, owner, sslPolicy, ipAddressType, wafWebACLID string, cwAlarms CloudWatchAlarmList, loadBalancerType string, http2 bool) (string, error) {
-	if _, ok := SSLPolicies[sslPolicy]; !ok {
-		return "", fmt.Errorf("invalid SSLPolicy '%s' defined", sslPolicy)
+func (a *Adapter) UpdateStack(ctx context.Context, stackName string, certificateARNs map[string]time.Time, settings *StackSettings) (string, error) {
+	if _, ok := SSLPolicies[settings.SSLPolicy]; !ok {
+		return "", fmt.Errorf("invalid SSLPolicy '%s' defined", settings.SSLPolicy)
+	}
+
+	spec, err := a.newStackSpec(stackName, certificateARNs, settings)
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.uploadCABundle(ctx, spec); err != nil {
+		return "", err
+	}
+
+	return updateStack(ctx, a.cloudformation, spec)
+}
+
+func (a *Adapter) newStackSpec(stackName string, certificateARNs map[string]time.Time, settings *StackSettings) (*stackSpec, error) {
+	subnets, err := a.SelectLBSubnets(settings.Scheme, settings.SubnetSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select subnets: %w", err)
+	}
+
+	if (len(settings.EIPAllocations) > 0 || len(settings.PrivateIPv4Addresses) > 0) && settings.LoadBalancerType != LoadBalancerTypeNetwork {
+		return nil, fmt.Errorf("subnet mappings are only supported by %s load balancers", LoadBalancerTypeNetwork)
+	}
+
+	subnetMappings, err := mapSubnets(a.manifest.subnets, subnets, settings.EIPAllocations, settings.PrivateIPv4Addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map subnets: %w", err)
+	}
+
+	// load balancers without public IPv4 addresses are only reachable by
+	// IPv6 from the internet
+	if settings.IPAddressType == IPAddressTypeDualstackWithoutPublicIPv4 {
+		if err := ValidateIPAddressType(settings.IPAddressType, settings.LoadBalancerType, settings.Scheme); err != nil {
+			return nil, err
+		}
+		if err := checkIPv6Subnets(a.manifest.subnets, subnets); err != nil {
+			return nil, fmt.Errorf("%s load balancers require IPv6 subnets: %w", settings.IPAddressType, err)
+		}
 	}
 
 	spec := &stackSpec{
 		name:            stackName,
-		scheme:          scheme,
-		ownerIngress:    owner,
+		scheme:          settings.Scheme,
+		ownerIngress:    settings.Owner,
 		certificateARNs: certificateARNs,
-		securityGroupID: securityGroup,
-		subnets:         a.FindLBSubnets(scheme),
+		securityGroupID: settings.SecurityGroup,
+		subnets:         subnets,
+		subnetMappings:  subnetMappings,
 		vpcID:           a.VpcID(),
 		clusterID:       a.ClusterID(),
 		healthCheck: &healthCheck{
@@ -862,25 +1149,29 @@ func (a *Adapter) UpdateStack(ctx context.Context, stackName string, certificate
 		targetType:                        a.targetType,
 		targetPort:                        a.targetPort,
 		targetHTTPS:                       a.targetHTTPS,
-		httpDisabled:                      a.httpDisabled(loadBalancerType),
-		httpTargetPort:                    a.httpTargetPort(loadBalancerType),
+		httpDisabled:                      a.httpDisabled(settings.LoadBalancerType),
+		httpTargetPort:                    a.httpTargetPort(settings.LoadBalancerType),
 		timeoutInMinutes:                  int32(a.creationTimeout.Minutes()),
 		stackTerminationProtection:        a.stackTerminationProtection,
 		idleConnectionTimeoutSeconds:      uint(a.idleConnectionTimeout.Seconds()),
 		deregistrationDelayTimeoutSeconds: uint(a.deregistrationDelayTimeout.Seconds()),
 		controllerID:                      a.controllerID,
-		sslPolicy:                         sslPolicy,
-		ipAddressType:                     ipAddressType,
-		loadbalancerType:                  loadBalancerType,
+		sslPolicy:                         settings.SSLPolicy,
+		ipAddressType:                     settings.IPAddressType,
+		loadbalancerType:                  settings.LoadBalancerType,
 		albLogsS3Bucket:                   a.albLogsS3Bucket,
 		albLogsS3Prefix:                   a.albLogsS3Prefix,
-		wafWebAclId:                       wafWebACLID,
-		cwAlarms:                          cwAlarms,
+		wafWebAclId:                       settings.WAFWebACLID,
+		cwAlarms:                          settings.CWAlarms,
 		httpRedirectToHTTPS:               a.httpRedirectToHTTPS,
 		nlbCrossZone:                      a.nlbCrossZone,
 		nlbZoneAffinity:                   a.nlbZoneAffinity,
-		http2:                             http2,
+		targetProtocolVersion:             a.targetProtocolVersion,
+		healthCheckMatcher:                a.healthCheckMatcher,
+		http2:                             settings.HTTP2,
 		tags:                              a.stackTags,
+		resourceTags:                      settings.Tags,
+		settingsHash:                      SettingsHash(settings),
 		internalDomains:                   a.internalDomains,
 		denyInternalDomains:               a.denyInternalDomains,
 		denyInternalDomainsResponse: denyResp{
@@ -890,7 +1181,326 @@ func (a *Adapter) UpdateStack(ctx context.Context, stackName string, certificate
 		},
 	}
 
-	return updateStack(ctx, a.cloudformation, spec)
+	for _, apply := range []func(*stackSpec, *StackSettings) error{
+		a.applyAccessLogs,
+	} {
+		if err := apply(spec, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.NLBCrossZone != "" {
+		nlbCrossZone, err := strconv.ParseBool(settings.NLBCrossZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NLB cross zone setting %q: %w", settings.NLBCrossZone, err)
+		}
+		spec.nlbCrossZone = nlbCrossZone
+	}
+
+	targetGroupAttributes := maps.Clone(a.targetGroupAttributes[settings.LoadBalancerType])
+	if settings.TargetGroupAttributes != "" {
+		attributes, err := ParseTargetGroupAttributes(settings.TargetGroupAttributes, settings.LoadBalancerType)
+		if err != nil {
+			return nil, err
+		}
+		if targetGroupAttributes == nil {
+			targetGroupAttributes = make(map[string]string)
+		}
+		maps.Copy(targetGroupAttributes, attributes)
+	}
+	if err := validateTargetGroupAttributes(targetGroupAttributes); err != nil {
+		return nil, err
+	}
+	spec.targetGroupAttributes = targetGroupAttributes
+
+	var loadBalancerAttributes map[string]string
+	if settings.LoadBalancerType == LoadBalancerTypeApplication {
+		loadBalancerAttributes = maps.Clone(a.loadBalancerAttributes)
+	}
+	if settings.LoadBalancerAttributes != "" {
+		attributes, err := ParseLoadBalancerAttributes(settings.LoadBalancerAttributes, settings.LoadBalancerType)
+		if err != nil {
+			return nil, err
+		}
+		if loadBalancerAttributes == nil {
+			loadBalancerAttributes = make(map[string]string)
+		}
+		maps.Copy(loadBalancerAttributes, attributes)
+	}
+	spec.loadBalancerAttributes = loadBalancerAttributes
+
+	var responseHeaders map[string]string
+	if settings.LoadBalancerType == LoadBalancerTypeApplication {
+		responseHeaders = maps.Clone(a.responseHeaders)
+	}
+	if settings.ResponseHeaders != "" {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, errors.New("response headers are only supported by application load balancers")
+		}
+		headers, err := ParseResponseHeaders(settings.ResponseHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if responseHeaders == nil {
+			responseHeaders = make(map[string]string)
+		}
+		maps.Copy(responseHeaders, headers)
+	}
+	spec.responseHeaders = responseHeaders
+
+	if settings.NLBZoneAffinity != "" {
+		if !slices.Contains(ZoneAffinities, settings.NLBZoneAffinity) {
+			return nil, fmt.Errorf("invalid NLB zone affinity %q", settings.NLBZoneAffinity)
+		}
+		spec.nlbZoneAffinity = settings.NLBZoneAffinity
+	}
+
+	if settings.Listeners != "" {
+		listeners, err := ParseListeners(settings.Listeners, settings.LoadBalancerType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listeners: %w", err)
+		}
+		// the targets of IP target groups are the pods of the ingress
+		// controller, see SetTargetsOnCNITargetGroups
+		if len(dedicatedListeners(listeners)) > 0 && a.targetType == elbv2Types.TargetTypeEnumIp {
+			return nil, errors.New("listener target ports are not supported with the IP target type")
+		}
+		spec.listeners = listeners
+	}
+
+	if settings.HTTPListenerMode != "" {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("HTTP listener mode is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+
+		switch settings.HTTPListenerMode {
+		case HTTPListenerModeRedirect:
+			spec.httpRedirectToHTTPS = true
+		case HTTPListenerModeForward:
+			spec.httpRedirectToHTTPS = false
+		case HTTPListenerModeDisabled:
+			if hasInsecureListener(spec.listeners) {
+				return nil, errors.New("HTTP listener can't be disabled when HTTP listeners are configured")
+			}
+			spec.httpDisabled = true
+		default:
+			return nil, fmt.Errorf("invalid HTTP listener mode %q", settings.HTTPListenerMode)
+		}
+	}
+
+	if settings.TargetProtocolVersion != "" {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("target protocol version is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		if err := ValidateTargetProtocolVersion(settings.TargetProtocolVersion); err != nil {
+			return nil, err
+		}
+		// the default matcher only applies to the default protocol version
+		if settings.TargetProtocolVersion != a.targetProtocolVersion {
+			spec.healthCheckMatcher = ""
+		}
+		spec.targetProtocolVersion = settings.TargetProtocolVersion
+	}
+
+	if settings.HealthCheckMatcher != "" {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("health check matcher is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		spec.healthCheckMatcher = settings.HealthCheckMatcher
+	}
+
+	if settings.LoadBalancerType == LoadBalancerTypeApplication {
+		if spec.healthCheckMatcher != "" {
+			if err := ValidateHealthCheckMatcher(spec.healthCheckMatcher, spec.targetProtocolVersion); err != nil {
+				return nil, err
+			}
+		}
+		// HTTP listeners can't forward to HTTP/2 and gRPC targets
+		if spec.targetProtocolVersion != "" && spec.targetProtocolVersion != TargetProtocolVersionHTTP1 &&
+			hasInsecureListener(spec.listenersOrDefault()) && !spec.httpRedirectToHTTPS && spec.httpTargetPort == spec.targetPort {
+			return nil, fmt.Errorf("target protocol version %s requires HTTP listeners to redirect to HTTPS or a separate HTTP target port", spec.targetProtocolVersion)
+		}
+	}
+
+	if settings.MTLSMode != "" && settings.MTLSMode != MTLSModeOff {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("mutual TLS is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+
+		switch settings.MTLSMode {
+		case MTLSModePassthrough:
+		case MTLSModeVerify:
+			if a.caBundleS3Bucket == "" {
+				return nil, errors.New("mutual TLS verification requires an S3 bucket for CA bundles")
+			}
+			if settings.MTLSCABundle == "" {
+				return nil, fmt.Errorf("no CA bundle found for %q", settings.MTLSCABundleRef)
+			}
+			if err := validateCABundle(settings.MTLSCABundle); err != nil {
+				return nil, fmt.Errorf("invalid CA bundle %q: %w", settings.MTLSCABundleRef, err)
+			}
+			spec.mtlsCABundle = settings.MTLSCABundle
+			spec.mtlsCABundleHash = CABundleHash(settings.MTLSCABundle)
+			spec.mtlsCABundleS3Bucket = a.caBundleS3Bucket
+			spec.mtlsCABundleS3Key = caBundleS3Key(a.caBundleS3Prefix, a.ClusterID(), spec.mtlsCABundleHash)
+		default:
+			return nil, fmt.Errorf("invalid mutual TLS mode %q", settings.MTLSMode)
+		}
+		spec.mtlsMode = settings.MTLSMode
+	}
+
+	if settings.Auth != nil {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("authentication is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		if err := settings.Auth.validate(); err != nil {
+			return nil, fmt.Errorf("invalid authentication config: %w", err)
+		}
+		// authentication is only possible on HTTPS listeners, so HTTP
+		// listeners must not forward requests to the targets.
+		if hasInsecureListener(spec.listenersOrDefault()) && !spec.httpRedirectToHTTPS {
+			return nil, errors.New("authentication requires the HTTP listener to redirect to HTTPS or to be disabled")
+		}
+		spec.auth = settings.Auth
+	}
+
+	if settings.StrictHosts {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("strict hosts mode is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		spec.strictHosts = true
+		spec.hostnames = normalizeHostnames(settings.Hostnames)
+	}
+
+	if settings.SourceRanges != "" {
+		sourceRanges, err := ParseSourceRanges(settings.SourceRanges)
+		if err != nil {
+			return nil, err
+		}
+		spec.sourceRanges = strings.Split(sourceRanges, ",")
+		spec.sourceRangesDenyResponse = a.sourceRangesDenyResponse
+
+		if settings.LoadBalancerType == LoadBalancerTypeNetwork {
+			if rules := len(spec.listenersOrDefault()) * len(spec.sourceRanges); rules > maxSecurityGroupRules {
+				return nil, fmt.Errorf("source ranges require %d security group rules, the limit is %d", rules, maxSecurityGroupRules)
+			}
+		}
+	}
+
+	if settings.OriginHeaderRef != "" {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("origin headers are only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		if settings.OriginHeader == nil {
+			return nil, fmt.Errorf("no origin header found for %q", settings.OriginHeaderRef)
+		}
+		if err := settings.OriginHeader.validate(); err != nil {
+			return nil, fmt.Errorf("invalid origin header %q: %w", settings.OriginHeaderRef, err)
+		}
+		spec.originHeader = settings.OriginHeader
+	}
+
+	if settings.Maintenance || len(settings.MaintenanceHosts) > 0 {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("maintenance mode is only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		spec.maintenanceResponse = a.maintenanceResponse
+
+		if settings.Maintenance {
+			spec.maintenance = true
+		} else {
+			spec.maintenanceHosts = normalizeHostnames(settings.MaintenanceHosts)
+			if rules := chunks(len(spec.maintenanceHosts), maxRuleConditionValues); rules > maxMaintenanceRules {
+				return nil, fmt.Errorf("%d hosts in maintenance require %d listener rules, the limit is %d", len(spec.maintenanceHosts), rules, maxMaintenanceRules)
+			}
+		}
+	}
+
+	if settings.CapacityUnits < 0 {
+		return nil, fmt.Errorf("invalid capacity units %d", settings.CapacityUnits)
+	}
+	spec.capacityUnits = settings.CapacityUnits
+
+	if settings.NLBSecurityGroup {
+		if settings.LoadBalancerType != LoadBalancerTypeNetwork {
+			return nil, fmt.Errorf("the NLB security group is only supported by %s load balancers", LoadBalancerTypeNetwork)
+		}
+		if len(spec.sourceRanges) > 0 {
+			return nil, errors.New("the NLB security group can't be combined with source ranges")
+		}
+		spec.nlbSecurityGroup = true
+	}
+
+	if len(settings.Fleets) > 0 {
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("fleets are only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		if spec.restrictsForwarding() {
+			return nil, errors.New("fleets can't be combined with strict hosts, source ranges or origin headers")
+		}
+		spec.fleets = make(map[string][]string, len(settings.Fleets))
+		for name, hostnames := range settings.Fleets {
+			if _, ok := a.fleets[name]; !ok {
+				return nil, fmt.Errorf("unknown fleet %q", name)
+			}
+			spec.fleets[name] = normalizeHostnames(hostnames)
+		}
+	}
+
+	if settings.FleetWeights != "" {
+		weights, err := ParseFleetWeights(settings.FleetWeights)
+		if err != nil {
+			return nil, err
+		}
+		if settings.LoadBalancerType != LoadBalancerTypeApplication {
+			return nil, fmt.Errorf("fleet weights are only supported by %s load balancers", LoadBalancerTypeApplication)
+		}
+		if spec.restrictsForwarding() {
+			return nil, errors.New("fleet weights can't be combined with strict hosts, source ranges or origin headers")
+		}
+		for name := range weights {
+			if _, ok := a.fleets[name]; !ok {
+				return nil, fmt.Errorf("unknown fleet %q", name)
+			}
+		}
+		spec.fleetWeights = weights
+	}
+
+	if rules := spec.listenerRules(); rules > MaxListenerRules {
+		return nil, fmt.Errorf("%d listener rules required for %d hostnames and %d source ranges, the limit is %d", rules, len(spec.hostnames), len(spec.sourceRanges), MaxListenerRules)
+	}
+
+	// the stack tags, including the certificate tags, are propagated to
+	// the load balancer and target groups.
+	if tags := len(stackTags(spec)); tags > MaxTagsPerResource {
+		return nil, fmt.Errorf("%d tags required for %d certificates and %d resource tags, the limit is %d", tags, len(spec.certificateARNs), len(spec.resourceTags), MaxTagsPerResource)
+	}
+
+	return spec, nil
+}
+
+// applyAccessLogs applies the per load balancer access log settings, which
+// take precedence over the global ones.
+func (a *Adapter) applyAccessLogs(spec *stackSpec, settings *StackSettings) error {
+	if settings.AccessLogsS3Bucket != "" {
+		spec.albLogsS3Bucket = settings.AccessLogsS3Bucket
+	}
+	if settings.AccessLogsS3Prefix != "" {
+		spec.albLogsS3Prefix = settings.AccessLogsS3Prefix
+	}
+	if settings.AccessLogsDisabled {
+		spec.albLogsS3Bucket, spec.albLogsS3Prefix = "", ""
+	}
+	return nil
+}
+
+// uploadCABundle uploads the CA bundle of the trust store of the stack, if
+// any.
+func (a *Adapter) uploadCABundle(ctx context.Context, spec *stackSpec) error {
+	if spec.mtlsCABundle == "" {
+		return nil
+	}
+	return putCABundle(ctx, a.s3, spec.mtlsCABundleS3Bucket, spec.mtlsCABundleS3Key, spec.mtlsCABundle)
 }
 
 func (a *Adapter) httpTargetPort(loadBalancerType string) uint {
@@ -925,6 +1535,13 @@ func (a *Adapter) DeleteStack(ctx context.Context, stack *Stack) error {
 			return fmt.Errorf("failed to detach target groups from autoscaling group %q: %w", asg.name, err)
 		}
 	}
+	for fleet, arn := range stack.FleetTargetGroupARNs {
+		for _, asg := range a.FleetAutoScalingGroups[fleet] {
+			if err := detachTargetGroupsFromAutoScalingGroup(ctx, a.autoscaling, []string{arn}, asg.name); err != nil {
+				return fmt.Errorf("failed to detach target group of fleet %q from autoscaling group %q: %w", fleet, asg.name, err)
+			}
+		}
+	}
 	return deleteStack(ctx, a.cloudformation, stack.Name)
 }
 
@@ -987,18 +1604,40 @@ func buildManifest(ctx context.Context, awsAdapter *Adapter, clusterID, vpcID st
 // when finding subnets for ELBs used for services of type LoadBalancer.
 // https://github.com/kubernetes/kubernetes/blob/65efeee64f772e0f38037e91a677138a335a7570/pkg/cloudprovider/providers/aws/aws.go#L2949-L3027
 func (a *Adapter) FindLBSubnets(scheme string) []string {
-	var internal bool
-	if scheme == string(elbv2Types.LoadBalancerSchemeEnumInternal) {
-		internal = true
+	return findSubnets(a.manifest.subnets, scheme == string(elbv2Types.LoadBalancerSchemeEnumInternal), nil)
+}
+
+// SelectLBSubnets returns the subnets for a load balancer based on the scheme
+// and the subnet selector. The selector configured for the scheme is used
+// when the selector is empty and the subnets are discovered with
+// FindLBSubnets when neither is set.
+func (a *Adapter) SelectLBSubnets(scheme string, subnetSelector string) ([]string, error) {
+	selector, err := ParseSubnetSelector(subnetSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	if selector == nil {
+		selector = a.subnetSelectors[scheme]
 	}
 
+	return selectSubnets(a.manifest.subnets, scheme, selector)
+}
+
+// findSubnets picks one subnet per availability zone out of the subnets
+// accepted by the filter. A nil filter accepts all subnets.
+func findSubnets(subnets []*subnetDetails, internal bool, filter func(*subnetDetails) bool) []string {
 	subnetsByAZ := make(map[string]*subnetDetails)
-	for _, subnet := range a.manifest.subnets {
+	for _, subnet := range subnets {
 		// ignore private subnet for public LB
 		if !internal && !subnet.public {
 			continue
 		}
 
+		if filter != nil && !filter(subnet) {
+			continue
+		}
+
 		existing, ok := subnetsByAZ[subnet.availabilityZone]
 		if !ok {
 			subnetsByAZ[subnet.availabilityZone] = subnet
@@ -1094,6 +1733,19 @@ func (a *Adapter) UpdateAutoScalingGroupsAndInstances(ctx context.Context) error
 		return err
 	}
 
+	if len(a.fleetFilterTags) > 0 && !a.TargetCNI.Enabled {
+		fleetASGs, err := getFleetAutoScalingGroups(ctx, a.autoscaling, a.fleetFilterTags)
+		if err != nil {
+			return err
+		}
+		for _, asgs := range fleetASGs {
+			for name := range asgs {
+				delete(targetedASGs, name)
+			}
+		}
+		a.FleetAutoScalingGroups = fleetASGs
+	}
+
 	a.TargetedAutoScalingGroups = targetedASGs
 	a.OwnedAutoScalingGroups = ownedASGs
 	return nil
@@ -1142,22 +1794,10 @@ func generateDefaultFilters(clusterId string) []ec2Types.Filter {
 // ASGs. As such, we instead build a map of tags to look for as we iterate over all ASGs in getOwnedAutoScalingGroups
 func (a *Adapter) parseAutoscaleFilterTags(clusterId string) map[string][]string {
 	if a.customFilter != "" {
-		terms := strings.Fields(a.customFilter)
-		filterTags := make(map[string][]string)
-		for _, term := range terms {
-			parts := strings.Split(term, "=")
-			if len(parts) != 2 {
-				log.Errorf("Failed parsing %s, falling back to default", a.customFilter)
-				return generateDefaultAutoscaleFilterTags(clusterId)
-			}
-			if parts[0] == "tag-key" {
-				filterTags[parts[1]] = []string{}
-			} else if strings.HasPrefix(parts[0], "tag:") {
-				tagKey := strings.TrimPrefix(parts[0], "tag:")
-				filterTags[tagKey] = strings.Split(parts[1], ",")
-			} else {
-				filterTags[parts[0]] = strings.Split(parts[1], ",")
-			}
+		filterTags, err := ParseFilterTags(a.customFilter)
+		if err != nil {
+			log.Errorf("Failed parsing %s, falling back to default", a.customFilter)
+			return generateDefaultAutoscaleFilterTags(clusterId)
 		}
 		return filterTags
 	}
@@ -1185,6 +1825,9 @@ func nonTargetedASGs(ownedASGs, targetedASGs map[string]*autoScalingGroupDetails
 
 // SetTargetsOnCNITargetGroups implements desired state for CNI target groups
 // by polling the current list of targets thus creating a diff of what needs to be added and removed.
+// Endpoints are IP addresses, registered on the port of the target group, or
+// IP addresses with a port like 10.2.3.4:9999, registered on that port, if
+// the CNI targets are registered on the ports of their endpoints.
 func (a *Adapter) SetTargetsOnCNITargetGroups(ctx context.Context, endpoints, cniTargetGroupARNs []string) error {
 	log.Debugf("setting targets on CNI target groups: '%v'", cniTargetGroupARNs)
 	for _, targetGroupARN := range cniTargetGroupARNs {
@@ -1197,6 +1840,10 @@ func (a *Adapter) SetTargetsOnCNITargetGroups(ctx context.Context, endpoints, cn
 		registeredInstances := make([]string, len(tgh.TargetHealthDescriptions))
 		for i, target := range tgh.TargetHealthDescriptions {
 			registeredInstances[i] = *target.Target.Id
+			// targets registered on another port of the same IP are different targets
+			if a.cniTargetPorts && target.Target.Port != nil {
+				registeredInstances[i] = net.JoinHostPort(*target.Target.Id, strconv.Itoa(int(*target.Target.Port)))
+			}
 		}
 		toRegister := difference(endpoints, registeredInstances)
 		if len(toRegister) > 0 {
@@ -1232,3 +1879,8 @@ func difference(a, b []string) []string {
 	}
 	return diff
 }
+
+// intersection returns the elements in `a` that are also in `b`.
+func intersection(a, b []string) []string {
+	return difference(a, difference(a, b))
+}
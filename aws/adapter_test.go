@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -1017,6 +1018,41 @@ func TestAdapter_SetTargetsOnCNITargetGroups(t *testing.T) {
 		require.Equal(t, []elbv2Types.TargetDescription{{Id: aws.String("3.3.3.3")}}, m.Rtinputs[0].Targets)
 		require.Equal(t, []elbv2Types.TargetDescription{{Id: aws.String("4.4.4.4")}}, m.Dtinputs[0].Targets)
 	})
+
+	t.Run("endpoints with ports, registering and deregistering on the endpoint ports", func(t *testing.T) {
+		a.cniTargetPorts = true
+		thOut = elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: []elbv2Types.TargetHealthDescription{
+			{Target: &elbv2Types.TargetDescription{Id: aws.String("1.1.1.1"), Port: aws.Int32(9999)}},
+			{Target: &elbv2Types.TargetDescription{Id: aws.String("2.2.2.2"), Port: aws.Int32(9999)}},
+			{Target: &elbv2Types.TargetDescription{Id: aws.String("3.3.3.3"), Port: aws.Int32(8080)}},
+		}}
+		m.Rtinputs, m.Dtinputs = nil, nil
+
+		require.NoError(t, a.SetTargetsOnCNITargetGroups(context.Background(), []string{"1.1.1.1:9999", "3.3.3.3:9999"}, tgARNs))
+		require.Equal(t, []elbv2Types.TargetDescription{
+			{Id: aws.String("3.3.3.3"), Port: aws.Int32(9999)},
+		}, m.Rtinputs[0].Targets)
+		require.Equal(t, []elbv2Types.TargetDescription{
+			{Id: aws.String("2.2.2.2"), Port: aws.Int32(9999)},
+			{Id: aws.String("3.3.3.3"), Port: aws.Int32(8080)},
+		}, m.Dtinputs[0].Targets)
+	})
+
+	t.Run("no endpoints with ports, deregistering all on their ports", func(t *testing.T) {
+		a.cniTargetPorts = true
+		thOut = elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: []elbv2Types.TargetHealthDescription{
+			{Target: &elbv2Types.TargetDescription{Id: aws.String("1.1.1.1"), Port: aws.Int32(9999)}},
+			{Target: &elbv2Types.TargetDescription{Id: aws.String("3.3.3.3"), Port: aws.Int32(9999)}},
+		}}
+		m.Rtinputs, m.Dtinputs = nil, nil
+
+		require.NoError(t, a.SetTargetsOnCNITargetGroups(context.Background(), []string{}, tgARNs))
+		require.Equal(t, []*elbv2.RegisterTargetsInput(nil), m.Rtinputs)
+		require.Equal(t, []elbv2Types.TargetDescription{
+			{Id: aws.String("1.1.1.1"), Port: aws.Int32(9999)},
+			{Id: aws.String("3.3.3.3"), Port: aws.Int32(9999)},
+		}, m.Dtinputs[0].Targets)
+	})
 }
 
 func TestWithTargetAccessMode(t *testing.T) {
@@ -1043,6 +1079,375 @@ func TestWithTargetAccessMode(t *testing.T) {
 	})
 }
 
+func TestNewStackSpecAccessLogs(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		settings       *StackSettings
+		expectedBucket string
+		expectedPrefix string
+	}{
+		{
+			name:           "uses global settings",
+			settings:       &StackSettings{},
+			expectedBucket: "global-bucket",
+			expectedPrefix: "global-prefix",
+		},
+		{
+			name: "bucket and prefix can be overridden",
+			settings: &StackSettings{
+				AccessLogsS3Bucket: "bucket",
+				AccessLogsS3Prefix: "prefix",
+			},
+			expectedBucket: "bucket",
+			expectedPrefix: "prefix",
+		},
+		{
+			name: "bucket override keeps global prefix",
+			settings: &StackSettings{
+				AccessLogsS3Bucket: "bucket",
+			},
+			expectedBucket: "bucket",
+			expectedPrefix: "global-prefix",
+		},
+		{
+			name: "access logs can be disabled",
+			settings: &StackSettings{
+				AccessLogsS3Bucket: "bucket",
+				AccessLogsDisabled: true,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{
+				manifest:        &manifest{},
+				albLogsS3Bucket: "global-bucket",
+				albLogsS3Prefix: "global-prefix",
+			}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedBucket, spec.albLogsS3Bucket)
+			assert.Equal(t, test.expectedPrefix, spec.albLogsS3Prefix)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+		})
+	}
+}
+
+func TestNewStackSpecNLBSettings(t *testing.T) {
+	a := &Adapter{
+		manifest:        &manifest{},
+		nlbCrossZone:    false,
+		nlbZoneAffinity: DefaultZoneAffinity,
+	}
+
+	spec, err := a.newStackSpec("stack", nil, &StackSettings{LoadBalancerType: LoadBalancerTypeNetwork})
+	require.NoError(t, err)
+	assert.False(t, spec.nlbCrossZone)
+	assert.Equal(t, DefaultZoneAffinity, spec.nlbZoneAffinity)
+
+	spec, err = a.newStackSpec("stack", nil, &StackSettings{
+		LoadBalancerType: LoadBalancerTypeNetwork,
+		NLBCrossZone:     "true",
+		NLBZoneAffinity:  ZoneAffinityAvailabilityZone,
+	})
+	require.NoError(t, err)
+	assert.True(t, spec.nlbCrossZone)
+	assert.Equal(t, ZoneAffinityAvailabilityZone, spec.nlbZoneAffinity)
+
+	_, err = a.newStackSpec("stack", nil, &StackSettings{NLBZoneAffinity: "foo"})
+	assert.Error(t, err)
+}
+
+func TestNewStackSpecTagLimit(t *testing.T) {
+	a := &Adapter{manifest: &manifest{}}
+
+	tags := make(map[string]string)
+	for i := range 40 {
+		tags[fmt.Sprintf("tag-%d", i)] = "foo"
+	}
+	certificateARNs := make(map[string]time.Time)
+	for i := range 5 {
+		certificateARNs[fmt.Sprintf("arn:aws:acm:eu-central-1:123456789012:certificate/%d", i)] = time.Time{}
+	}
+
+	_, err := a.newStackSpec("stack", certificateARNs, &StackSettings{Tags: tags})
+	require.NoError(t, err)
+
+	for i := 5; i < 10; i++ {
+		certificateARNs[fmt.Sprintf("arn:aws:acm:eu-central-1:123456789012:certificate/%d", i)] = time.Time{}
+	}
+	_, err = a.newStackSpec("stack", certificateARNs, &StackSettings{Tags: tags})
+	assert.Error(t, err)
+}
+
+func TestNewStackSpecTargetProtocolVersion(t *testing.T) {
+	a := &Adapter{
+		manifest:              &manifest{},
+		targetProtocolVersion: DefaultTargetProtocolVersion,
+		healthCheckMatcher:    "200-299",
+		httpRedirectToHTTPS:   true,
+	}
+
+	for _, test := range []struct {
+		name                  string
+		settings              *StackSettings
+		targetProtocolVersion string
+		healthCheckMatcher    string
+		err                   bool
+	}{
+		{
+			name:                  "defaults",
+			settings:              &StackSettings{LoadBalancerType: LoadBalancerTypeApplication},
+			targetProtocolVersion: TargetProtocolVersionHTTP1,
+			healthCheckMatcher:    "200-299",
+		},
+		{
+			name: "gRPC without matcher uses the gRPC default",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetProtocolVersion: TargetProtocolVersionGRPC,
+			},
+			targetProtocolVersion: TargetProtocolVersionGRPC,
+		},
+		{
+			name: "gRPC with matcher",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetProtocolVersion: TargetProtocolVersionGRPC,
+				HealthCheckMatcher:    "0,12",
+			},
+			targetProtocolVersion: TargetProtocolVersionGRPC,
+			healthCheckMatcher:    "0,12",
+		},
+		{
+			name: "gRPC with HTTP matcher",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetProtocolVersion: TargetProtocolVersionGRPC,
+				HealthCheckMatcher:    "200",
+			},
+			err: true,
+		},
+		{
+			name: "HTTP2 with forwarding HTTP listener",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetProtocolVersion: TargetProtocolVersionHTTP2,
+				HTTPListenerMode:      HTTPListenerModeForward,
+			},
+			err: true,
+		},
+		{
+			name: "unknown protocol version",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetProtocolVersion: "HTTP3",
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType:   LoadBalancerTypeNetwork,
+				HealthCheckMatcher: "200",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.targetProtocolVersion, spec.targetProtocolVersion)
+			assert.Equal(t, test.healthCheckMatcher, spec.healthCheckMatcher)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+		})
+	}
+}
+
+func TestNewStackSpecHTTPListenerMode(t *testing.T) {
+	a := &Adapter{
+		manifest:            &manifest{},
+		httpRedirectToHTTPS: true,
+	}
+
+	for _, test := range []struct {
+		name         string
+		settings     *StackSettings
+		httpRedirect bool
+		httpDisabled bool
+		err          bool
+	}{
+		{
+			name:         "default",
+			settings:     &StackSettings{LoadBalancerType: LoadBalancerTypeApplication},
+			httpRedirect: true,
+		},
+		{
+			name:     "forward",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, HTTPListenerMode: HTTPListenerModeForward},
+		},
+		{
+			name:         "disabled",
+			settings:     &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, HTTPListenerMode: HTTPListenerModeDisabled},
+			httpRedirect: true,
+			httpDisabled: true,
+		},
+		{
+			name: "disabled with HTTP listeners",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				Listeners:        "HTTP:8080,HTTPS:8443",
+				HTTPListenerMode: HTTPListenerModeDisabled,
+			},
+			err: true,
+		},
+		{
+			name:     "network load balancer",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeNetwork, HTTPListenerMode: HTTPListenerModeForward},
+			err:      true,
+		},
+		{
+			name:     "invalid mode",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, HTTPListenerMode: "foo"},
+			err:      true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.httpRedirect, spec.httpRedirectToHTTPS)
+			assert.Equal(t, test.httpDisabled, spec.httpDisabled)
+		})
+	}
+}
+
+func TestNewStackSpecFleets(t *testing.T) {
+	a := (&Adapter{manifest: &manifest{}, TargetCNI: &TargetCNIconfig{}}).WithFleets(map[string]string{"canary": "tag:fleet=canary"})
+
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		fleets   map[string][]string
+		err      bool
+	}{
+		{
+			name:     "no fleets",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication},
+		},
+		{
+			name: "fleet",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				Fleets:           map[string][]string{"canary": {"B.org", "a.org"}},
+			},
+			fleets: map[string][]string{"canary": {"a.org", "b.org"}},
+		},
+		{
+			name: "unknown fleet",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				Fleets:           map[string][]string{"edge": {"a.org"}},
+			},
+			err: true,
+		},
+		{
+			name: "strict hosts",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Fleets:           map[string][]string{"canary": {"a.org"}},
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				Fleets:           map[string][]string{"canary": {"a.org"}},
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.fleets, spec.fleets)
+		})
+	}
+}
+
+func TestNewStackSpecFleetWeights(t *testing.T) {
+	a := (&Adapter{manifest: &manifest{}, TargetCNI: &TargetCNIconfig{}}).WithFleets(map[string]string{"canary": "tag:fleet=canary"})
+
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		weights  map[string]int
+		err      bool
+	}{
+		{
+			name:     "no weights",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication},
+		},
+		{
+			name: "weights",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				FleetWeights:     "canary=10",
+			},
+			weights: map[string]int{"canary": 10},
+		},
+		{
+			name: "unknown fleet",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				FleetWeights:     "edge=10",
+			},
+			err: true,
+		},
+		{
+			name: "source ranges",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				SourceRanges:     "10.0.0.0/8",
+				FleetWeights:     "canary=10",
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				FleetWeights:     "canary=10",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.weights, spec.fleetWeights)
+			assert.Nil(t, spec.fleets)
+		})
+	}
+}
+
 func TestGetStackLBStates(t *testing.T) {
 	tests := []struct {
 		name                  string
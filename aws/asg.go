@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
@@ -118,6 +119,29 @@ func testFilterTags(filterTags map[string][]string, asgTags map[string]string) b
 	return len(filterTags) == len(matches)
 }
 
+// ParseFilterTags parses a filter of space separated terms in the format of
+// the custom filter, e.g. "tag:key=value1,value2 tag-key=key", and returns the
+// tag values to look for by tag key. An empty list of values matches any
+// value.
+func ParseFilterTags(filter string) (map[string][]string, error) {
+	filterTags := make(map[string][]string)
+	for _, term := range strings.Fields(filter) {
+		parts := strings.Split(term, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid filter term %q", term)
+		}
+		if parts[0] == "tag-key" {
+			filterTags[parts[1]] = []string{}
+		} else if strings.HasPrefix(parts[0], "tag:") {
+			tagKey := strings.TrimPrefix(parts[0], "tag:")
+			filterTags[tagKey] = strings.Split(parts[1], ",")
+		} else {
+			filterTags[parts[0]] = strings.Split(parts[1], ",")
+		}
+	}
+	return filterTags, nil
+}
+
 func getOwnedAndTargetedAutoScalingGroups(ctx context.Context, service AutoScalingAPI, filterTags map[string][]string, ownedTags map[string]string) (map[string]*autoScalingGroupDetails, map[string]*autoScalingGroupDetails, error) {
 	params := &autoscaling.DescribeAutoScalingGroupsInput{}
 	targetedASGs := make(map[string]*autoScalingGroupDetails)
@@ -158,6 +182,44 @@ func getOwnedAndTargetedAutoScalingGroups(ctx context.Context, service AutoScali
 	return targetedASGs, ownedASGs, nil
 }
 
+// getFleetAutoScalingGroups returns the Auto Scaling Groups matching the
+// filter tags of every fleet by fleet name.
+func getFleetAutoScalingGroups(ctx context.Context, service AutoScalingAPI, fleetFilterTags map[string]map[string][]string) (map[string]map[string]*autoScalingGroupDetails, error) {
+	params := &autoscaling.DescribeAutoScalingGroupsInput{}
+	fleetASGs := make(map[string]map[string]*autoScalingGroupDetails, len(fleetFilterTags))
+	for fleet := range fleetFilterTags {
+		fleetASGs[fleet] = make(map[string]*autoScalingGroupDetails)
+	}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(service, params)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get describe auto scaling groups page: %w", err)
+		}
+
+		for _, g := range page.AutoScalingGroups {
+			name := aws.ToString(g.AutoScalingGroupName)
+			tags := make(map[string]string)
+			for _, td := range g.Tags {
+				tags[aws.ToString(td.Key)] = aws.ToString(td.Value)
+			}
+
+			for fleet, filterTags := range fleetFilterTags {
+				if testFilterTags(filterTags, tags) {
+					fleetASGs[fleet][name] = &autoScalingGroupDetails{
+						name:                    name,
+						arn:                     aws.ToString(g.AutoScalingGroupARN),
+						launchConfigurationName: aws.ToString(g.LaunchConfigurationName),
+						targetGroups:            g.TargetGroupARNs,
+						tags:                    tags,
+					}
+				}
+			}
+		}
+	}
+	return fleetASGs, nil
+}
+
 func updateTargetGroupsForAutoScalingGroup(ctx context.Context, svc AutoScalingAPI, elbv2svc ELBV2API, targetGroupARNs []string, autoScalingGroupName string, ownerTags map[string]string) error {
 	params := &autoscaling.DescribeLoadBalancerTargetGroupsInput{
 		AutoScalingGroupName: aws.String(autoScalingGroupName),
@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// attribute is a load balancer or target group attribute which can be
+// configured.
+type attribute struct {
+	loadBalancerTypes []string
+	validate          func(loadBalancerType, value string) error
+}
+
+func oneOf(values ...string) func(string, string) error {
+	return func(_, value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("must be one of %v", values)
+		}
+		return nil
+	}
+}
+
+func intRange(minValue, maxValue int) func(string, string) error {
+	return func(_, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil || v < minValue || v > maxValue {
+			return fmt.Errorf("must be an integer between %d and %d", minValue, maxValue)
+		}
+		return nil
+	}
+}
+
+func boolean(_, value string) error {
+	return oneOf("true", "false")("", value)
+}
+
+// parseAttributes parses a comma separated list of the supported attributes
+// of the given kind in the form key=value for a load balancer of the given
+// type.
+func parseAttributes(value, loadBalancerType, kind string, supported map[string]attribute) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, term := range strings.Split(value, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		key, v, found := strings.Cut(term, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid %s attribute %q, expected key=value", kind, term)
+		}
+		key, v = strings.TrimSpace(key), strings.TrimSpace(v)
+
+		attribute, ok := supported[key]
+		if !ok {
+			return nil, fmt.Errorf("unsupported %s attribute %q", kind, key)
+		}
+		if !slices.Contains(attribute.loadBalancerTypes, loadBalancerType) {
+			return nil, fmt.Errorf("%s attribute %q is not supported by %s load balancers", kind, key, loadBalancerType)
+		}
+		if err := attribute.validate(loadBalancerType, v); err != nil {
+			return nil, fmt.Errorf("invalid %s attribute %q: %w", kind, term, err)
+		}
+		if _, ok := attributes[key]; ok {
+			return nil, fmt.Errorf("duplicate %s attribute %q", kind, key)
+		}
+		attributes[key] = v
+	}
+
+	return attributes, nil
+}
+
+// formatAttributes returns the canonical representation of the attributes
+// ordered by key.
+func formatAttributes(attributes map[string]string) string {
+	terms := make([]string, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		terms = append(terms, key+"="+attributes[key])
+	}
+	return strings.Join(terms, ",")
+}
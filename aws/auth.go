@@ -0,0 +1,195 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	// AuthTypeOIDC authenticates users with an OpenID Connect identity
+	// provider
+	AuthTypeOIDC = "oidc"
+	// AuthTypeCognito authenticates users with an Amazon Cognito user pool
+	AuthTypeCognito = "cognito"
+
+	// maxAuthSessionTimeout is the maximum authentication session
+	// timeout supported by application load balancers (7 days)
+	maxAuthSessionTimeout = 604800
+)
+
+// AuthTypes are the supported authentication types of application load
+// balancers.
+var AuthTypes = []string{
+	AuthTypeOIDC,
+	AuthTypeCognito,
+}
+
+// AuthConfig configures the authentication of users on the HTTPS listeners
+// of an application load balancer before requests are forwarded to the
+// targets.
+type AuthConfig struct {
+	Type string `json:"type"`
+
+	// OpenID Connect identity provider
+	Issuer                string `json:"issuer,omitempty"`
+	AuthorizationEndpoint string `json:"authorizationEndpoint,omitempty"`
+	TokenEndpoint         string `json:"tokenEndpoint,omitempty"`
+	UserInfoEndpoint      string `json:"userInfoEndpoint,omitempty"`
+	ClientID              string `json:"clientID,omitempty"`
+	ClientSecret          string `json:"clientSecret,omitempty"`
+
+	// Amazon Cognito user pool
+	UserPoolArn      string `json:"userPoolArn,omitempty"`
+	UserPoolClientID string `json:"userPoolClientID,omitempty"`
+	UserPoolDomain   string `json:"userPoolDomain,omitempty"`
+
+	// Scope is the space separated list of requested user claims. The
+	// load balancer uses "openid" when empty.
+	Scope string `json:"scope,omitempty"`
+	// SessionCookieName and SessionTimeout (in seconds) configure the
+	// authentication session. The load balancer defaults are used when
+	// empty.
+	SessionCookieName string `json:"sessionCookieName,omitempty"`
+	SessionTimeout    int64  `json:"sessionTimeout,omitempty"`
+}
+
+// Hash returns the hash identifying the authentication config, including
+// its credentials, or an empty string if there is no config.
+func (c *AuthConfig) Hash() string {
+	if c == nil {
+		return ""
+	}
+	// marshaling a struct of strings and integers can't fail
+	data, _ := json.Marshal(c)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *AuthConfig) validate() error {
+	type field struct{ name, value string }
+	var required []field
+	switch c.Type {
+	case AuthTypeOIDC:
+		required = []field{
+			{"issuer", c.Issuer},
+			{"authorization endpoint", c.AuthorizationEndpoint},
+			{"token endpoint", c.TokenEndpoint},
+			{"user info endpoint", c.UserInfoEndpoint},
+			{"client ID", c.ClientID},
+			{"client secret", c.ClientSecret},
+		}
+	case AuthTypeCognito:
+		required = []field{
+			{"user pool ARN", c.UserPoolArn},
+			{"user pool client ID", c.UserPoolClientID},
+			{"user pool domain", c.UserPoolDomain},
+		}
+	default:
+		return fmt.Errorf("invalid authentication type %q", c.Type)
+	}
+
+	for _, f := range required {
+		if f.value == "" {
+			return fmt.Errorf("%s authentication requires the %s", c.Type, f.name)
+		}
+	}
+
+	if c.SessionTimeout < 0 || c.SessionTimeout > maxAuthSessionTimeout {
+		return errors.New("authentication session timeout must be at most 7 days")
+	}
+	return nil
+}
+
+// authenticateAction returns the listener action authenticating users with
+// the config. The client secret is referenced from a NoEcho parameter to
+// not expose it in the template.
+func (c *AuthConfig) authenticateAction() cloudformation.ElasticLoadBalancingV2ListenerAction {
+	var sessionTimeout *cloudformation.StringExpr
+	if c.SessionTimeout > 0 {
+		sessionTimeout = cloudformation.String(strconv.FormatInt(c.SessionTimeout, 10))
+	}
+
+	if c.Type == AuthTypeCognito {
+		return cloudformation.ElasticLoadBalancingV2ListenerAction{
+			Type:  cloudformation.String("authenticate-cognito"),
+			Order: cloudformation.Integer(1),
+			AuthenticateCognitoConfig: &cloudformation.ElasticLoadBalancingV2ListenerAuthenticateCognitoConfig{
+				UserPoolArn:       cloudformation.String(c.UserPoolArn),
+				UserPoolClientID:  cloudformation.String(c.UserPoolClientID),
+				UserPoolDomain:    cloudformation.String(c.UserPoolDomain),
+				Scope:             optionalString(c.Scope),
+				SessionCookieName: optionalString(c.SessionCookieName),
+				SessionTimeout:    sessionTimeout,
+			},
+		}
+	}
+
+	return cloudformation.ElasticLoadBalancingV2ListenerAction{
+		Type:  cloudformation.String("authenticate-oidc"),
+		Order: cloudformation.Integer(1),
+		AuthenticateOidcConfig: &cloudformation.ElasticLoadBalancingV2ListenerAuthenticateOidcConfig{
+			Issuer:                cloudformation.String(c.Issuer),
+			AuthorizationEndpoint: cloudformation.String(c.AuthorizationEndpoint),
+			TokenEndpoint:         cloudformation.String(c.TokenEndpoint),
+			UserInfoEndpoint:      cloudformation.String(c.UserInfoEndpoint),
+			ClientID:              cloudformation.String(c.ClientID),
+			ClientSecret:          cloudformation.Ref(parameterAuthClientSecretParameter).String(),
+			Scope:                 optionalString(c.Scope),
+			SessionCookieName:     optionalString(c.SessionCookieName),
+			SessionTimeout:        sessionTimeout,
+		},
+	}
+}
+
+// authenticateRuleAction returns the listener rule action authenticating
+// users with the config, see authenticateAction.
+func (c *AuthConfig) authenticateRuleAction() cloudformation.ElasticLoadBalancingV2ListenerRuleAction {
+	var sessionTimeout *cloudformation.IntegerExpr
+	if c.SessionTimeout > 0 {
+		sessionTimeout = cloudformation.Integer(c.SessionTimeout)
+	}
+
+	if c.Type == AuthTypeCognito {
+		return cloudformation.ElasticLoadBalancingV2ListenerRuleAction{
+			Type:  cloudformation.String("authenticate-cognito"),
+			Order: cloudformation.Integer(1),
+			AuthenticateCognitoConfig: &cloudformation.ElasticLoadBalancingV2ListenerRuleAuthenticateCognitoConfig{
+				UserPoolArn:       cloudformation.String(c.UserPoolArn),
+				UserPoolClientID:  cloudformation.String(c.UserPoolClientID),
+				UserPoolDomain:    cloudformation.String(c.UserPoolDomain),
+				Scope:             optionalString(c.Scope),
+				SessionCookieName: optionalString(c.SessionCookieName),
+				SessionTimeout:    sessionTimeout,
+			},
+		}
+	}
+
+	return cloudformation.ElasticLoadBalancingV2ListenerRuleAction{
+		Type:  cloudformation.String("authenticate-oidc"),
+		Order: cloudformation.Integer(1),
+		AuthenticateOidcConfig: &cloudformation.ElasticLoadBalancingV2ListenerRuleAuthenticateOidcConfig{
+			Issuer:                cloudformation.String(c.Issuer),
+			AuthorizationEndpoint: cloudformation.String(c.AuthorizationEndpoint),
+			TokenEndpoint:         cloudformation.String(c.TokenEndpoint),
+			UserInfoEndpoint:      cloudformation.String(c.UserInfoEndpoint),
+			ClientID:              cloudformation.String(c.ClientID),
+			ClientSecret:          cloudformation.Ref(parameterAuthClientSecretParameter).String(),
+			Scope:                 optionalString(c.Scope),
+			SessionCookieName:     optionalString(c.SessionCookieName),
+			SessionTimeout:        sessionTimeout,
+		},
+	}
+}
+
+func optionalString(s string) *cloudformation.StringExpr {
+	if s == "" {
+		return nil
+	}
+	return cloudformation.String(s)
+}
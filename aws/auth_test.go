@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOIDCAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		Type:                  AuthTypeOIDC,
+		Issuer:                "https://idp.example.org",
+		AuthorizationEndpoint: "https://idp.example.org/authorize",
+		TokenEndpoint:         "https://idp.example.org/token",
+		UserInfoEndpoint:      "https://idp.example.org/userinfo",
+		ClientID:              "client",
+		ClientSecret:          "secret",
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	assert.NoError(t, testOIDCAuthConfig().validate())
+	assert.NoError(t, (&AuthConfig{
+		Type:             AuthTypeCognito,
+		UserPoolArn:      "arn:aws:cognito-idp:eu-central-1:123456789012:userpool/eu-central-1_foo",
+		UserPoolClientID: "client",
+		UserPoolDomain:   "foo",
+	}).validate())
+
+	noSecret := testOIDCAuthConfig()
+	noSecret.ClientSecret = ""
+	assert.Error(t, noSecret.validate())
+
+	longSession := testOIDCAuthConfig()
+	longSession.SessionTimeout = maxAuthSessionTimeout + 1
+	assert.Error(t, longSession.validate())
+
+	assert.Error(t, (&AuthConfig{Type: AuthTypeCognito}).validate())
+	assert.Error(t, (&AuthConfig{Type: "foo"}).validate())
+}
+
+func TestAuthConfigHash(t *testing.T) {
+	var nilConfig *AuthConfig
+	assert.Empty(t, nilConfig.Hash())
+	assert.Equal(t, testOIDCAuthConfig().Hash(), testOIDCAuthConfig().Hash())
+
+	rotated := testOIDCAuthConfig()
+	rotated.ClientSecret = "rotated"
+	assert.NotEqual(t, testOIDCAuthConfig().Hash(), rotated.Hash())
+}
+
+func TestNewStackSpecAuth(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		err      bool
+	}{
+		{
+			name: "HTTP redirected",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeRedirect,
+				Auth:             testOIDCAuthConfig(),
+			},
+		},
+		{
+			name: "HTTP disabled",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeDisabled,
+				Auth:             testOIDCAuthConfig(),
+			},
+		},
+		{
+			name: "HTTP forwarded",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeForward,
+				Auth:             testOIDCAuthConfig(),
+			},
+			err: true,
+		},
+		{
+			name: "missing credentials",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeRedirect,
+				Auth:             &AuthConfig{Type: AuthTypeOIDC},
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				Auth:             testOIDCAuthConfig(),
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{manifest: &manifest{}}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parameters := make(map[string]string)
+			for _, p := range stackParameters(spec) {
+				parameters[aws.ToString(p.ParameterKey)] = aws.ToString(p.ParameterValue)
+			}
+			assert.Equal(t, "secret", parameters[parameterAuthClientSecretParameter])
+			assert.Equal(t, test.settings.Auth.Hash(), convertCloudFormationTags(stackTags(spec))[authConfigHashTag])
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// CapacityReservation reserves a minimum capacity of the load balancers
+// serving any of the hosts between From and Until, e.g. to prepare them for a
+// planned traffic spike.
+type CapacityReservation struct {
+	Hosts         []string  `json:"hosts"`
+	CapacityUnits int       `json:"capacityUnits"`
+	From          time.Time `json:"from"`
+	Until         time.Time `json:"until"`
+}
+
+// active returns whether the reservation applies at the given time.
+func (r *CapacityReservation) active(now time.Time) bool {
+	return !now.Before(r.From) && now.Before(r.Until)
+}
+
+// NewCapacityReservationsFromYAML parses and validates a list of capacity
+// reservations.
+func NewCapacityReservationsFromYAML(b []byte) ([]CapacityReservation, error) {
+	var reservations []CapacityReservation
+	if err := yaml.Unmarshal(b, &reservations); err != nil {
+		return nil, err
+	}
+
+	for i := range reservations {
+		r := &reservations[i]
+		switch {
+		case len(r.Hosts) == 0:
+			return nil, fmt.Errorf("capacity reservation %d: no hosts", i)
+		case r.CapacityUnits <= 0:
+			return nil, fmt.Errorf("capacity reservation %d: the capacity units must be positive", i)
+		case r.From.IsZero() || r.Until.IsZero():
+			return nil, fmt.Errorf("capacity reservation %d: from and until are required", i)
+		case !r.From.Before(r.Until):
+			return nil, fmt.Errorf("capacity reservation %d: from must be before until", i)
+		}
+		for j, host := range r.Hosts {
+			r.Hosts[j] = strings.ToLower(host)
+		}
+	}
+	return reservations, nil
+}
+
+// ReservedCapacityUnits returns the capacity units reserved at the given time
+// for a load balancer serving the hostnames, which is the maximum of the
+// active reservations for any of them, or 0 if there are none.
+func ReservedCapacityUnits(reservations []CapacityReservation, hostnames []string, now time.Time) int {
+	units := 0
+	for i := range reservations {
+		r := &reservations[i]
+		if r.CapacityUnits <= units || !r.active(now) {
+			continue
+		}
+		if slices.ContainsFunc(hostnames, func(hostname string) bool {
+			return slices.Contains(r.Hosts, strings.ToLower(hostname))
+		}) {
+			units = r.CapacityUnits
+		}
+	}
+	return units
+}
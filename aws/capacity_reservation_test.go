@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCapacityReservationsFromYAML(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data string
+		want []CapacityReservation
+		err  bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "reservations",
+			data: `
+- hosts: [Shop.example.org, api.example.org]
+  capacityUnits: 1500
+  from: 2026-11-27T00:00:00Z
+  until: 2026-11-30T00:00:00Z
+`,
+			want: []CapacityReservation{
+				{
+					Hosts:         []string{"shop.example.org", "api.example.org"},
+					CapacityUnits: 1500,
+					From:          time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC),
+					Until:         time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "no hosts",
+			data: "- {capacityUnits: 100, from: 2026-11-27T00:00:00Z, until: 2026-11-30T00:00:00Z}",
+			err:  true,
+		},
+		{
+			name: "no capacity units",
+			data: "- {hosts: [a.org], from: 2026-11-27T00:00:00Z, until: 2026-11-30T00:00:00Z}",
+			err:  true,
+		},
+		{
+			name: "no until",
+			data: "- {hosts: [a.org], capacityUnits: 100, from: 2026-11-27T00:00:00Z}",
+			err:  true,
+		},
+		{
+			name: "until before from",
+			data: "- {hosts: [a.org], capacityUnits: 100, from: 2026-11-30T00:00:00Z, until: 2026-11-27T00:00:00Z}",
+			err:  true,
+		},
+		{
+			name: "invalid time",
+			data: "- {hosts: [a.org], capacityUnits: 100, from: tomorrow, until: 2026-11-27T00:00:00Z}",
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewCapacityReservationsFromYAML([]byte(test.data))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestReservedCapacityUnits(t *testing.T) {
+	from := time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	reservations := []CapacityReservation{
+		{Hosts: []string{"a.org"}, CapacityUnits: 100, From: from, Until: until},
+		{Hosts: []string{"a.org", "b.org"}, CapacityUnits: 300, From: from, Until: until},
+		{Hosts: []string{"c.org"}, CapacityUnits: 500, From: until, Until: until.Add(time.Hour)},
+	}
+
+	for _, test := range []struct {
+		name      string
+		hostnames []string
+		now       time.Time
+		want      int
+	}{
+		{
+			name:      "maximum of the active reservations",
+			hostnames: []string{"A.org"},
+			now:       from,
+			want:      300,
+		},
+		{
+			name:      "before the reservation",
+			hostnames: []string{"a.org"},
+			now:       from.Add(-time.Second),
+		},
+		{
+			name:      "expired reservation",
+			hostnames: []string{"a.org"},
+			now:       until,
+		},
+		{
+			name:      "later reservation",
+			hostnames: []string{"a.org", "c.org"},
+			now:       until,
+			want:      500,
+		},
+		{
+			name:      "other hosts",
+			hostnames: []string{"d.org"},
+			now:       from,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, ReservedCapacityUnits(reservations, test.hostnames, test.now))
+		})
+	}
+}
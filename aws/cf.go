@@ -2,14 +2,24 @@ package aws
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -17,8 +27,51 @@ const (
 	certificateARNTagPrefix = "ingress:certificate-arn/"
 	ingressOwnerTag         = "ingress:owner"
 	cwAlarmConfigHashTag    = "cloudwatch:alarm-config-hash"
+	resourceTagsHashTag     = "ingress:resource-tags-hash"
+	settingsHashTag         = "ingress:settings-hash"
+	caBundleHashTag         = "ingress:ca-bundle-hash"
+	authConfigHashTag       = "ingress:auth-config-hash"
+	hostnamesHashTag        = "ingress:hostnames-hash"
+	sourceRangesHashTag     = "ingress:source-ranges-hash"
+	originHeaderHashTag     = "ingress:origin-header-hash"
+	maintenanceTag          = "ingress:maintenance"
+	maintenanceHostsHashTag = "ingress:maintenance-hosts-hash"
+	nlbSecurityGroupTag     = "ingress:nlb-security-group"
+	fleetsHashTag           = "ingress:fleets-hash"
+	fleetWeightsHashTag     = "ingress:fleet-weights-hash"
+
+	// MaxTagKeyLength, MaxTagValueLength and MaxTagsPerResource are the
+	// limits of the tags of load balancers and target groups. The
+	// lengths are counted in Unicode characters.
+	MaxTagKeyLength    = 128
+	MaxTagValueLength  = 256
+	MaxTagsPerResource = 50
 )
 
+// managedTags are the keys of the tags which the controller adds to the
+// stack besides the cluster and certificate tags.
+var managedTags = []string{
+	kubernetesCreatorTag,
+	ingressOwnerTag,
+	cwAlarmConfigHashTag,
+	resourceTagsHashTag,
+	settingsHashTag,
+	caBundleHashTag,
+	authConfigHashTag,
+	hostnamesHashTag,
+	sourceRangesHashTag,
+	originHeaderHashTag,
+	maintenanceTag,
+	maintenanceHostsHashTag,
+	nlbSecurityGroupTag,
+	fleetsHashTag,
+	fleetWeightsHashTag,
+}
+
+// tagPattern matches the characters allowed in the tag keys and values of
+// load balancers and target groups.
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
 // Stack is a simple wrapper around a CloudFormation Stack.
 type Stack struct {
 	Name              string
@@ -34,10 +87,47 @@ type Stack struct {
 	HTTP2             bool
 	OwnerIngress      string
 	CWAlarmConfigHash string
+	ResourceTagsHash  string
 	TargetGroupARNs   []string
 	WAFWebACLID       string
-	CertificateARNs   map[string]time.Time
-	tags              map[string]string
+	// SettingsHash is only set when one of the settings which the
+	// ingresses sharing the load balancer must agree on is set, see
+	// SettingsHash.
+	SettingsHash string
+	// MTLSCABundleHash is only set when mutual TLS is enabled with a
+	// trust store.
+	MTLSCABundleHash string
+	// AuthConfigHash is only set when users are authenticated by the
+	// load balancer, see AuthConfig.Hash.
+	AuthConfigHash string
+	// HostnamesHash is only set in strict hosts mode, see HostnamesHash.
+	HostnamesHash string
+	// SourceRangesHash is only set when the access to the load balancer
+	// is restricted, see SourceRangesHash.
+	SourceRangesHash string
+	// OriginHeaderHash is only set when the load balancer requires an
+	// origin header, see OriginHeader.Hash.
+	OriginHeaderHash string
+	// Maintenance is set when the load balancer responds to all requests
+	// with the maintenance response, MaintenanceHostsHash is only set when
+	// only some of its hosts are in maintenance, see HostnamesHash.
+	Maintenance          bool
+	MaintenanceHostsHash string
+	// CapacityUnits is the minimum capacity reserved for the load balancer.
+	CapacityUnits int
+	// NLBSecurityGroup is set when the SecurityGroup is attached to the
+	// network load balancer.
+	NLBSecurityGroup bool
+	// FleetsHash and FleetTargetGroupARNs, the target groups by fleet
+	// name, are only set when hosts of the load balancer are served by
+	// fleets, see FleetsHash.
+	FleetsHash           string
+	FleetTargetGroupARNs map[string]string
+	// FleetWeightsHash is only set when the listeners forward a share of
+	// the requests to fleets, see FleetWeightsHash.
+	FleetWeightsHash string
+	CertificateARNs  map[string]time.Time
+	tags             map[string]string
 }
 
 // IsComplete returns true if the stack status is a complete state.
@@ -121,6 +211,11 @@ func (o stackOutput) targetGroupARNs() (arns []string) {
 	if arn, ok := o[outputHTTPTargetGroupARN]; ok {
 		arns = append(arns, arn)
 	}
+	for _, key := range slices.Sorted(maps.Keys(o)) {
+		if strings.HasPrefix(key, outputListenerTargetGroupARNPrefix) {
+			arns = append(arns, o[key])
+		}
+	}
 	return
 }
 
@@ -140,6 +235,9 @@ const (
 	outputLoadBalancerDNSName = "LoadBalancerDNSName"
 	outputTargetGroupARN      = "TargetGroupARN"
 	outputHTTPTargetGroupARN  = "HTTPTargetGroupARN"
+	// outputListenerTargetGroupARNPrefix is followed by the port of the
+	// dedicated listener of the target group.
+	outputListenerTargetGroupARNPrefix = "ListenerTargetGroupARN"
 
 	parameterLoadBalancerSchemeParameter             = "LoadBalancerSchemeParameter"
 	parameterLoadBalancerSecurityGroupParameter      = "LoadBalancerSecurityGroupParameter"
@@ -156,6 +254,11 @@ const (
 	parameterLoadBalancerTypeParameter               = "Type"
 	parameterLoadBalancerWAFWebACLIDParameter        = "LoadBalancerWAFWebACLIDParameter"
 	parameterHTTP2Parameter                          = "HTTP2"
+	parameterMTLSModeParameter                       = "MTLSModeParameter"
+	parameterAuthClientSecretParameter               = "AuthClientSecretParameter"
+	parameterOriginHeaderValueParameter              = "OriginHeaderValueParameter"
+	parameterOriginHeaderPreviousValueParameter      = "OriginHeaderPreviousValueParameter"
+	parameterCapacityUnitsParameter                  = "CapacityUnitsParameter"
 )
 
 type stackSpec struct {
@@ -163,6 +266,7 @@ type stackSpec struct {
 	scheme                            string
 	ownerIngress                      string
 	subnets                           []string
+	subnetMappings                    []*subnetMapping
 	certificateARNs                   map[string]time.Time
 	securityGroupID                   string
 	clusterID                         string
@@ -188,6 +292,8 @@ type stackSpec struct {
 	albLogsS3Prefix                   string
 	wafWebAclId                       string
 	nlbZoneAffinity                   string
+	targetProtocolVersion             string
+	healthCheckMatcher                string
 	cwAlarms                          CloudWatchAlarmList
 	httpRedirectToHTTPS               bool
 	nlbCrossZone                      bool
@@ -196,6 +302,30 @@ type stackSpec struct {
 	denyInternalDomainsResponse       denyResp
 	internalDomains                   []string
 	tags                              map[string]string
+	resourceTags                      map[string]string
+	settingsHash                      string
+	listeners                         []Listener
+	mtlsMode                          string
+	mtlsCABundle                      string
+	mtlsCABundleHash                  string
+	mtlsCABundleS3Bucket              string
+	mtlsCABundleS3Key                 string
+	auth                              *AuthConfig
+	strictHosts                       bool
+	hostnames                         []string
+	sourceRanges                      []string
+	sourceRangesDenyResponse          denyResp
+	originHeader                      *OriginHeader
+	maintenance                       bool
+	maintenanceHosts                  []string
+	maintenanceResponse               denyResp
+	loadBalancerAttributes            map[string]string
+	responseHeaders                   map[string]string
+	capacityUnits                     int
+	nlbSecurityGroup                  bool
+	fleets                            map[string][]string
+	fleetWeights                      map[string]int
+	targetGroupAttributes             map[string]string
 }
 
 type healthCheck struct {
@@ -225,53 +355,88 @@ func createStack(ctx context.Context, svc CloudFormationAPI, spec *stackSpec) (s
 		return "", err
 	}
 
-	stackTags := map[string]string{
-		kubernetesCreatorTag:                spec.controllerID,
-		clusterIDTagPrefix + spec.clusterID: resourceLifecycleOwned,
-	}
-
-	tags := mergeTags(spec.tags, stackTags)
-
 	params := &cloudformation.CreateStackInput{
-		StackName: aws.String(spec.name),
-		OnFailure: types.OnFailureDelete,
-		Parameters: []types.Parameter{
-			cfParam(parameterLoadBalancerSchemeParameter, spec.scheme),
-			cfParam(parameterLoadBalancerSecurityGroupParameter, spec.securityGroupID),
-			cfParam(parameterLoadBalancerSubnetsParameter, strings.Join(spec.subnets, ",")),
-			cfParam(parameterTargetGroupVPCIDParameter, spec.vpcID),
-			cfParam(parameterTargetGroupTargetPortParameter, fmt.Sprintf("%d", spec.targetPort)),
-			cfParam(parameterListenerSslPolicyParameter, spec.sslPolicy),
-			cfParam(parameterIpAddressTypeParameter, spec.ipAddressType),
-			cfParam(parameterLoadBalancerTypeParameter, spec.loadbalancerType),
-			cfParam(parameterHTTP2Parameter, fmt.Sprintf("%t", spec.http2)),
-		},
-		Tags:                        tagMapToCloudformationTags(tags),
+		StackName:                   aws.String(spec.name),
+		OnFailure:                   types.OnFailureDelete,
+		Parameters:                  stackParameters(spec),
+		Tags:                        stackTags(spec),
 		TemplateBody:                aws.String(template),
 		TimeoutInMinutes:            aws.Int32(int32(spec.timeoutInMinutes)),
 		EnableTerminationProtection: aws.Bool(spec.stackTerminationProtection),
 	}
 
+	resp, err := svc.CreateStack(ctx, params)
+	if err != nil {
+		return spec.name, err
+	}
+
+	return aws.ToString(resp.StackId), nil
+}
+
+func updateStack(ctx context.Context, svc CloudFormationAPI, spec *stackSpec) (string, error) {
+	template, err := generateTemplate(spec)
+	if err != nil {
+		return "", err
+	}
+
+	params := &cloudformation.UpdateStackInput{
+		StackName:    aws.String(spec.name),
+		Parameters:   stackParameters(spec),
+		Tags:         stackTags(spec),
+		TemplateBody: aws.String(template),
+	}
+
+	if spec.stackTerminationProtection {
+		params := &cloudformation.UpdateTerminationProtectionInput{
+			StackName:                   aws.String(spec.name),
+			EnableTerminationProtection: aws.Bool(spec.stackTerminationProtection),
+		}
+
+		_, err := svc.UpdateTerminationProtection(ctx, params)
+		if err != nil {
+			return spec.name, err
+		}
+	}
+
+	resp, err := svc.UpdateStack(ctx, params)
+	if err != nil {
+		return spec.name, err
+	}
+
+	return aws.ToString(resp.StackId), nil
+}
+
+// stackParameters returns the parameters passed to the CloudFormation
+// template generated for the spec.
+func stackParameters(spec *stackSpec) []types.Parameter {
+	parameters := []types.Parameter{
+		cfParam(parameterLoadBalancerSchemeParameter, spec.scheme),
+		cfParam(parameterLoadBalancerSecurityGroupParameter, spec.securityGroupID),
+		cfParam(parameterLoadBalancerSubnetsParameter, strings.Join(spec.subnets, ",")),
+		cfParam(parameterTargetGroupVPCIDParameter, spec.vpcID),
+		cfParam(parameterTargetGroupTargetPortParameter, fmt.Sprintf("%d", spec.targetPort)),
+		cfParam(parameterListenerSslPolicyParameter, spec.sslPolicy),
+		cfParam(parameterIpAddressTypeParameter, spec.ipAddressType),
+		cfParam(parameterLoadBalancerTypeParameter, spec.loadbalancerType),
+		cfParam(parameterHTTP2Parameter, fmt.Sprintf("%t", spec.http2)),
+	}
+
 	if spec.wafWebAclId != "" {
-		params.Parameters = append(
-			params.Parameters,
+		parameters = append(
+			parameters,
 			cfParam(parameterLoadBalancerWAFWebACLIDParameter, spec.wafWebAclId),
 		)
 	}
 
-	if !spec.httpDisabled && spec.httpTargetPort != spec.targetPort {
-		params.Parameters = append(
-			params.Parameters,
+	if hasInsecureListener(spec.listenersOrDefault()) && spec.httpTargetPort != spec.targetPort {
+		parameters = append(
+			parameters,
 			cfParam(parameterTargetGroupHTTPTargetPortParameter, fmt.Sprintf("%d", spec.httpTargetPort)),
 		)
 	}
 
-	for certARN, ttl := range spec.certificateARNs {
-		params.Tags = append(params.Tags, cfTag(certificateARNTagPrefix+certARN, ttl.Format(time.RFC3339)))
-	}
-
 	if spec.healthCheck != nil {
-		params.Parameters = append(params.Parameters,
+		parameters = append(parameters,
 			cfParam(parameterTargetGroupHealthCheckPathParameter, spec.healthCheck.path),
 			cfParam(parameterTargetGroupHealthCheckPortParameter, fmt.Sprintf("%d", spec.healthCheck.port)),
 			cfParam(parameterTargetGroupHealthCheckIntervalParameter, fmt.Sprintf("%.0f", spec.healthCheck.interval.Seconds())),
@@ -279,105 +444,221 @@ func createStack(ctx context.Context, svc CloudFormationAPI, spec *stackSpec) (s
 		)
 	}
 
-	if spec.ownerIngress != "" {
-		params.Tags = append(params.Tags, cfTag(ingressOwnerTag, spec.ownerIngress))
+	if spec.mtlsMode != "" {
+		parameters = append(parameters, cfParam(parameterMTLSModeParameter, spec.mtlsMode))
 	}
 
-	if len(spec.cwAlarms) > 0 {
-		params.Tags = append(params.Tags, cfTag(cwAlarmConfigHashTag, spec.cwAlarms.Hash()))
+	if spec.auth != nil && spec.auth.Type == AuthTypeOIDC {
+		parameters = append(parameters, cfParam(parameterAuthClientSecretParameter, spec.auth.ClientSecret))
 	}
 
-	resp, err := svc.CreateStack(ctx, params)
-	if err != nil {
-		return spec.name, err
+	if spec.capacityUnits > 0 {
+		parameters = append(parameters, cfParam(parameterCapacityUnitsParameter, strconv.Itoa(spec.capacityUnits)))
 	}
 
-	return aws.ToString(resp.StackId), nil
-}
-
-func updateStack(ctx context.Context, svc CloudFormationAPI, spec *stackSpec) (string, error) {
-	template, err := generateTemplate(spec)
-	if err != nil {
-		return "", err
+	if spec.originHeader != nil {
+		parameters = append(parameters,
+			cfParam(parameterOriginHeaderValueParameter, spec.originHeader.Value),
+		)
+		if spec.originHeader.PreviousValue != "" {
+			parameters = append(parameters, cfParam(parameterOriginHeaderPreviousValueParameter, spec.originHeader.PreviousValue))
+		}
 	}
 
+	return parameters
+}
+
+// stackTags returns the tags of the CloudFormation stack for the spec.
+func stackTags(spec *stackSpec) []types.Tag {
 	stackTags := map[string]string{
 		kubernetesCreatorTag:                spec.controllerID,
 		clusterIDTagPrefix + spec.clusterID: resourceLifecycleOwned,
 	}
 
-	tags := mergeTags(spec.tags, stackTags)
+	resourceTags := make(map[string]string, len(spec.resourceTags))
+	for k, v := range spec.resourceTags {
+		if !isReservedTagKey(k) {
+			resourceTags[k] = v
+		}
+	}
 
-	params := &cloudformation.UpdateStackInput{
-		StackName: aws.String(spec.name),
-		Parameters: []types.Parameter{
-			cfParam(parameterLoadBalancerSchemeParameter, spec.scheme),
-			cfParam(parameterLoadBalancerSecurityGroupParameter, spec.securityGroupID),
-			cfParam(parameterLoadBalancerSubnetsParameter, strings.Join(spec.subnets, ",")),
-			cfParam(parameterTargetGroupVPCIDParameter, spec.vpcID),
-			cfParam(parameterTargetGroupTargetPortParameter, fmt.Sprintf("%d", spec.targetPort)),
-			cfParam(parameterListenerSslPolicyParameter, spec.sslPolicy),
-			cfParam(parameterIpAddressTypeParameter, spec.ipAddressType),
-			cfParam(parameterLoadBalancerTypeParameter, spec.loadbalancerType),
-			cfParam(parameterHTTP2Parameter, fmt.Sprintf("%t", spec.http2)),
-		},
-		Tags:         tagMapToCloudformationTags(tags),
-		TemplateBody: aws.String(template),
+	// tags defined by the controller take precedence over the tags
+	// defined by the ingresses.
+	tags := tagMapToCloudformationTags(mergeTags(resourceTags, spec.tags, stackTags))
+
+	for certARN, ttl := range spec.certificateARNs {
+		tags = append(tags, cfTag(certificateARNTagPrefix+certARN, ttl.Format(time.RFC3339)))
 	}
 
-	if spec.wafWebAclId != "" {
-		params.Parameters = append(
-			params.Parameters,
-			cfParam(parameterLoadBalancerWAFWebACLIDParameter, spec.wafWebAclId),
-		)
+	if spec.ownerIngress != "" {
+		tags = append(tags, cfTag(ingressOwnerTag, spec.ownerIngress))
 	}
 
-	if !spec.httpDisabled && spec.httpTargetPort != spec.targetPort {
-		params.Parameters = append(
-			params.Parameters,
-			cfParam(parameterTargetGroupHTTPTargetPortParameter, fmt.Sprintf("%d", spec.httpTargetPort)),
-		)
+	if len(spec.cwAlarms) > 0 {
+		tags = append(tags, cfTag(cwAlarmConfigHashTag, spec.cwAlarms.Hash()))
 	}
 
-	for certARN, ttl := range spec.certificateARNs {
-		params.Tags = append(params.Tags, cfTag(certificateARNTagPrefix+certARN, ttl.Format(time.RFC3339)))
+	if len(spec.resourceTags) > 0 {
+		tags = append(tags, cfTag(resourceTagsHashTag, ResourceTagsHash(spec.resourceTags)))
 	}
 
-	if spec.healthCheck != nil {
-		params.Parameters = append(params.Parameters,
-			cfParam(parameterTargetGroupHealthCheckPathParameter, spec.healthCheck.path),
-			cfParam(parameterTargetGroupHealthCheckPortParameter, fmt.Sprintf("%d", spec.healthCheck.port)),
-			cfParam(parameterTargetGroupHealthCheckIntervalParameter, fmt.Sprintf("%.0f", spec.healthCheck.interval.Seconds())),
-			cfParam(parameterTargetGroupHealthCheckTimeoutParameter, fmt.Sprintf("%.0f", spec.healthCheck.timeout.Seconds())),
-		)
+	// the settings are recorded in tags rather than parameters, as the
+	// template doesn't use them and tags can be read while the stack is
+	// created.
+	if spec.settingsHash != "" {
+		tags = append(tags, cfTag(settingsHashTag, spec.settingsHash))
 	}
 
-	if spec.ownerIngress != "" {
-		params.Tags = append(params.Tags, cfTag(ingressOwnerTag, spec.ownerIngress))
+	if spec.mtlsCABundleHash != "" {
+		tags = append(tags, cfTag(caBundleHashTag, spec.mtlsCABundleHash))
 	}
 
-	if len(spec.cwAlarms) > 0 {
-		params.Tags = append(params.Tags, cfTag(cwAlarmConfigHashTag, spec.cwAlarms.Hash()))
+	if spec.auth != nil {
+		tags = append(tags, cfTag(authConfigHashTag, spec.auth.Hash()))
 	}
 
-	if spec.stackTerminationProtection {
-		params := &cloudformation.UpdateTerminationProtectionInput{
-			StackName:                   aws.String(spec.name),
-			EnableTerminationProtection: aws.Bool(spec.stackTerminationProtection),
+	if spec.strictHosts {
+		tags = append(tags, cfTag(hostnamesHashTag, HostnamesHash(spec.hostnames)))
+	}
+
+	if len(spec.sourceRanges) > 0 {
+		tags = append(tags, cfTag(sourceRangesHashTag, SourceRangesHash(strings.Join(spec.sourceRanges, ","))))
+	}
+
+	if spec.originHeader != nil {
+		tags = append(tags, cfTag(originHeaderHashTag, spec.originHeader.Hash()))
+	}
+
+	if spec.maintenance {
+		tags = append(tags, cfTag(maintenanceTag, "true"))
+	}
+
+	if len(spec.maintenanceHosts) > 0 {
+		tags = append(tags, cfTag(maintenanceHostsHashTag, HostnamesHash(spec.maintenanceHosts)))
+	}
+
+	if spec.nlbSecurityGroup {
+		tags = append(tags, cfTag(nlbSecurityGroupTag, "true"))
+	}
+
+	if len(spec.fleets) > 0 {
+		tags = append(tags, cfTag(fleetsHashTag, FleetsHash(spec.fleets)))
+	}
+
+	if len(spec.fleetWeights) > 0 {
+		tags = append(tags, cfTag(fleetWeightsHashTag, FleetWeightsHash(FormatFleetWeights(spec.fleetWeights))))
+	}
+
+	return tags
+}
+
+// MaxResourceTags returns the number of custom tags which can be added to a
+// stack with the given number of certificates without exceeding the tag
+// limit of the load balancer. Tags added with the additional stack tags of
+// the controller are not accounted for.
+func MaxResourceTags(certificates int) int {
+	// one tag for the cluster and one per certificate.
+	return MaxTagsPerResource - len(managedTags) - 1 - certificates
+}
+
+// ValidateTag returns an error if the tag can't be added to load balancers
+// and target groups.
+func ValidateTag(key, value string) error {
+	switch {
+	case key == "":
+		return errors.New("empty tag key")
+	case utf8.RuneCountInString(key) > MaxTagKeyLength:
+		return fmt.Errorf("tag key %q is longer than %d characters", key, MaxTagKeyLength)
+	case utf8.RuneCountInString(value) > MaxTagValueLength:
+		return fmt.Errorf("value of tag %q is longer than %d characters", key, MaxTagValueLength)
+	case !tagPattern.MatchString(key):
+		return fmt.Errorf("tag key %q contains invalid characters", key)
+	case !tagPattern.MatchString(value):
+		return fmt.Errorf("value of tag %q contains invalid characters", key)
+	}
+	return nil
+}
+
+// isReservedTagKey returns true if the tag key is reserved by AWS or used by
+// the controller to manage the stack.
+func isReservedTagKey(key string) bool {
+	for _, prefix := range []string{"aws:", "ingress:", "cloudwatch:", clusterIDTagPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return true
 		}
+	}
+	return key == kubernetesCreatorTag || key == clusterIDTag
+}
 
-		_, err := svc.UpdateTerminationProtection(ctx, params)
-		if err != nil {
-			return spec.name, err
+// ResourceTagsHash computes a hash of the custom resource tags of a stack
+// which can be used to detect changes between two versions. The hash string
+// will be empty if tags is empty or there was an error while encoding.
+func ResourceTagsHash(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	// map keys are sorted by json.Marshal which makes the hash stable.
+	buf, err := json.Marshal(tags)
+	if err != nil {
+		log.Errorf("failed to marshal resource tags: %v", err)
+		return ""
+	}
+
+	hash := sha256.New()
+	hash.Write(buf)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SettingsHash returns the hash of the settings which the ingresses sharing a
+// load balancer must agree on, or an empty string if none of them is set.
+func SettingsHash(settings *StackSettings) string {
+	values := make(map[string]string)
+	add := func(key, value string) {
+		if value != "" {
+			values[key] = value
 		}
 	}
 
-	resp, err := svc.UpdateStack(ctx, params)
+	add("access-logs-s3-bucket", settings.AccessLogsS3Bucket)
+	add("access-logs-s3-prefix", settings.AccessLogsS3Prefix)
+	if settings.AccessLogsDisabled {
+		add("access-logs-disabled", "true")
+	}
+	add("subnet-selector", settings.SubnetSelector)
+	add("eip-allocations", strings.Join(settings.EIPAllocations, ","))
+	add("private-ipv4-addresses", strings.Join(settings.PrivateIPv4Addresses, ","))
+	add("nlb-cross-zone", settings.NLBCrossZone)
+	add("nlb-zone-affinity", settings.NLBZoneAffinity)
+	add("listeners", settings.Listeners)
+	add("http-listener-mode", settings.HTTPListenerMode)
+	add("mtls-mode", settings.MTLSMode)
+	add("mtls-ca-bundle-ref", settings.MTLSCABundleRef)
+	if settings.StrictHosts {
+		add("strict-hosts", "true")
+	}
+	add("source-ranges", settings.SourceRanges)
+	add("origin-header-ref", settings.OriginHeaderRef)
+	add("target-group-attributes", settings.TargetGroupAttributes)
+	add("load-balancer-attributes", settings.LoadBalancerAttributes)
+	add("response-headers", settings.ResponseHeaders)
+	add("target-protocol-version", settings.TargetProtocolVersion)
+	add("health-check-matcher", settings.HealthCheckMatcher)
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	// map keys are sorted by json.Marshal which makes the hash stable.
+	buf, err := json.Marshal(values)
 	if err != nil {
-		return spec.name, err
+		log.Errorf("failed to marshal settings: %v", err)
+		return ""
 	}
 
-	return aws.ToString(resp.StackId), nil
+	hash := sha256.Sum256(buf)
+	return hex.EncodeToString(hash[:])
 }
 
 func mergeTags(tags ...map[string]string) map[string]string {
@@ -490,24 +771,40 @@ func mapToManagedStack(stack *types.Stack) *Stack {
 		http2 = false
 	}
 
+	// a missing or invalid parameter means no capacity is reserved
+	capacityUnits, _ := strconv.Atoi(parameters[parameterCapacityUnitsParameter])
+
 	return &Stack{
-		Name:              aws.ToString(stack.StackName),
-		LoadBalancerARN:   outputs.loadBalancerARN(),
-		DNSName:           outputs.dnsName(),
-		TargetGroupARNs:   outputs.targetGroupARNs(),
-		Scheme:            parameters[parameterLoadBalancerSchemeParameter],
-		SecurityGroup:     parameters[parameterLoadBalancerSecurityGroupParameter],
-		SSLPolicy:         parameters[parameterListenerSslPolicyParameter],
-		IpAddressType:     parameters[parameterIpAddressTypeParameter],
-		LoadBalancerType:  parameters[parameterLoadBalancerTypeParameter],
-		HTTP2:             http2,
-		CertificateARNs:   certificateARNs,
-		tags:              tags,
-		OwnerIngress:      ownerIngress,
-		status:            stack.StackStatus,
-		statusReason:      aws.ToString(stack.StackStatusReason),
-		CWAlarmConfigHash: tags[cwAlarmConfigHashTag],
-		WAFWebACLID:       parameters[parameterLoadBalancerWAFWebACLIDParameter],
+		Name:                 aws.ToString(stack.StackName),
+		LoadBalancerARN:      outputs.loadBalancerARN(),
+		DNSName:              outputs.dnsName(),
+		TargetGroupARNs:      outputs.targetGroupARNs(),
+		Scheme:               parameters[parameterLoadBalancerSchemeParameter],
+		SecurityGroup:        parameters[parameterLoadBalancerSecurityGroupParameter],
+		SSLPolicy:            parameters[parameterListenerSslPolicyParameter],
+		IpAddressType:        parameters[parameterIpAddressTypeParameter],
+		LoadBalancerType:     parameters[parameterLoadBalancerTypeParameter],
+		HTTP2:                http2,
+		CertificateARNs:      certificateARNs,
+		tags:                 tags,
+		OwnerIngress:         ownerIngress,
+		status:               stack.StackStatus,
+		statusReason:         aws.ToString(stack.StackStatusReason),
+		CWAlarmConfigHash:    tags[cwAlarmConfigHashTag],
+		WAFWebACLID:          parameters[parameterLoadBalancerWAFWebACLIDParameter],
+		SettingsHash:         tags[settingsHashTag],
+		MTLSCABundleHash:     tags[caBundleHashTag],
+		AuthConfigHash:       tags[authConfigHashTag],
+		HostnamesHash:        tags[hostnamesHashTag],
+		SourceRangesHash:     tags[sourceRangesHashTag],
+		OriginHeaderHash:     tags[originHeaderHashTag],
+		Maintenance:          tags[maintenanceTag] == "true",
+		MaintenanceHostsHash: tags[maintenanceHostsHashTag],
+		CapacityUnits:        capacityUnits,
+		NLBSecurityGroup:     tags[nlbSecurityGroupTag] == "true",
+		FleetsHash:           tags[fleetsHashTag],
+		FleetTargetGroupARNs: outputs.fleetTargetGroupARNs(),
+		FleetWeightsHash:     tags[fleetWeightsHashTag],
 	}
 }
 
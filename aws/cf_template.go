@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"crypto/sha256"
+	"maps"
+	"slices"
 	"sort"
 
 	cloudformation "github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
@@ -116,6 +118,55 @@ func generateTemplate(spec *stackSpec) (string, error) {
 		}
 	}
 
+	var mutualAuthentication *cloudformation.ElasticLoadBalancingV2ListenerMutualAuthentication
+	if spec.mtlsMode != "" {
+		template.Parameters[parameterMTLSModeParameter] = &cloudformation.Parameter{
+			Type:        "String",
+			Description: "Mutual TLS mode of the HTTPS listeners",
+		}
+		mutualAuthentication = &cloudformation.ElasticLoadBalancingV2ListenerMutualAuthentication{
+			Mode: cloudformation.Ref(parameterMTLSModeParameter).String(),
+		}
+	}
+
+	if spec.mtlsCABundleHash != "" {
+		template.AddResource(trustStoreResourceName, &cloudformation.ElasticLoadBalancingV2TrustStore{
+			CaCertificatesBundleS3Bucket: cloudformation.String(spec.mtlsCABundleS3Bucket),
+			CaCertificatesBundleS3Key:    cloudformation.String(spec.mtlsCABundleS3Key),
+		})
+		mutualAuthentication.TrustStoreArn = cloudformation.Ref(trustStoreResourceName).String()
+	}
+
+	if spec.capacityUnits > 0 {
+		template.Parameters[parameterCapacityUnitsParameter] = &cloudformation.Parameter{
+			Type:        "Number",
+			Description: "Minimum capacity units reserved for the load balancer",
+		}
+	}
+
+	if spec.originHeader != nil {
+		template.Parameters[parameterOriginHeaderValueParameter] = &cloudformation.Parameter{
+			Type:        "String",
+			NoEcho:      cloudformation.Bool(true),
+			Description: "Value of the origin header required by the load balancer",
+		}
+		if spec.originHeader.PreviousValue != "" {
+			template.Parameters[parameterOriginHeaderPreviousValueParameter] = &cloudformation.Parameter{
+				Type:        "String",
+				NoEcho:      cloudformation.Bool(true),
+				Description: "Previous value of the origin header accepted during its rotation",
+			}
+		}
+	}
+
+	if spec.auth != nil && spec.auth.Type == AuthTypeOIDC {
+		template.Parameters[parameterAuthClientSecretParameter] = &cloudformation.Parameter{
+			Type:        "String",
+			NoEcho:      cloudformation.Bool(true),
+			Description: "Client secret of the OpenID Connect identity provider",
+		}
+	}
+
 	const httpsTargetGroupName = "TG"
 
 	template.Outputs = map[string]*cloudformation.Output{
@@ -134,9 +185,12 @@ func generateTemplate(spec *stackSpec) (string, error) {
 	}
 
 	template.AddResource(httpsTargetGroupName, newTargetGroup(spec, parameterTargetGroupTargetPortParameter))
+	addFleetTargetGroups(template, spec)
+
+	listeners := spec.listenersOrDefault()
 
-	if !spec.httpDisabled {
-		// Use the same target group for HTTP Listener or create another one if needed
+	if hasInsecureListener(listeners) {
+		// Use the same target group for HTTP Listeners or create another one if needed
 		httpTargetGroupName := httpsTargetGroupName
 		if spec.httpTargetPort != spec.targetPort {
 			httpTargetGroupName = "TGHTTP"
@@ -151,29 +205,74 @@ func generateTemplate(spec *stackSpec) (string, error) {
 			template.AddResource(httpTargetGroupName, newTargetGroup(spec, parameterTargetGroupHTTPTargetPortParameter))
 		}
 
-		// Add an HTTP Listener resource
-		if spec.loadbalancerType == LoadBalancerTypeApplication {
-			if spec.httpRedirectToHTTPS {
-				template.AddResource("HTTPListener", &cloudformation.ElasticLoadBalancingV2Listener{
-					DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
-						{
-							Type: cloudformation.String("redirect"),
-							RedirectConfig: &cloudformation.ElasticLoadBalancingV2ListenerRedirectConfig{
-								Protocol:   cloudformation.String("HTTPS"),
-								Port:       cloudformation.String("443"),
-								Host:       cloudformation.String("#{host}"),
-								Path:       cloudformation.String("/#{path}"),
-								Query:      cloudformation.String("#{query}"),
-								StatusCode: cloudformation.String("HTTP_301"),
+		// HTTP requests are redirected to the first secure listener
+		redirectPort := defaultHTTPSListenerPort
+		if i := slices.IndexFunc(listeners, Listener.secure); i >= 0 {
+			redirectPort = int(listeners[i].Port)
+		}
+
+		for _, listener := range listeners {
+			if listener.secure() || listener.dedicated() {
+				continue
+			}
+
+			suffix := listener.resourceSuffix(defaultHTTPListenerPort)
+			listenerName := "HTTPListener" + suffix
+
+			// Add an HTTP Listener resource
+			if spec.loadbalancerType == LoadBalancerTypeApplication {
+				if spec.httpRedirectToHTTPS {
+					template.AddResource(listenerName, &cloudformation.ElasticLoadBalancingV2Listener{
+						DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
+							{
+								Type: cloudformation.String("redirect"),
+								RedirectConfig: &cloudformation.ElasticLoadBalancingV2ListenerRedirectConfig{
+									Protocol:   cloudformation.String("HTTPS"),
+									Port:       cloudformation.String(fmt.Sprintf("%d", redirectPort)),
+									Host:       cloudformation.String("#{host}"),
+									Path:       cloudformation.String("/#{path}"),
+									Query:      cloudformation.String("#{query}"),
+									StatusCode: cloudformation.String("HTTP_301"),
+								},
 							},
 						},
-					},
-					LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
-					Port:            cloudformation.Integer(80),
-					Protocol:        cloudformation.String("HTTP"),
-				})
-			} else {
-				template.AddResource("HTTPListener", &cloudformation.ElasticLoadBalancingV2Listener{
+						LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
+						Port:            cloudformation.Integer(int64(listener.Port)),
+						Protocol:        cloudformation.String(listener.Protocol),
+					})
+				} else {
+					httpListener := &cloudformation.ElasticLoadBalancingV2Listener{
+						DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
+							{
+								Type:           cloudformation.String("forward"),
+								TargetGroupArn: cloudformation.Ref(httpTargetGroupName).String(),
+							},
+						},
+						LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
+						Port:            cloudformation.Integer(int64(listener.Port)),
+						Protocol:        cloudformation.String(listener.Protocol),
+					}
+					addListenerRules(template, spec, listenerName, httpListener, httpTargetGroupName, false)
+					// the fleets are only served on the target port
+					if httpTargetGroupName == httpsTargetGroupName {
+						addFleetRules(template, spec, listenerName, false)
+						setFleetWeights(httpListener, spec, httpTargetGroupName)
+					}
+					template.AddResource(listenerName, httpListener)
+					if spec.denyInternalDomains {
+						template.AddResource(
+							"HTTPRuleBlockInternalTraffic"+suffix,
+							generateDenyInternalTrafficRule(
+								listenerName,
+								internalTrafficDenyRulePriority,
+								spec.internalDomains,
+								spec.denyInternalDomainsResponse,
+							),
+						)
+					}
+				}
+			} else if spec.loadbalancerType == LoadBalancerTypeNetwork {
+				template.AddResource(listenerName, &cloudformation.ElasticLoadBalancingV2Listener{
 					DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
 						{
 							Type:           cloudformation.String("forward"),
@@ -181,33 +280,10 @@ func generateTemplate(spec *stackSpec) (string, error) {
 						},
 					},
 					LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
-					Port:            cloudformation.Integer(80),
-					Protocol:        cloudformation.String("HTTP"),
+					Port:            cloudformation.Integer(int64(listener.Port)),
+					Protocol:        cloudformation.String(listener.Protocol),
 				})
-				if spec.denyInternalDomains {
-					template.AddResource(
-						"HTTPRuleBlockInternalTraffic",
-						generateDenyInternalTrafficRule(
-							"HTTPListener",
-							internalTrafficDenyRulePriority,
-							spec.internalDomains,
-							spec.denyInternalDomainsResponse,
-						),
-					)
-				}
 			}
-		} else if spec.loadbalancerType == LoadBalancerTypeNetwork {
-			template.AddResource("HTTPListener", &cloudformation.ElasticLoadBalancingV2Listener{
-				DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
-					{
-						Type:           cloudformation.String("forward"),
-						TargetGroupArn: cloudformation.Ref(httpTargetGroupName).String(),
-					},
-				},
-				LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
-				Port:            cloudformation.Integer(80),
-				Protocol:        cloudformation.String("TCP"),
-			})
 		}
 	}
 
@@ -221,74 +297,76 @@ func generateTemplate(spec *stackSpec) (string, error) {
 			return certificateARNs[i] < certificateARNs[j]
 		})
 
-		// Add an HTTPS Listener resource with the first certificate as the default one
-		if spec.loadbalancerType == LoadBalancerTypeApplication {
-			template.AddResource("HTTPSListener", &cloudformation.ElasticLoadBalancingV2Listener{
-				DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
-					{
-						Type:           cloudformation.String("forward"),
-						TargetGroupArn: cloudformation.Ref(httpsTargetGroupName).String(),
-					},
+		for _, listener := range listeners {
+			if !listener.secure() || listener.dedicated() {
+				continue
+			}
+
+			suffix := listener.resourceSuffix(defaultHTTPSListenerPort)
+			listenerName := "HTTPSListener" + suffix
+
+			defaultActions := cloudformation.ElasticLoadBalancingV2ListenerActionList{
+				{
+					Type:           cloudformation.String("forward"),
+					TargetGroupArn: cloudformation.Ref(httpsTargetGroupName).String(),
 				},
+			}
+			if spec.auth != nil {
+				defaultActions[0].Order = cloudformation.Integer(2)
+				defaultActions = append(cloudformation.ElasticLoadBalancingV2ListenerActionList{spec.auth.authenticateAction()}, defaultActions...)
+			}
+
+			// Add an HTTPS Listener resource with the first certificate as the default one
+			httpsListener := &cloudformation.ElasticLoadBalancingV2Listener{
+				DefaultActions: &defaultActions,
 				Certificates: &cloudformation.ElasticLoadBalancingV2ListenerCertificatePropertyList{
 					{
 						CertificateArn: cloudformation.String(certificateARNs[0]),
 					},
 				},
-				LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
-				Port:            cloudformation.Integer(443),
-				Protocol:        cloudformation.String("HTTPS"),
-				SslPolicy:       cloudformation.Ref(parameterListenerSslPolicyParameter).String(),
-			})
-			if spec.denyInternalDomains {
+				LoadBalancerArn:      cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
+				MutualAuthentication: mutualAuthentication,
+				Port:                 cloudformation.Integer(int64(listener.Port)),
+				Protocol:             cloudformation.String(listener.Protocol),
+				SslPolicy:            cloudformation.Ref(parameterListenerSslPolicyParameter).String(),
+				ListenerAttributes:   responseHeaderListenerAttributes(spec.responseHeaders),
+			}
+			addListenerRules(template, spec, listenerName, httpsListener, httpsTargetGroupName, spec.auth != nil)
+			addFleetRules(template, spec, listenerName, spec.auth != nil)
+			setFleetWeights(httpsListener, spec, httpsTargetGroupName)
+			template.AddResource(listenerName, httpsListener)
+			if spec.loadbalancerType == LoadBalancerTypeApplication && spec.denyInternalDomains {
 				template.AddResource(
-					"HTTPSRuleBlockInternalTraffic",
+					"HTTPSRuleBlockInternalTraffic"+suffix,
 					generateDenyInternalTrafficRule(
-						"HTTPSListener",
+						listenerName,
 						internalTrafficDenyRulePriority,
 						spec.internalDomains,
 						spec.denyInternalDomainsResponse,
 					),
 				)
 			}
-		} else if spec.loadbalancerType == LoadBalancerTypeNetwork {
-			template.AddResource("HTTPSListener", &cloudformation.ElasticLoadBalancingV2Listener{
-				DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
-					{
-						Type:           cloudformation.String("forward"),
-						TargetGroupArn: cloudformation.Ref(httpsTargetGroupName).String(),
-					},
-				},
-				Certificates: &cloudformation.ElasticLoadBalancingV2ListenerCertificatePropertyList{
-					{
-						CertificateArn: cloudformation.String(certificateARNs[0]),
-					},
-				},
-				LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
-				Port:            cloudformation.Integer(443),
-				Protocol:        cloudformation.String("TLS"),
-				SslPolicy:       cloudformation.Ref(parameterListenerSslPolicyParameter).String(),
-			})
-		}
 
-		// Add a ListenerCertificate resource with all of the certificates, including the default one
-		certificateList := make(cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificateList, 0, len(certificateARNs))
-		for _, certARN := range certificateARNs {
-			c := cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificate{
-				CertificateArn: cloudformation.String(certARN),
+			// Add a ListenerCertificate resource with all of the certificates, including the default one
+			certificateList := make(cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificateList, 0, len(certificateARNs))
+			for _, certARN := range certificateARNs {
+				c := cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificate{
+					CertificateArn: cloudformation.String(certARN),
+				}
+				certificateList = append(certificateList, c)
 			}
-			certificateList = append(certificateList, c)
-		}
-
-		// Use a new resource name every time to avoid a bug where CloudFormation fails to perform an update properly
-		resourceName := fmt.Sprintf("HTTPSListenerCertificate%x", hashARNs(certificateARNs))
-		template.AddResource(resourceName, &cloudformation.ElasticLoadBalancingV2ListenerCertificate{
-			Certificates: &certificateList,
-			ListenerArn:  cloudformation.Ref("HTTPSListener").String(),
-		})
 
+			// Use a new resource name every time to avoid a bug where CloudFormation fails to perform an update properly
+			resourceName := fmt.Sprintf("%sCertificate%x", listenerName, hashARNs(certificateARNs))
+			template.AddResource(resourceName, &cloudformation.ElasticLoadBalancingV2ListenerCertificate{
+				Certificates: &certificateList,
+				ListenerArn:  cloudformation.Ref(listenerName).String(),
+			})
+		}
 	}
 
+	addDedicatedListeners(template, spec, listeners)
+
 	// Build up the LoadBalancerAttributes list, as there is no way to make attributes conditional in the template
 	lbAttrList := make(cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttributeList, 0, 5)
 
@@ -359,6 +437,8 @@ func generateTemplate(spec *stackSpec) (string, error) {
 		)
 	}
 
+	lbAttrList = append(lbAttrList, loadBalancerAttributeList(spec.loadBalancerAttributes)...)
+
 	lb := &cloudformation.ElasticLoadBalancingV2LoadBalancer{
 		LoadBalancerAttributes: &lbAttrList,
 
@@ -373,9 +453,37 @@ func generateTemplate(spec *stackSpec) (string, error) {
 		},
 	}
 
-	// Security groups can't be set for 'network' load balancers
-	if spec.loadbalancerType != LoadBalancerTypeNetwork {
+	// Subnets and SubnetMappings are mutually exclusive
+	if len(spec.subnetMappings) > 0 {
+		subnetMappings := make(cloudformation.ElasticLoadBalancingV2LoadBalancerSubnetMappingList, 0, len(spec.subnetMappings))
+		for _, mapping := range spec.subnetMappings {
+			subnetMapping := cloudformation.ElasticLoadBalancingV2LoadBalancerSubnetMapping{
+				SubnetID: cloudformation.String(mapping.subnetID),
+			}
+			if mapping.allocationID != "" {
+				subnetMapping.AllocationID = cloudformation.String(mapping.allocationID)
+			}
+			if mapping.privateIPv4Address != "" {
+				subnetMapping.PrivateIPv4Address = cloudformation.String(mapping.privateIPv4Address)
+			}
+			subnetMappings = append(subnetMappings, subnetMapping)
+		}
+		lb.Subnets = nil
+		lb.SubnetMappings = &subnetMappings
+	}
+
+	if spec.capacityUnits > 0 {
+		lb.MinimumLoadBalancerCapacity = &cloudformation.ElasticLoadBalancingV2LoadBalancerMinimumLoadBalancerCapacity{
+			CapacityUnits: cloudformation.Ref(parameterCapacityUnitsParameter).Integer(),
+		}
+	}
+
+	// Security groups can't be added to existing 'network' load balancers
+	// without any, so they are only set when requested at creation.
+	if spec.loadbalancerType != LoadBalancerTypeNetwork || spec.nlbSecurityGroup {
 		lb.SecurityGroups = cloudformation.Ref(parameterLoadBalancerSecurityGroupParameter).StringList()
+	} else if len(spec.sourceRanges) > 0 {
+		addSourceRangesSecurityGroup(template, spec, lb)
 	}
 
 	// TODO(mlarsen): hack to only set type on "new" stacks where this
@@ -463,6 +571,72 @@ func generateDenyInternalTrafficRule(listenerName string, rulePriority int64, in
 	}
 }
 
+// addDedicatedListeners adds the listeners of network load balancers which
+// forward to their own target group on the target port of the listener.
+func addDedicatedListeners(template *cloudformation.Template, spec *stackSpec, listeners []Listener) {
+	certificateARNs := slices.Sorted(maps.Keys(spec.certificateARNs))
+
+	for _, listener := range dedicatedListeners(listeners) {
+		// like the HTTPS listeners, TLS listeners require a certificate
+		if listener.secure() && len(certificateARNs) == 0 {
+			continue
+		}
+
+		targetGroupName := listener.targetGroupName()
+		template.AddResource(targetGroupName, newDedicatedTargetGroup(spec, listener))
+		template.Outputs[fmt.Sprintf("%s%d", outputListenerTargetGroupARNPrefix, listener.Port)] = &cloudformation.Output{
+			Description: fmt.Sprintf("The ARN of the TargetGroup of the listener on port %d", listener.Port),
+			Value:       cloudformation.Ref(targetGroupName).String(),
+		}
+
+		listenerName := listener.resourceName()
+		resource := &cloudformation.ElasticLoadBalancingV2Listener{
+			DefaultActions: &cloudformation.ElasticLoadBalancingV2ListenerActionList{
+				{
+					Type:           cloudformation.String("forward"),
+					TargetGroupArn: cloudformation.Ref(targetGroupName).String(),
+				},
+			},
+			LoadBalancerArn: cloudformation.Ref(LoadBalancerResourceLogicalID).String(),
+			Port:            cloudformation.Integer(int64(listener.Port)),
+			Protocol:        cloudformation.String(listener.Protocol),
+		}
+		if listener.secure() {
+			resource.Certificates = &cloudformation.ElasticLoadBalancingV2ListenerCertificatePropertyList{
+				{
+					CertificateArn: cloudformation.String(certificateARNs[0]),
+				},
+			}
+			resource.SslPolicy = cloudformation.Ref(parameterListenerSslPolicyParameter).String()
+
+			certificateList := make(cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificateList, 0, len(certificateARNs))
+			for _, certARN := range certificateARNs {
+				certificateList = append(certificateList, cloudformation.ElasticLoadBalancingV2ListenerCertificateCertificate{
+					CertificateArn: cloudformation.String(certARN),
+				})
+			}
+			template.AddResource(fmt.Sprintf("%sCertificate%x", listenerName, hashARNs(certificateARNs)), &cloudformation.ElasticLoadBalancingV2ListenerCertificate{
+				Certificates: &certificateList,
+				ListenerArn:  cloudformation.Ref(listenerName).String(),
+			})
+		}
+		template.AddResource(listenerName, resource)
+	}
+}
+
+// newDedicatedTargetGroup returns the target group of a dedicated listener.
+// The targets are health checked with TCP connections to the target port as
+// they don't necessarily serve HTTP.
+func newDedicatedTargetGroup(spec *stackSpec, listener Listener) *cloudformation.ElasticLoadBalancingV2TargetGroup {
+	targetGroup := newTargetGroup(spec, "")
+	targetGroup.Port = cloudformation.Integer(int64(listener.TargetPort))
+	targetGroup.Protocol = cloudformation.String(ListenerProtocolTCP)
+	targetGroup.HealthCheckProtocol = cloudformation.String(ListenerProtocolTCP)
+	targetGroup.HealthCheckPort = cloudformation.String("traffic-port")
+	targetGroup.HealthCheckPath = nil
+	return targetGroup
+}
+
 func newTargetGroup(spec *stackSpec, targetPortParameter string) *cloudformation.ElasticLoadBalancingV2TargetGroup {
 	var targetType *cloudformation.StringExpr
 	if spec.targetType != "" {
@@ -501,9 +675,22 @@ func newTargetGroup(spec *stackSpec, targetPortParameter string) *cloudformation
 		VPCID:                      cloudformation.Ref(parameterTargetGroupVPCIDParameter).String(),
 	}
 
+	*targetGroup.TargetGroupAttributes = append(*targetGroup.TargetGroupAttributes, targetGroupAttributeList(spec.targetGroupAttributes)...)
+
 	// custom target group healthcheck only supported when the target group protocol is != TCP
 	if protocol != "TCP" {
 		targetGroup.HealthCheckTimeoutSeconds = cloudformation.Ref(parameterTargetGroupHealthCheckTimeoutParameter).Integer()
 	}
+
+	// The default HTTP/1.1 protocol version isn't set explicitly to keep
+	// existing target groups, as changing the protocol version replaces
+	// them. The HTTP target port only serves HTTP listeners, which require
+	// HTTP/1.1.
+	if spec.loadbalancerType == LoadBalancerTypeApplication && targetPortParameter == parameterTargetGroupTargetPortParameter {
+		if spec.targetProtocolVersion != "" && spec.targetProtocolVersion != TargetProtocolVersionHTTP1 {
+			targetGroup.ProtocolVersion = cloudformation.String(spec.targetProtocolVersion)
+		}
+		targetGroup.Matcher = spec.healthCheckMatcherProperty()
+	}
 	return targetGroup
 }
@@ -386,6 +386,580 @@ func TestGenerateTemplate(t *testing.T) {
 				require.NotNil(t, props.WebACLID)
 			},
 		},
+		{
+			name: "access logs are disabled without bucket",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				properties := template.Resources[LoadBalancerResourceLogicalID].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				attributes := []cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute(*properties.LoadBalancerAttributes)
+				require.Contains(t, attributes, cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute{
+					Key:   cloudformation.String("access_logs.s3.enabled"),
+					Value: cloudformation.String("false"),
+				})
+			},
+		},
+		{
+			name: "NLB uses subnet mappings when configured",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				subnetMappings: []*subnetMapping{
+					{subnetID: "subnet-1", allocationID: "eipalloc-a"},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				properties := template.Resources[LoadBalancerResourceLogicalID].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Nil(t, properties.Subnets)
+				require.Equal(t, &cloudformation.ElasticLoadBalancingV2LoadBalancerSubnetMappingList{
+					{
+						SubnetID:     cloudformation.String("subnet-1"),
+						AllocationID: cloudformation.String("eipalloc-a"),
+					},
+				}, properties.SubnetMappings)
+			},
+		},
+		{
+			name: "ALB renders one listener per configured listener",
+			spec: &stackSpec{
+				loadbalancerType:            LoadBalancerTypeApplication,
+				certificateARNs:             map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS:         true,
+				denyInternalDomains:         true,
+				denyInternalDomainsResponse: denyResp,
+				internalDomains:             internalDomains,
+				listeners: []Listener{
+					{Protocol: ListenerProtocolHTTP, Port: 8080},
+					{Protocol: ListenerProtocolHTTPS, Port: 8443},
+					{Protocol: ListenerProtocolHTTPS, Port: 9443},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireListeners(t, template, "HTTPListener8080", "HTTPSListener8443", "HTTPSListener9443")
+
+				listener := template.Resources["HTTPListener8080"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Equal(t, cloudformation.Integer(8080), listener.Port)
+				redirectConfig := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)[0].RedirectConfig
+				require.Equal(t, cloudformation.String("8443"), redirectConfig.Port)
+
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener8443", 8443, "HTTPS")
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener9443", 9443, "HTTPS")
+				validateDenyRule(t, template.Resources["HTTPSRuleBlockInternalTraffic8443"])
+				validateDenyRule(t, template.Resources["HTTPSRuleBlockInternalTraffic9443"])
+
+				var certificateListeners []interface{}
+				for _, resource := range template.Resources {
+					if c, ok := resource.Properties.(*cloudformation.ElasticLoadBalancingV2ListenerCertificate); ok {
+						certificateListeners = append(certificateListeners, c.ListenerArn)
+					}
+				}
+				require.ElementsMatch(t, []interface{}{
+					cloudformation.Ref("HTTPSListener8443").String(),
+					cloudformation.Ref("HTTPSListener9443").String(),
+				}, certificateListeners)
+			},
+		},
+		{
+			name: "NLB renders configured TLS listeners without HTTP listener",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				targetPort:       9999,
+				httpTargetPort:   8888,
+				listeners: []Listener{
+					{Protocol: ListenerProtocolTLS, Port: 443},
+					{Protocol: ListenerProtocolTLS, Port: 8443},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG")
+				requireListeners(t, template, "HTTPSListener", "HTTPSListener8443")
+				require.NotContains(t, template.Parameters, parameterTargetGroupHTTPTargetPortParameter)
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener", 443, "TLS")
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener8443", 8443, "TLS")
+			},
+		},
+		{
+			name: "ALB with mTLS verify mode has trust store",
+			spec: &stackSpec{
+				loadbalancerType:     LoadBalancerTypeApplication,
+				certificateARNs:      map[string]time.Time{"domain.company.com": time.Now()},
+				mtlsMode:             MTLSModeVerify,
+				mtlsCABundleHash:     "abc",
+				mtlsCABundleS3Bucket: "bucket",
+				mtlsCABundleS3Key:    "cluster/abc.pem",
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.Contains(t, template.Parameters, parameterMTLSModeParameter)
+
+				trustStore, ok := template.Resources["TrustStore"].Properties.(*cloudformation.ElasticLoadBalancingV2TrustStore)
+				require.True(t, ok, "TrustStore expected")
+				require.Equal(t, cloudformation.String("bucket"), trustStore.CaCertificatesBundleS3Bucket)
+				require.Equal(t, cloudformation.String("cluster/abc.pem"), trustStore.CaCertificatesBundleS3Key)
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.NotNil(t, listener.MutualAuthentication)
+				require.Equal(t, cloudformation.Ref(parameterMTLSModeParameter).String(), listener.MutualAuthentication.Mode)
+				require.Equal(t, cloudformation.Ref("TrustStore").String(), listener.MutualAuthentication.TrustStoreArn)
+			},
+		},
+		{
+			name: "ALB with mTLS passthrough mode has no trust store",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				mtlsMode:         MTLSModePassthrough,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, "TrustStore")
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.NotNil(t, listener.MutualAuthentication)
+				require.Nil(t, listener.MutualAuthentication.TrustStoreArn)
+			},
+		},
+		{
+			name: "ALB with OIDC authentication authenticates before forwarding",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS: true,
+				auth: &AuthConfig{
+					Type:                  AuthTypeOIDC,
+					Issuer:                "https://idp.example.org",
+					AuthorizationEndpoint: "https://idp.example.org/authorize",
+					TokenEndpoint:         "https://idp.example.org/token",
+					UserInfoEndpoint:      "https://idp.example.org/userinfo",
+					ClientID:              "client",
+					ClientSecret:          "secret",
+					SessionTimeout:        3600,
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.Equal(t, cloudformation.Bool(true), template.Parameters[parameterAuthClientSecretParameter].NoEcho)
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Len(t, actions, 2)
+
+				require.Equal(t, cloudformation.String("authenticate-oidc"), actions[0].Type)
+				require.Equal(t, cloudformation.Integer(1), actions[0].Order)
+				oidc := actions[0].AuthenticateOidcConfig
+				require.Equal(t, cloudformation.String("https://idp.example.org"), oidc.Issuer)
+				require.Equal(t, cloudformation.String("client"), oidc.ClientID)
+				require.Equal(t, cloudformation.Ref(parameterAuthClientSecretParameter).String(), oidc.ClientSecret)
+				require.Equal(t, cloudformation.String("3600"), oidc.SessionTimeout)
+				require.Nil(t, oidc.SessionCookieName)
+
+				require.Equal(t, cloudformation.String("forward"), actions[1].Type)
+				require.Equal(t, cloudformation.Integer(2), actions[1].Order)
+			},
+		},
+		{
+			name: "ALB with Cognito authentication authenticates before forwarding",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS: true,
+				auth: &AuthConfig{
+					Type:              AuthTypeCognito,
+					UserPoolArn:       "arn:aws:cognito-idp:eu-central-1:123456789012:userpool/eu-central-1_foo",
+					UserPoolClientID:  "client",
+					UserPoolDomain:    "foo",
+					SessionCookieName: "session",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Parameters, parameterAuthClientSecretParameter)
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Len(t, actions, 2)
+
+				require.Equal(t, cloudformation.String("authenticate-cognito"), actions[0].Type)
+				cognito := actions[0].AuthenticateCognitoConfig
+				require.Equal(t, cloudformation.String("foo"), cognito.UserPoolDomain)
+				require.Equal(t, cloudformation.String("session"), cognito.SessionCookieName)
+				require.Equal(t, cloudformation.String("forward"), actions[1].Type)
+			},
+		},
+		{
+			name: "ALB in strict hosts mode only forwards the hostnames",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				strictHosts:      true,
+				hostnames:        []string{"a.org", "b.org", "c.org", "d.org", "e.org", "f.org", "g.org"},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					listener := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+					actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+					require.Len(t, actions, 1)
+					require.Equal(t, cloudformation.String("404"), actions[0].FixedResponseConfig.StatusCode)
+
+					for i, hostnames := range [][]string{{"a.org", "b.org", "c.org", "d.org", "e.org"}, {"f.org", "g.org"}} {
+						rule := template.Resources[fmt.Sprintf("%sRuleForward%d", name, i+1)].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+						require.Equal(t, cloudformation.Integer(forwardRulePriorityOffset+int64(i)), rule.Priority)
+						require.Equal(t, cloudformation.Ref(name).String(), rule.ListenerArn)
+
+						condition := []cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition(*rule.Conditions)[0]
+						var values []string
+						for _, v := range condition.Values.Literal {
+							values = append(values, v.Literal)
+						}
+						require.Equal(t, hostnames, values)
+					}
+				}
+				require.Contains(t, template.Resources, "HTTPListenerRuleForward2")
+				require.NotContains(t, template.Resources, "HTTPSListenerRuleForward3")
+			},
+		},
+		{
+			name: "ALB in strict hosts mode doesn't add rules to redirecting HTTP listener",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS: true,
+				strictHosts:         true,
+				hostnames:           []string{"a.org"},
+				auth: &AuthConfig{
+					Type:             AuthTypeCognito,
+					UserPoolArn:      "arn:aws:cognito-idp:eu-central-1:123456789012:userpool/eu-central-1_foo",
+					UserPoolClientID: "client",
+					UserPoolDomain:   "foo",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, "HTTPListenerRuleForward1")
+
+				rule := template.Resources["HTTPSListenerRuleForward1"].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerRuleAction(*rule.Actions)
+				require.Len(t, actions, 2)
+				require.Equal(t, cloudformation.String("authenticate-cognito"), actions[0].Type)
+				require.Equal(t, cloudformation.String("forward"), actions[1].Type)
+				require.Equal(t, cloudformation.Ref("TG").String(), actions[1].TargetGroupArn)
+			},
+		},
+		{
+			name: "ALB with source ranges only forwards requests from the source ranges",
+			spec: &stackSpec{
+				loadbalancerType:         LoadBalancerTypeApplication,
+				certificateARNs:          map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS:      true,
+				sourceRanges:             []string{"10.0.0.0/8", "2001:db8::/32"},
+				sourceRangesDenyResponse: defaultSourceRangesDenyResponse,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, sourceRangesSecurityGroupResourceName)
+				require.NotContains(t, template.Resources, "HTTPListenerRuleForward1")
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Len(t, actions, 1)
+				require.Equal(t, cloudformation.String("403"), actions[0].FixedResponseConfig.StatusCode)
+				require.Equal(t, cloudformation.String("Forbidden"), actions[0].FixedResponseConfig.MessageBody)
+
+				rule := template.Resources["HTTPSListenerRuleForward1"].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+				condition := []cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition(*rule.Conditions)[0]
+				require.Equal(t, cloudformation.String("source-ip"), condition.Field)
+				require.Len(t, condition.SourceIPConfig.Values.Literal, 2)
+				require.NotContains(t, template.Resources, "HTTPSListenerRuleForward2")
+			},
+		},
+		{
+			name: "ALB with origin header only forwards requests with the header",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				originHeader:     &OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.Equal(t, cloudformation.Bool(true), template.Parameters[parameterOriginHeaderValueParameter].NoEcho)
+				require.Equal(t, cloudformation.Bool(true), template.Parameters[parameterOriginHeaderPreviousValueParameter].NoEcho)
+
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					listener := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+					actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+					require.Len(t, actions, 1)
+					require.Equal(t, cloudformation.String("403"), actions[0].FixedResponseConfig.StatusCode)
+
+					rule := template.Resources[name+"RuleForward1"].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+					conditions := []cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition(*rule.Conditions)
+					require.Len(t, conditions, 1)
+					require.Equal(t, cloudformation.String("http-header"), conditions[0].Field)
+					require.Equal(t, cloudformation.String("X-Origin-Secret"), conditions[0].HTTPHeaderConfig.HTTPHeaderName)
+					require.Equal(t, []*cloudformation.StringExpr{
+						cloudformation.Ref(parameterOriginHeaderValueParameter).String(),
+						cloudformation.Ref(parameterOriginHeaderPreviousValueParameter).String(),
+					}, conditions[0].HTTPHeaderConfig.Values.Literal)
+					require.NotContains(t, template.Resources, name+"RuleForward2")
+				}
+			},
+		},
+		{
+			name: "ALB in maintenance mode responds to all requests",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				httpRedirectToHTTPS: true,
+				strictHosts:         true,
+				hostnames:           []string{"a.org"},
+				maintenance:         true,
+				maintenanceResponse: defaultMaintenanceResponse,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, "HTTPSListenerRuleForward1")
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Len(t, actions, 1)
+				require.Equal(t, cloudformation.String("503"), actions[0].FixedResponseConfig.StatusCode)
+
+				redirect := template.Resources["HTTPListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Equal(t, cloudformation.String("redirect"), []cloudformation.ElasticLoadBalancingV2ListenerAction(*redirect.DefaultActions)[0].Type)
+			},
+		},
+		{
+			name: "ALB with hosts in maintenance",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				maintenanceHosts:    []string{"a.org", "b.org", "c.org", "d.org", "e.org", "f.org"},
+				maintenanceResponse: defaultMaintenanceResponse,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					listener := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+					require.Equal(t, cloudformation.String("forward"), []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)[0].Type)
+
+					for i := range 2 {
+						rule := template.Resources[fmt.Sprintf("%sRuleMaintenance%d", name, i+1)].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+						require.Equal(t, cloudformation.Integer(maintenanceRulePriorityOffset+int64(i)), rule.Priority)
+						actions := []cloudformation.ElasticLoadBalancingV2ListenerRuleAction(*rule.Actions)
+						require.Equal(t, cloudformation.String("503"), actions[0].FixedResponseConfig.StatusCode)
+					}
+					require.NotContains(t, template.Resources, name+"RuleMaintenance3")
+				}
+			},
+		},
+		{
+			name: "ALB with fleets",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				fleets: map[string][]string{
+					"canary": {"a.org", "b.org", "c.org", "d.org", "e.org", "f.org"},
+					"edge":   {"g.org"},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TGFleetCanary", "TGFleetEdge")
+				validateTargetGroupOutput(t, template, "TGFleetCanary", "FleetTargetGroupARNCanary")
+				validateTargetGroupOutput(t, template, "TGFleetEdge", "FleetTargetGroupARNEdge")
+
+				validateTargetGroupListener(t, template, "TG", "HTTPListener", 80, "HTTP")
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener", 443, "HTTPS")
+
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					for _, rule := range []struct {
+						name        string
+						targetGroup string
+						hosts       int
+						priority    int64
+					}{
+						{name + "RuleFleetCanary1", "TGFleetCanary", 5, fleetRulePriorityOffset},
+						{name + "RuleFleetCanary2", "TGFleetCanary", 1, fleetRulePriorityOffset + 1},
+						{name + "RuleFleetEdge1", "TGFleetEdge", 1, fleetRulePriorityOffset + maxFleetChunks},
+					} {
+						resource := template.Resources[rule.name].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+						require.Equal(t, cloudformation.Integer(rule.priority), resource.Priority)
+						conditions := []cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition(*resource.Conditions)
+						require.Len(t, conditions[0].Values.Literal, rule.hosts)
+						actions := []cloudformation.ElasticLoadBalancingV2ListenerRuleAction(*resource.Actions)
+						require.Equal(t, cloudformation.Ref(rule.targetGroup).String(), actions[0].TargetGroupArn)
+					}
+				}
+			},
+		},
+		{
+			name: "ALB with fleet rules and forward rules",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				strictHosts:      true,
+				hostnames:        []string{"a.org"},
+				fleets:           map[string][]string{"canary": {"b.org"}},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					forward := template.Resources[name+"RuleForward1"].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+					fleet := template.Resources[name+"RuleFleetCanary1"].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule)
+					require.NotEqual(t, forward.Priority, fleet.Priority)
+				}
+			},
+		},
+		{
+			name: "ALB with fleets and HTTP target port",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				targetPort:       9999,
+				httpTargetPort:   8888,
+				fleets:           map[string][]string{"canary": {"a.org"}},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TGHTTP", "TGFleetCanary")
+				require.Contains(t, template.Resources, "HTTPSListenerRuleFleetCanary1")
+				require.NotContains(t, template.Resources, "HTTPListenerRuleFleetCanary1")
+			},
+		},
+		{
+			name: "ALB with gRPC targets",
+			spec: &stackSpec{
+				loadbalancerType:      LoadBalancerTypeApplication,
+				certificateARNs:       map[string]time.Time{"domain.company.com": time.Now()},
+				targetPort:            9999,
+				httpTargetPort:        8888,
+				targetProtocolVersion: TargetProtocolVersionGRPC,
+				healthCheckMatcher:    "0-2",
+				fleets:                map[string][]string{"canary": {"a.org"}},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				for _, name := range []string{"TG", "TGFleetCanary"} {
+					tg := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+					require.Equal(t, cloudformation.String("GRPC"), tg.ProtocolVersion)
+					require.Equal(t, &cloudformation.ElasticLoadBalancingV2TargetGroupMatcher{GrpcCode: cloudformation.String("0-2")}, tg.Matcher)
+				}
+
+				tg := template.Resources["TGHTTP"].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+				require.Nil(t, tg.ProtocolVersion)
+				require.Nil(t, tg.Matcher)
+			},
+		},
+		{
+			name: "ALB with HTTP health check matcher",
+			spec: &stackSpec{
+				loadbalancerType:      LoadBalancerTypeApplication,
+				certificateARNs:       map[string]time.Time{"domain.company.com": time.Now()},
+				targetProtocolVersion: TargetProtocolVersionHTTP1,
+				healthCheckMatcher:    "200-299",
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				tg := template.Resources["TG"].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+				require.Nil(t, tg.ProtocolVersion)
+				require.Equal(t, &cloudformation.ElasticLoadBalancingV2TargetGroupMatcher{HTTPCode: cloudformation.String("200-299")}, tg.Matcher)
+			},
+		},
+		{
+			name: "ALB with fleet weights",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				fleets:           map[string][]string{"canary": {"a.org"}},
+				fleetWeights:     map[string]int{"edge": 5, "canary": 10},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TGFleetCanary", "TGFleetEdge")
+				validateTargetGroupOutput(t, template, "TGFleetEdge", "FleetTargetGroupARNEdge")
+				require.NotContains(t, template.Resources, "HTTPSListenerRuleFleetEdge1")
+
+				for _, name := range []string{"HTTPListener", "HTTPSListener"} {
+					listener := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+					actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+					require.Len(t, actions, 1)
+					require.Nil(t, actions[0].TargetGroupArn)
+					require.Equal(t, &cloudformation.ElasticLoadBalancingV2ListenerTargetGroupTupleList{
+						{TargetGroupArn: cloudformation.Ref("TG").String(), Weight: cloudformation.Integer(85)},
+						{TargetGroupArn: cloudformation.Ref("TGFleetCanary").String(), Weight: cloudformation.Integer(10)},
+						{TargetGroupArn: cloudformation.Ref("TGFleetEdge").String(), Weight: cloudformation.Integer(5)},
+					}, actions[0].ForwardConfig.TargetGroups)
+				}
+			},
+		},
+		{
+			name: "ALB with fleet weights and HTTP target port",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				targetPort:       9999,
+				httpTargetPort:   8888,
+				fleetWeights:     map[string]int{"canary": 50},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TGHTTP", "TGFleetCanary")
+				validateTargetGroupListener(t, template, "TGHTTP", "HTTPListener", 80, "HTTP")
+
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Len(t, *actions[0].ForwardConfig.TargetGroups, 2)
+			},
+		},
+		{
+			name: "ALB with fleet weights in maintenance",
+			spec: &stackSpec{
+				loadbalancerType:    LoadBalancerTypeApplication,
+				certificateARNs:     map[string]time.Time{"domain.company.com": time.Now()},
+				maintenance:         true,
+				maintenanceResponse: defaultMaintenanceResponse,
+				fleetWeights:        map[string]int{"canary": 50},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				listener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				actions := []cloudformation.ElasticLoadBalancingV2ListenerAction(*listener.DefaultActions)
+				require.Equal(t, cloudformation.String("fixed-response"), actions[0].Type)
+				require.Nil(t, actions[0].ForwardConfig)
+			},
+		},
+		{
+			name: "NLB with source ranges gets a security group",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				vpcID:            "vpc-123",
+				sourceRanges:     []string{"10.0.0.0/8", "2001:db8::/32"},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, "HTTPSListenerRuleForward1")
+
+				lb := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Equal(t, cloudformation.StringList(cloudformation.Ref(sourceRangesSecurityGroupResourceName)), lb.SecurityGroups)
+
+				sg := template.Resources[sourceRangesSecurityGroupResourceName].Properties.(*cloudformation.EC2SecurityGroup)
+				require.Equal(t, cloudformation.String("vpc-123"), sg.VpcID)
+				ingress := []cloudformation.EC2SecurityGroupIngressProperty(*sg.SecurityGroupIngress)
+				require.Len(t, ingress, 4)
+				require.Equal(t, cloudformation.String("10.0.0.0/8"), ingress[0].CidrIP)
+				require.Equal(t, cloudformation.String("2001:db8::/32"), ingress[1].CidrIPv6)
+				require.Equal(t, cloudformation.Integer(80), ingress[0].FromPort)
+				require.Equal(t, cloudformation.Integer(443), ingress[2].ToPort)
+			},
+		},
+		{
+			name: "NLB with security group",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				nlbSecurityGroup: true,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Resources, sourceRangesSecurityGroupResourceName)
+
+				lb := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Equal(t, cloudformation.Ref(parameterLoadBalancerSecurityGroupParameter).StringList(), lb.SecurityGroups)
+			},
+		},
+		{
+			name: "NLB without security group",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				lb := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Nil(t, lb.SecurityGroups)
+			},
+		},
 		{
 			name: "deregistration timeout is set correctly",
 			spec: &stackSpec{
@@ -403,6 +977,212 @@ func TestGenerateTemplate(t *testing.T) {
 				require.Equal(t, &expected, props.TargetGroupAttributes)
 			},
 		},
+		{
+			name: "target group attributes are added after the deregistration timeout",
+			spec: &stackSpec{
+				deregistrationDelayTimeoutSeconds: 1234,
+				targetGroupAttributes: map[string]string{
+					"slow_start.duration_seconds":   "30",
+					"load_balancing.algorithm.type": "weighted_random",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				props := template.Resources["TG"].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+				expected := cloudformation.ElasticLoadBalancingV2TargetGroupTargetGroupAttributeList{
+					{
+						Key:   cloudformation.String("deregistration_delay.timeout_seconds"),
+						Value: cloudformation.String("1234"),
+					},
+					{
+						Key:   cloudformation.String("load_balancing.algorithm.type"),
+						Value: cloudformation.String("weighted_random"),
+					},
+					{
+						Key:   cloudformation.String("slow_start.duration_seconds"),
+						Value: cloudformation.String("30"),
+					},
+				}
+				require.Equal(t, &expected, props.TargetGroupAttributes)
+			},
+		},
+		{
+			name: "minimum capacity is reserved",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				capacityUnits:    1500,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.Contains(t, template.Parameters, parameterCapacityUnitsParameter)
+
+				lb := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Equal(t, &cloudformation.ElasticLoadBalancingV2LoadBalancerMinimumLoadBalancerCapacity{
+					CapacityUnits: cloudformation.Ref(parameterCapacityUnitsParameter).Integer(),
+				}, lb.MinimumLoadBalancerCapacity)
+			},
+		},
+		{
+			name: "no minimum capacity without reservation",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				require.NotContains(t, template.Parameters, parameterCapacityUnitsParameter)
+
+				lb := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				require.Nil(t, lb.MinimumLoadBalancerCapacity)
+			},
+		},
+		{
+			name: "response headers are added to the HTTPS listener",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				responseHeaders: map[string]string{
+					"X-Frame-Options":           "DENY",
+					"Strict-Transport-Security": "max-age=31536000",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				httpListener := template.Resources["HTTPListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Nil(t, httpListener.ListenerAttributes)
+
+				httpsListener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				expected := cloudformation.ElasticLoadBalancingV2ListenerListenerAttributeList{
+					{
+						Key:   cloudformation.String("routing.http.response.strict_transport_security.header_value"),
+						Value: cloudformation.String("max-age=31536000"),
+					},
+					{
+						Key:   cloudformation.String("routing.http.response.x_frame_options.header_value"),
+						Value: cloudformation.String("DENY"),
+					},
+				}
+				require.Equal(t, &expected, httpsListener.ListenerAttributes)
+			},
+		},
+		{
+			name: "no listener attributes without response headers",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				httpsListener := template.Resources["HTTPSListener"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Nil(t, httpsListener.ListenerAttributes)
+			},
+		},
+		{
+			name: "load balancer attributes are added after the access logs",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeApplication,
+				loadBalancerAttributes: map[string]string{
+					"routing.http.desync_mitigation_mode":             "strictest",
+					"routing.http.drop_invalid_header_fields.enabled": "true",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				props := template.Resources["LB"].Properties.(*cloudformation.ElasticLoadBalancingV2LoadBalancer)
+				attributes := []cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute(*props.LoadBalancerAttributes)
+				require.Len(t, attributes, 5)
+				require.Equal(t, cloudformation.String("access_logs.s3.enabled"), attributes[2].Key)
+				require.Equal(t, cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute{
+					Key:   cloudformation.String("routing.http.desync_mitigation_mode"),
+					Value: cloudformation.String("strictest"),
+				}, attributes[3])
+				require.Equal(t, cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute{
+					Key:   cloudformation.String("routing.http.drop_invalid_header_fields.enabled"),
+					Value: cloudformation.String("true"),
+				}, attributes[4])
+			},
+		},
+		{
+			name: "NLB HTTP and HTTPS target groups have the same attributes",
+			spec: &stackSpec{
+				loadbalancerType:                  LoadBalancerTypeNetwork,
+				targetPort:                        9999,
+				httpTargetPort:                    8888,
+				deregistrationDelayTimeoutSeconds: 10,
+				targetGroupAttributes: map[string]string{
+					"proxy_protocol_v2.enabled":  "true",
+					"preserve_client_ip.enabled": "true",
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TGHTTP")
+
+				expected := cloudformation.ElasticLoadBalancingV2TargetGroupTargetGroupAttributeList{
+					{
+						Key:   cloudformation.String("deregistration_delay.timeout_seconds"),
+						Value: cloudformation.String("10"),
+					},
+					{
+						Key:   cloudformation.String("preserve_client_ip.enabled"),
+						Value: cloudformation.String("true"),
+					},
+					{
+						Key:   cloudformation.String("proxy_protocol_v2.enabled"),
+						Value: cloudformation.String("true"),
+					},
+				}
+				for _, name := range []string{"TG", "TGHTTP"} {
+					props := template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+					require.Equal(t, &expected, props.TargetGroupAttributes)
+				}
+			},
+		},
+		{
+			name: "NLB listeners with target ports have their own target groups",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+				targetPort:       9999,
+				httpTargetPort:   9999,
+				listeners: []Listener{
+					{Protocol: ListenerProtocolTLS, Port: 443},
+					{Protocol: ListenerProtocolTCP, Port: 5432, TargetPort: 30432},
+					{Protocol: ListenerProtocolTLS, Port: 8883, TargetPort: 31883},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TG5432", "TG8883")
+				requireListeners(t, template, "HTTPSListener", "Listener5432", "Listener8883")
+
+				validateTargetGroupListener(t, template, "TG", "HTTPSListener", 443, "TLS")
+				validateTargetGroupListener(t, template, "TG5432", "Listener5432", 5432, "TCP")
+				validateTargetGroupListener(t, template, "TG8883", "Listener8883", 8883, "TLS")
+				validateTargetGroupOutput(t, template, "TG5432", "ListenerTargetGroupARN5432")
+				validateTargetGroupOutput(t, template, "TG8883", "ListenerTargetGroupARN8883")
+
+				tg := template.Resources["TG5432"].Properties.(*cloudformation.ElasticLoadBalancingV2TargetGroup)
+				require.Equal(t, cloudformation.Integer(30432), tg.Port)
+				require.Equal(t, cloudformation.String("TCP"), tg.Protocol)
+				require.Equal(t, cloudformation.String("TCP"), tg.HealthCheckProtocol)
+				require.Equal(t, cloudformation.String("traffic-port"), tg.HealthCheckPort)
+				require.Nil(t, tg.HealthCheckPath)
+
+				passthrough := template.Resources["Listener5432"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Nil(t, passthrough.Certificates)
+				require.Nil(t, passthrough.SslPolicy)
+
+				terminated := template.Resources["Listener8883"].Properties.(*cloudformation.ElasticLoadBalancingV2Listener)
+				require.Equal(t, cloudformation.String("domain.company.com"), (*terminated.Certificates)[0].CertificateArn)
+				require.Equal(t, cloudformation.Ref(parameterListenerSslPolicyParameter).String(), terminated.SslPolicy)
+			},
+		},
+		{
+			name: "NLB TLS listeners with target ports require a certificate",
+			spec: &stackSpec{
+				loadbalancerType: LoadBalancerTypeNetwork,
+				listeners: []Listener{
+					{Protocol: ListenerProtocolTCP, Port: 5432, TargetPort: 30432},
+					{Protocol: ListenerProtocolTLS, Port: 8883, TargetPort: 31883},
+				},
+			},
+			validate: func(t *testing.T, template *cloudformation.Template) {
+				requireTargetGroups(t, template, "TG", "TG5432")
+				requireListeners(t, template, "Listener5432")
+			},
+		},
 		{
 			name: "Does not set healthcheck timeout on NLBs",
 			spec: &stackSpec{
@@ -726,6 +1506,73 @@ func TestGenerateTemplate(t *testing.T) {
 	}
 }
 
+func TestGenerateTemplateRemovedListener(t *testing.T) {
+	generate := func(ports ...int32) *cloudformation.Template {
+		spec := &stackSpec{
+			loadbalancerType: LoadBalancerTypeApplication,
+			certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+			httpDisabled:     true,
+		}
+		for _, port := range ports {
+			spec.listeners = append(spec.listeners, Listener{Protocol: ListenerProtocolHTTPS, Port: port})
+		}
+
+		generated, err := generateTemplate(spec)
+		require.NoError(t, err)
+
+		var template *cloudformation.Template
+		require.NoError(t, json.Unmarshal([]byte(generated), &template))
+		return template
+	}
+
+	// the listener on port 8443 must not be replaced when the listener on
+	// the default port is removed.
+	template := generate(443, 8443)
+	requireListeners(t, template, "HTTPSListener", "HTTPSListener8443")
+	validateTargetGroupListener(t, template, "TG", "HTTPSListener8443", 8443, "HTTPS")
+
+	template = generate(8443)
+	requireListeners(t, template, "HTTPSListener8443")
+	validateTargetGroupListener(t, template, "TG", "HTTPSListener8443", 8443, "HTTPS")
+}
+
+func TestGenerateTemplateGrowingFleet(t *testing.T) {
+	generate := func(canaryHosts int) *cloudformation.Template {
+		spec := &stackSpec{
+			loadbalancerType: LoadBalancerTypeApplication,
+			certificateARNs:  map[string]time.Time{"domain.company.com": time.Now()},
+			fleets: map[string][]string{
+				"canary": make([]string, canaryHosts),
+				"edge":   {"edge.org"},
+			},
+		}
+		for i := range canaryHosts {
+			spec.fleets["canary"][i] = fmt.Sprintf("canary-%d.org", i)
+		}
+
+		generated, err := generateTemplate(spec)
+		require.NoError(t, err)
+
+		var template *cloudformation.Template
+		require.NoError(t, json.Unmarshal([]byte(generated), &template))
+		return template
+	}
+
+	priority := func(template *cloudformation.Template, name string) *cloudformation.IntegerExpr {
+		require.Contains(t, template.Resources, name)
+		return template.Resources[name].Properties.(*cloudformation.ElasticLoadBalancingV2ListenerRule).Priority
+	}
+
+	// the rules of the second fleet keep their priorities when the first
+	// fleet needs more rules, so that they aren't updated.
+	template := generate(1)
+	edge := priority(template, "HTTPSListenerRuleFleetEdge1")
+
+	template = generate(6)
+	require.Contains(t, template.Resources, "HTTPSListenerRuleFleetCanary2")
+	require.Equal(t, edge, priority(template, "HTTPSListenerRuleFleetEdge1"))
+}
+
 func validateTargetGroupListener(t *testing.T, template *cloudformation.Template, targetGroup string, name string, port int64, protocol string) {
 	resource, ok := template.Resources[name]
 	require.True(t, ok, "Resource %s expected", name)
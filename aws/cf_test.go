@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -364,6 +365,67 @@ func TestTagConversion(t *testing.T) {
 
 }
 
+func TestStackTags(t *testing.T) {
+	spec := &stackSpec{
+		clusterID:    "test-cluster",
+		controllerID: DefaultControllerID,
+		ownerIngress: "foo/bar",
+		tags: map[string]string{
+			"team": "controller-team",
+		},
+		resourceTags: map[string]string{
+			"team":               "ingress-team",
+			"cost-center":        "1234",
+			"aws:reserved":       "foo",
+			ingressOwnerTag:      "baz/qux",
+			kubernetesCreatorTag: "foo",
+		},
+		settingsHash:     "settings-hash",
+		mtlsCABundleHash: "ca-bundle-hash",
+		strictHosts:      true,
+		hostnames:        []string{"foo.org"},
+		sourceRanges:     []string{"10.0.0.0/8", "192.168.0.0/16"},
+		nlbSecurityGroup: true,
+		fleets:           map[string][]string{"canary": {"foo.org"}},
+		fleetWeights:     map[string]int{"canary": 10},
+	}
+
+	got := convertCloudFormationTags(stackTags(spec))
+	want := map[string]string{
+		kubernetesCreatorTag:                DefaultControllerID,
+		clusterIDTagPrefix + "test-cluster": resourceLifecycleOwned,
+		ingressOwnerTag:                     "foo/bar",
+		"team":                              "controller-team",
+		"cost-center":                       "1234",
+		resourceTagsHashTag:                 ResourceTagsHash(spec.resourceTags),
+		settingsHashTag:                     "settings-hash",
+		caBundleHashTag:                     "ca-bundle-hash",
+		hostnamesHashTag:                    HostnamesHash([]string{"foo.org"}),
+		sourceRangesHashTag:                 SourceRangesHash("10.0.0.0/8,192.168.0.0/16"),
+		nlbSecurityGroupTag:                 "true",
+		fleetsHashTag:                       FleetsHash(map[string][]string{"canary": {"foo.org"}}),
+		fleetWeightsHashTag:                 FleetWeightsHash("canary=10"),
+	}
+	assert.Equal(t, want, got)
+	assert.Len(t, stackTags(spec), len(want))
+}
+
+func TestValidateTag(t *testing.T) {
+	assert.NoError(t, ValidateTag("team", "foo bar"))
+	assert.NoError(t, ValidateTag("example.org/team", "ü"+strings.Repeat("a", MaxTagValueLength-1)))
+	assert.Error(t, ValidateTag("", "foo"))
+	assert.Error(t, ValidateTag(strings.Repeat("a", MaxTagKeyLength+1), "foo"))
+	assert.Error(t, ValidateTag("team", strings.Repeat("a", MaxTagValueLength+1)))
+	assert.Error(t, ValidateTag("team", "foo,bar"))
+	assert.Error(t, ValidateTag("team;", "foo"))
+}
+
+func TestResourceTagsHash(t *testing.T) {
+	assert.Equal(t, "", ResourceTagsHash(nil))
+	assert.Equal(t, ResourceTagsHash(map[string]string{"a": "1", "b": "2"}), ResourceTagsHash(map[string]string{"b": "2", "a": "1"}))
+	assert.NotEqual(t, ResourceTagsHash(map[string]string{"a": "1"}), ResourceTagsHash(map[string]string{"a": "2"}))
+}
+
 func TestConvertStackParameters(t *testing.T) {
 	for _, ti := range []struct {
 		name  string
@@ -389,6 +451,29 @@ func TestConvertStackParameters(t *testing.T) {
 
 }
 
+func TestSettingsHash(t *testing.T) {
+	assert.Equal(t, "", SettingsHash(&StackSettings{}))
+	assert.Equal(t, "", SettingsHash(&StackSettings{Scheme: "internal"}))
+	assert.NotEqual(t, "", SettingsHash(&StackSettings{AccessLogsDisabled: true}))
+	assert.Equal(t,
+		SettingsHash(&StackSettings{AccessLogsS3Bucket: "bucket", AccessLogsS3Prefix: "prefix"}),
+		SettingsHash(&StackSettings{AccessLogsS3Prefix: "prefix", AccessLogsS3Bucket: "bucket"}),
+	)
+	assert.NotEqual(t,
+		SettingsHash(&StackSettings{AccessLogsS3Bucket: "prefix"}),
+		SettingsHash(&StackSettings{AccessLogsS3Prefix: "prefix"}),
+	)
+}
+
+func TestMapToManagedStackSettingsHash(t *testing.T) {
+	stack := mapToManagedStack(&types.Stack{
+		StackName:   aws.String("stack"),
+		StackStatus: types.StackStatusCreateInProgress,
+		Tags:        []types.Tag{cfTag(settingsHashTag, "settings-hash")},
+	})
+	assert.Equal(t, "settings-hash", stack.SettingsHash)
+}
+
 func TestFindManagedStacks(t *testing.T) {
 	for _, ti := range []struct {
 		name    string
@@ -702,6 +787,42 @@ func TestGetStack(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "successful-call-listener-arns",
+			given: fake.CFOutputs{
+				DescribeStacks: fake.R(&cloudformation.DescribeStacksOutput{
+					Stacks: []types.Stack{
+						{
+							StackName:   aws.String("managed-stack"),
+							StackStatus: types.StackStatusCreateComplete,
+							Tags: []types.Tag{
+								cfTag(kubernetesCreatorTag, DefaultControllerID),
+								cfTag(clusterIDTagPrefix+"test-cluster", resourceLifecycleOwned),
+							},
+							Outputs: []types.Output{
+								{OutputKey: aws.String(outputLoadBalancerDNSName), OutputValue: aws.String("example.com")},
+								{OutputKey: aws.String(outputListenerTargetGroupARNPrefix + "8883"), OutputValue: aws.String("tg-8883-arn")},
+								{OutputKey: aws.String(outputTargetGroupARN), OutputValue: aws.String("tg-arn")},
+								{OutputKey: aws.String(outputListenerTargetGroupARNPrefix + "5432"), OutputValue: aws.String("tg-5432-arn")},
+							},
+						},
+					},
+				}, nil),
+			},
+			want: &Stack{
+				Name:            "managed-stack",
+				DNSName:         "example.com",
+				CertificateARNs: map[string]time.Time{},
+				TargetGroupARNs: []string{"tg-arn", "tg-5432-arn", "tg-8883-arn"},
+				tags: map[string]string{
+					kubernetesCreatorTag:                DefaultControllerID,
+					clusterIDTagPrefix + "test-cluster": resourceLifecycleOwned,
+				},
+				status: types.StackStatusCreateComplete,
+				HTTP2:  true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "successful-call-http-arn",
 			given: fake.CFOutputs{
@@ -65,8 +65,10 @@ func (id *instanceDetails) clusterID() string {
 type subnetDetails struct {
 	id               string
 	availabilityZone string
+	cidrBlock        string
 	tags             map[string]string
 	public           bool
+	ipv6             bool
 }
 
 func (sd *subnetDetails) String() string {
@@ -198,17 +200,22 @@ func getSubnets(ctx context.Context, svc EC2API, vpcID, clusterID string) ([]*su
 			return nil, err
 		}
 		tags := convertEc2Tags(sn.Tags)
+		ipv6 := hasIPv6CidrBlock(sn)
 		retAll[i] = &subnetDetails{
 			id:               subnetID,
 			availabilityZone: az,
+			cidrBlock:        aws.ToString(sn.CidrBlock),
 			public:           isPublic,
+			ipv6:             ipv6,
 			tags:             tags,
 		}
 		if _, ok := tags[clusterIDTagPrefix+clusterID]; ok {
 			retFiltered = append(retFiltered, &subnetDetails{
 				id:               subnetID,
 				availabilityZone: az,
+				cidrBlock:        aws.ToString(sn.CidrBlock),
 				public:           isPublic,
+				ipv6:             ipv6,
 				tags:             tags,
 			})
 		}
@@ -222,6 +229,17 @@ func getSubnets(ctx context.Context, svc EC2API, vpcID, clusterID string) ([]*su
 	return retFiltered, nil
 }
 
+// hasIPv6CidrBlock returns true if an IPv6 CIDR block is associated with the
+// subnet.
+func hasIPv6CidrBlock(subnet types.Subnet) bool {
+	for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+		if association.Ipv6CidrBlockState != nil && association.Ipv6CidrBlockState.State == types.SubnetCidrBlockStateCodeAssociated {
+			return true
+		}
+	}
+	return false
+}
+
 func convertEc2Tags(instanceTags []types.Tag) map[string]string {
 	tags := make(map[string]string, len(instanceTags))
 	for _, tagDescription := range instanceTags {
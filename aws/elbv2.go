@@ -3,6 +3,8 @@ package aws
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -56,12 +58,27 @@ func (ls *LoadBalancerState) Age() time.Duration {
 	return time.Since(ls.createdTime)
 }
 
+// newTargetDescription returns the description of the target, which is an
+// instance ID or IP address registered on the port of the target group, or
+// an IP address with the port to register it on, like 10.2.3.4:9999.
+func newTargetDescription(target string) elbv2Types.TargetDescription {
+	if host, port, err := net.SplitHostPort(target); err == nil {
+		if p, err := strconv.ParseInt(port, 10, 32); err == nil {
+			return elbv2Types.TargetDescription{
+				Id:   aws.String(host),
+				Port: aws.Int32(int32(p)),
+			}
+		}
+	}
+	return elbv2Types.TargetDescription{
+		Id: aws.String(target),
+	}
+}
+
 func registerTargetsOnTargetGroups(ctx context.Context, svc ELBV2API, targetGroupARNs []string, instances []string) error {
 	targets := make([]elbv2Types.TargetDescription, len(instances))
 	for i, instance := range instances {
-		targets[i] = elbv2Types.TargetDescription{
-			Id: aws.String(instance),
-		}
+		targets[i] = newTargetDescription(instance)
 	}
 
 	for _, targetGroupARN := range targetGroupARNs {
@@ -81,9 +98,7 @@ func registerTargetsOnTargetGroups(ctx context.Context, svc ELBV2API, targetGrou
 func deregisterTargetsOnTargetGroups(ctx context.Context, svc ELBV2API, targetGroupARNs []string, instances []string) error {
 	targets := make([]elbv2Types.TargetDescription, len(instances))
 	for i, instance := range instances {
-		targets[i] = elbv2Types.TargetDescription{
-			Id: aws.String(instance),
-		}
+		targets[i] = newTargetDescription(instance)
 	}
 
 	for _, targetGroupARN := range targetGroupARNs {
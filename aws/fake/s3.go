@@ -0,0 +1,37 @@
+package fake
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type S3Client struct {
+	// Objects are the uploaded objects by bucket and key
+	Objects map[string]map[string]string
+	Err     error
+}
+
+func (m *S3Client) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	body, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Objects == nil {
+		m.Objects = make(map[string]map[string]string)
+	}
+	bucket := aws.ToString(in.Bucket)
+	if m.Objects[bucket] == nil {
+		m.Objects[bucket] = make(map[string]string)
+	}
+	m.Objects[bucket][aws.ToString(in.Key)] = string(body)
+
+	return &s3.PutObjectOutput{}, nil
+}
@@ -0,0 +1,257 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	// outputFleetTargetGroupARNPrefix is followed by the resource suffix
+	// of the fleet of the target group, see fleetResourceSuffix.
+	outputFleetTargetGroupARNPrefix = "FleetTargetGroupARN"
+	// maxFleetWeight is the total weight of the target groups of the
+	// weighted forward actions, so that fleet weights are percentages.
+	maxFleetWeight = 100
+	// fleetRulePriorityOffset is the priority of the first rule forwarding
+	// the hosts of a fleet. The rules follow the forward rules, whose
+	// number is limited by MaxListenerRules.
+	fleetRulePriorityOffset = forwardRulePriorityOffset + MaxListenerRules
+	// maxFleetChunks is the size of the block of priorities of the rules of
+	// each fleet, so that the number of rules of one fleet doesn't change
+	// the priorities of the rules of the other fleets.
+	maxFleetChunks = MaxListenerRules
+)
+
+var fleetNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]{0,31}$`)
+
+// ParseFleets parses fleet definitions of the form <name>=<selector> and
+// returns the selectors by fleet name. Names consist of up to 32 lower case
+// letters and digits, starting with a letter. The selector is an Auto
+// Scaling Group tag filter in the format of the custom filter, see
+// ParseFilterTags, or a pod label selector in the AWSCNI target access mode.
+func ParseFleets(values []string) (map[string]string, error) {
+	fleets := make(map[string]string, len(values))
+	for _, value := range values {
+		name, selector, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(selector) == "" {
+			return nil, fmt.Errorf("invalid fleet %q, must be <name>=<selector>", value)
+		}
+		if !fleetNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid fleet name %q, must match %s", name, fleetNameRegexp)
+		}
+		if _, ok := fleets[name]; ok {
+			return nil, fmt.Errorf("duplicate fleet %q", name)
+		}
+		fleets[name] = strings.TrimSpace(selector)
+	}
+	return fleets, nil
+}
+
+// FleetsHash returns the hash identifying the hostnames routed to the
+// fleets of a load balancer.
+func FleetsHash(fleets map[string][]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(fleets)) {
+		fmt.Fprintf(&b, "%s=%s;", name, strings.Join(normalizeHostnames(fleets[name]), ","))
+	}
+	hash := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+// ParseFleetWeights parses a list of <fleet>=<weight> pairs separated by
+// commas or whitespace. The weight is the percentage of the requests not
+// matching any host rule which is forwarded to the fleet, the remaining
+// requests are forwarded to the default targets. An empty value results in
+// no weights.
+func ParseFleetWeights(value string) (map[string]int, error) {
+	var weights map[string]int
+	total := 0
+	for _, term := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		name, w, ok := strings.Cut(term, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fleet weight %q, must be <fleet>=<weight>", term)
+		}
+		if !fleetNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid fleet name %q, must match %s", name, fleetNameRegexp)
+		}
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight < 0 || weight > maxFleetWeight {
+			return nil, fmt.Errorf("invalid weight %q of fleet %s, must be between 0 and %d", w, name, maxFleetWeight)
+		}
+		if _, ok := weights[name]; ok {
+			return nil, fmt.Errorf("duplicate fleet %q", name)
+		}
+		if weights == nil {
+			weights = make(map[string]int)
+		}
+		weights[name] = weight
+		total += weight
+	}
+	if total > maxFleetWeight {
+		return nil, fmt.Errorf("total fleet weight %d exceeds %d", total, maxFleetWeight)
+	}
+	return weights, nil
+}
+
+// FormatFleetWeights returns the canonical representation of the fleet
+// weights which can be used to compare them. It returns an empty string if
+// there are no weights.
+func FormatFleetWeights(weights map[string]int) string {
+	terms := make([]string, 0, len(weights))
+	for _, name := range slices.Sorted(maps.Keys(weights)) {
+		terms = append(terms, fmt.Sprintf("%s=%d", name, weights[name]))
+	}
+	return strings.Join(terms, ",")
+}
+
+// FleetWeightsHash returns the hash identifying the canonical fleet weights of
+// a load balancer and an empty string if there are none.
+func FleetWeightsHash(fleetWeights string) string {
+	if fleetWeights == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(fleetWeights))
+	return hex.EncodeToString(hash[:])
+}
+
+// fleetResourceSuffix returns the suffix of the names of the resources and
+// outputs of a fleet. Fleet names are lower case, so the capitalized name is
+// reversible.
+func fleetResourceSuffix(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func fleetTargetGroupName(name string) string {
+	return "TGFleet" + fleetResourceSuffix(name)
+}
+
+// fleetTargetGroupARNs returns the ARNs of the target groups of the fleets
+// by fleet name.
+func (o stackOutput) fleetTargetGroupARNs() map[string]string {
+	var arns map[string]string
+	for key, arn := range o {
+		if suffix, ok := strings.CutPrefix(key, outputFleetTargetGroupARNPrefix); ok && suffix != "" {
+			if arns == nil {
+				arns = make(map[string]string)
+			}
+			arns[strings.ToLower(suffix)] = arn
+		}
+	}
+	return arns
+}
+
+// fleetRules returns the number of rules forwarding the hosts of the fleets
+// per listener.
+func (spec *stackSpec) fleetRules() int {
+	n := 0
+	for _, hosts := range spec.fleets {
+		n += chunks(len(hosts), maxRuleConditionValues)
+	}
+	return n
+}
+
+// fleetNames returns the sorted names of the fleets serving hosts of the load
+// balancer or getting a share of its requests.
+func (spec *stackSpec) fleetNames() []string {
+	names := slices.Collect(maps.Keys(spec.fleets))
+	for name := range spec.fleetWeights {
+		if _, ok := spec.fleets[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// addFleetTargetGroups adds a target group on the target port and its output
+// for every fleet of the load balancer.
+func addFleetTargetGroups(template *cloudformation.Template, spec *stackSpec) {
+	for _, name := range spec.fleetNames() {
+		targetGroupName := fleetTargetGroupName(name)
+		template.AddResource(targetGroupName, newTargetGroup(spec, parameterTargetGroupTargetPortParameter))
+		template.Outputs[outputFleetTargetGroupARNPrefix+fleetResourceSuffix(name)] = &cloudformation.Output{
+			Description: fmt.Sprintf("The ARN of the TargetGroup of the fleet %s", name),
+			Value:       cloudformation.Ref(targetGroupName).String(),
+		}
+	}
+}
+
+// addFleetRules adds rules forwarding the requests for the hosts of every
+// fleet to the target group of the fleet. The rules authenticate users first
+// if authenticate is set. In maintenance mode the listener doesn't forward
+// any requests.
+func addFleetRules(template *cloudformation.Template, spec *stackSpec, listenerName string, authenticate bool) {
+	if spec.loadbalancerType != LoadBalancerTypeApplication || spec.maintenance {
+		return
+	}
+
+	for i, name := range slices.Sorted(maps.Keys(spec.fleets)) {
+		j := 0
+		for hosts := range slices.Chunk(spec.fleets[name], maxRuleConditionValues) {
+			actions := cloudformation.ElasticLoadBalancingV2ListenerRuleActionList{
+				{
+					Type:           cloudformation.String("forward"),
+					TargetGroupArn: cloudformation.Ref(fleetTargetGroupName(name)).String(),
+				},
+			}
+			if authenticate {
+				actions[0].Order = cloudformation.Integer(2)
+				actions = append(cloudformation.ElasticLoadBalancingV2ListenerRuleActionList{spec.auth.authenticateRuleAction()}, actions...)
+			}
+
+			template.AddResource(fmt.Sprintf("%sRuleFleet%s%d", listenerName, fleetResourceSuffix(name), j+1), &cloudformation.ElasticLoadBalancingV2ListenerRule{
+				Conditions: &cloudformation.ElasticLoadBalancingV2ListenerRuleRuleConditionList{
+					ruleCondition(listenerRuleConditionHostField, hosts),
+				},
+				Actions:     &actions,
+				Priority:    cloudformation.Integer(fleetRulePriorityOffset + int64(i*maxFleetChunks+j)),
+				ListenerArn: cloudformation.Ref(listenerName).String(),
+			})
+			j++
+		}
+	}
+}
+
+// setFleetWeights replaces the target group of the forward default action of
+// the listener by the weighted target groups of the default targets and the
+// fleets. Changing the weights only updates the listener.
+func setFleetWeights(listener *cloudformation.ElasticLoadBalancingV2Listener, spec *stackSpec, targetGroupName string) {
+	if len(spec.fleetWeights) == 0 || listener.DefaultActions == nil {
+		return
+	}
+
+	for i, action := range *listener.DefaultActions {
+		if action.Type == nil || action.Type.Literal != "forward" {
+			continue
+		}
+
+		weight := maxFleetWeight
+		targetGroups := cloudformation.ElasticLoadBalancingV2ListenerTargetGroupTupleList{{
+			TargetGroupArn: cloudformation.Ref(targetGroupName).String(),
+		}}
+		for _, name := range slices.Sorted(maps.Keys(spec.fleetWeights)) {
+			targetGroups = append(targetGroups, cloudformation.ElasticLoadBalancingV2ListenerTargetGroupTuple{
+				TargetGroupArn: cloudformation.Ref(fleetTargetGroupName(name)).String(),
+				Weight:         cloudformation.Integer(int64(spec.fleetWeights[name])),
+			})
+			weight -= spec.fleetWeights[name]
+		}
+		targetGroups[0].Weight = cloudformation.Integer(int64(weight))
+
+		(*listener.DefaultActions)[i].TargetGroupArn = nil
+		(*listener.DefaultActions)[i].ForwardConfig = &cloudformation.ElasticLoadBalancingV2ListenerForwardConfig{
+			TargetGroups: &targetGroups,
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws/fake"
+)
+
+func TestParseFleets(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		values []string
+		want   map[string]string
+		err    bool
+	}{
+		{
+			name:   "no fleets",
+			values: nil,
+			want:   map[string]string{},
+		},
+		{
+			name:   "fleets",
+			values: []string{"canary=tag:fleet=canary", "edge2=application=skipper-edge, component=ingress"},
+			want: map[string]string{
+				"canary": "tag:fleet=canary",
+				"edge2":  "application=skipper-edge, component=ingress",
+			},
+		},
+		{
+			name:   "missing selector",
+			values: []string{"canary="},
+			err:    true,
+		},
+		{
+			name:   "missing separator",
+			values: []string{"canary"},
+			err:    true,
+		},
+		{
+			name:   "invalid name",
+			values: []string{"Canary=tag:fleet=canary"},
+			err:    true,
+		},
+		{
+			name:   "duplicate name",
+			values: []string{"canary=tag:fleet=canary", "canary=tag:fleet=other"},
+			err:    true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseFleets(test.values)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestFleetsHash(t *testing.T) {
+	hash := FleetsHash(map[string][]string{"canary": {"b.org", "A.org"}, "edge": {"c.org"}})
+	assert.Equal(t, hash, FleetsHash(map[string][]string{"edge": {"c.org"}, "canary": {"a.org", "b.org", "b.org"}}))
+	assert.NotEqual(t, hash, FleetsHash(map[string][]string{"canary": {"a.org", "b.org", "c.org"}}))
+	assert.NotEqual(t, hash, FleetsHash(map[string][]string{"canary": {"a.org", "b.org"}, "other": {"c.org"}}))
+}
+
+func TestParseFleetWeights(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		want  map[string]int
+		err   bool
+	}{
+		{
+			name:  "empty",
+			value: " ",
+		},
+		{
+			name:  "weights",
+			value: "edge=5, canary=10\nzero=0",
+			want:  map[string]int{"canary": 10, "edge": 5, "zero": 0},
+		},
+		{
+			name:  "all requests",
+			value: "canary=100",
+			want:  map[string]int{"canary": 100},
+		},
+		{
+			name:  "missing weight",
+			value: "canary",
+			err:   true,
+		},
+		{
+			name:  "invalid weight",
+			value: "canary=ten",
+			err:   true,
+		},
+		{
+			name:  "negative weight",
+			value: "canary=-1",
+			err:   true,
+		},
+		{
+			name:  "invalid name",
+			value: "Canary=10",
+			err:   true,
+		},
+		{
+			name:  "duplicate fleet",
+			value: "canary=10,canary=20",
+			err:   true,
+		},
+		{
+			name:  "total exceeds maximum",
+			value: "canary=60,edge=50",
+			err:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseFleetWeights(test.value)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatFleetWeights(t *testing.T) {
+	assert.Equal(t, "", FormatFleetWeights(nil))
+	assert.Equal(t, "canary=10,edge=0", FormatFleetWeights(map[string]int{"edge": 0, "canary": 10}))
+}
+
+func TestFleetWeightsHash(t *testing.T) {
+	assert.Empty(t, FleetWeightsHash(""))
+	assert.NotEqual(t, FleetWeightsHash("canary=10"), FleetWeightsHash("canary=50"))
+}
+
+func TestStackOutputFleetTargetGroupARNs(t *testing.T) {
+	outputs := stackOutput{
+		outputTargetGroupARN:                        "arn:tg",
+		outputFleetTargetGroupARNPrefix + "Canary":  "arn:canary",
+		outputFleetTargetGroupARNPrefix + "Edge2":   "arn:edge2",
+		outputListenerTargetGroupARNPrefix + "8443": "arn:8443",
+	}
+	assert.Equal(t, map[string]string{"canary": "arn:canary", "edge2": "arn:edge2"}, outputs.fleetTargetGroupARNs())
+	assert.Equal(t, []string{"arn:tg", "arn:8443"}, outputs.targetGroupARNs())
+	assert.Nil(t, stackOutput{outputTargetGroupARN: "arn:tg"}.fleetTargetGroupARNs())
+}
+
+func TestGetFleetAutoScalingGroups(t *testing.T) {
+	svc := &fake.ASGClient{Outputs: fake.ASGOutputs{
+		DescribeAutoScalingGroups: fake.R(fake.MockDescribeAutoScalingGroupOutput(map[string]fake.ASGtags{
+			"default": {"cluster": "owned"},
+			"canary":  {"cluster": "owned", "fleet": "canary"},
+		}), nil),
+	}}
+
+	got, err := getFleetAutoScalingGroups(context.Background(), svc, map[string]map[string][]string{
+		"canary": {"fleet": {"canary"}},
+		"edge":   {"fleet": {"edge"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*autoScalingGroupDetails{
+		"canary": {"canary": mockAutoScalingGroupDetails("canary", map[string]string{"cluster": "owned", "fleet": "canary"})},
+		"edge":   {},
+	}, got)
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+
+	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+// IPAddressTypes are the IP address types of the load balancers, see
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/application-load-balancers.html#ip-address-type
+var IPAddressTypes = []string{
+	IPAddressTypeIPV4,
+	IPAddressTypeDualstack,
+	IPAddressTypeDualstackWithoutPublicIPv4,
+}
+
+// ValidateIPAddressType returns an error if the IP address type is unknown or
+// not supported by the type and scheme of the load balancer.
+// dualstack-without-public-ipv4 is only supported by internet-facing
+// application load balancers.
+func ValidateIPAddressType(ipAddressType, loadBalancerType, scheme string) error {
+	if !slices.Contains(IPAddressTypes, ipAddressType) {
+		return fmt.Errorf("invalid IP address type %q, must be one of %v", ipAddressType, IPAddressTypes)
+	}
+
+	if ipAddressType == IPAddressTypeDualstackWithoutPublicIPv4 {
+		if loadBalancerType != LoadBalancerTypeApplication {
+			return fmt.Errorf("IP address type %s is only supported by %s load balancers", ipAddressType, LoadBalancerTypeApplication)
+		}
+		if scheme != string(elbv2Types.LoadBalancerSchemeEnumInternetFacing) {
+			return fmt.Errorf("IP address type %s is only supported by %s load balancers", ipAddressType, elbv2Types.LoadBalancerSchemeEnumInternetFacing)
+		}
+	}
+	return nil
+}
+
+// IPAddressTypeChangeable returns true if the IP address type of an existing
+// load balancer can be changed by a stack update without replacing the load
+// balancer. Application load balancers are switched to and from
+// dualstack-without-public-ipv4 in place, other changes require a new load
+// balancer.
+func IPAddressTypeChangeable(loadBalancerType, from, to string) bool {
+	return loadBalancerType == LoadBalancerTypeApplication &&
+		(from == IPAddressTypeDualstackWithoutPublicIPv4 || to == IPAddressTypeDualstackWithoutPublicIPv4)
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIPAddressType(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		ipAddressType    string
+		loadBalancerType string
+		scheme           string
+		err              bool
+	}{
+		{
+			name:             "ipv4",
+			ipAddressType:    IPAddressTypeIPV4,
+			loadBalancerType: LoadBalancerTypeNetwork,
+			scheme:           "internal",
+		},
+		{
+			name:             "dualstack",
+			ipAddressType:    IPAddressTypeDualstack,
+			loadBalancerType: LoadBalancerTypeApplication,
+			scheme:           "internal",
+		},
+		{
+			name:             "dualstack without public IPv4",
+			ipAddressType:    IPAddressTypeDualstackWithoutPublicIPv4,
+			loadBalancerType: LoadBalancerTypeApplication,
+			scheme:           "internet-facing",
+		},
+		{
+			name:             "dualstack without public IPv4 on network load balancer",
+			ipAddressType:    IPAddressTypeDualstackWithoutPublicIPv4,
+			loadBalancerType: LoadBalancerTypeNetwork,
+			scheme:           "internet-facing",
+			err:              true,
+		},
+		{
+			name:             "dualstack without public IPv4 on internal load balancer",
+			ipAddressType:    IPAddressTypeDualstackWithoutPublicIPv4,
+			loadBalancerType: LoadBalancerTypeApplication,
+			scheme:           "internal",
+			err:              true,
+		},
+		{
+			name:             "unknown",
+			ipAddressType:    "ipv6",
+			loadBalancerType: LoadBalancerTypeApplication,
+			scheme:           "internet-facing",
+			err:              true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateIPAddressType(test.ipAddressType, test.loadBalancerType, test.scheme)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIPAddressTypeChangeable(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		loadBalancerType string
+		from, to         string
+		want             bool
+	}{
+		{
+			name:             "to dualstack without public IPv4",
+			loadBalancerType: LoadBalancerTypeApplication,
+			from:             IPAddressTypeIPV4,
+			to:               IPAddressTypeDualstackWithoutPublicIPv4,
+			want:             true,
+		},
+		{
+			name:             "from dualstack without public IPv4",
+			loadBalancerType: LoadBalancerTypeApplication,
+			from:             IPAddressTypeDualstackWithoutPublicIPv4,
+			to:               IPAddressTypeDualstack,
+			want:             true,
+		},
+		{
+			name:             "ipv4 to dualstack",
+			loadBalancerType: LoadBalancerTypeApplication,
+			from:             IPAddressTypeIPV4,
+			to:               IPAddressTypeDualstack,
+		},
+		{
+			name:             "network load balancer",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			from:             IPAddressTypeDualstack,
+			to:               IPAddressTypeDualstackWithoutPublicIPv4,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, IPAddressTypeChangeable(test.loadBalancerType, test.from, test.to))
+		})
+	}
+}
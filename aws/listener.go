@@ -0,0 +1,206 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ListenerProtocolHTTP  = "HTTP"
+	ListenerProtocolHTTPS = "HTTPS"
+	ListenerProtocolTCP   = "TCP"
+	ListenerProtocolTLS   = "TLS"
+
+	defaultHTTPListenerPort  = 80
+	defaultHTTPSListenerPort = 443
+
+	// HTTPListenerModeRedirect redirects HTTP requests to HTTPS
+	HTTPListenerModeRedirect = "redirect"
+	// HTTPListenerModeForward forwards HTTP requests to the targets
+	HTTPListenerModeForward = "forward"
+	// HTTPListenerModeDisabled removes the HTTP listener
+	HTTPListenerModeDisabled = "disabled"
+)
+
+// HTTPListenerModes are the valid modes of the HTTP listener of application
+// load balancers.
+var HTTPListenerModes = []string{
+	HTTPListenerModeRedirect,
+	HTTPListenerModeForward,
+	HTTPListenerModeDisabled,
+}
+
+// listenerProtocols are the listener protocols supported per load balancer
+// type.
+var listenerProtocols = map[string][]string{
+	LoadBalancerTypeApplication: {ListenerProtocolHTTP, ListenerProtocolHTTPS},
+	LoadBalancerTypeNetwork:     {ListenerProtocolTCP, ListenerProtocolTLS},
+}
+
+// Listener is a listener of a load balancer. Secure listeners (HTTPS and TLS)
+// terminate TLS with the certificates of the load balancer and forward to the
+// HTTPS target group, the others forward to the HTTP target group.
+//
+// Listeners of network load balancers with a TargetPort forward to their own
+// target group on that port instead, e.g. for databases or MQTT brokers. TCP
+// listeners pass the connections through, TLS listeners terminate TLS.
+type Listener struct {
+	Protocol   string
+	Port       int32
+	TargetPort int32
+}
+
+func (l Listener) String() string {
+	if l.dedicated() {
+		return fmt.Sprintf("%s:%d:%d", l.Protocol, l.Port, l.TargetPort)
+	}
+	return fmt.Sprintf("%s:%d", l.Protocol, l.Port)
+}
+
+// dedicated returns true if the listener forwards to its own target group.
+func (l Listener) dedicated() bool {
+	return l.TargetPort > 0
+}
+
+// targetGroupName returns the name of the target group resource of a
+// dedicated listener.
+func (l Listener) targetGroupName() string {
+	return fmt.Sprintf("TG%d", l.Port)
+}
+
+// resourceName returns the name of the listener resource of a dedicated
+// listener.
+func (l Listener) resourceName() string {
+	return fmt.Sprintf("Listener%d", l.Port)
+}
+
+// resourceSuffix returns the suffix of the names of the listener resources.
+// The listener on the default port keeps the names used before listeners
+// became configurable, the names of the others only depend on the port so
+// that they don't change when other listeners are added or removed.
+func (l Listener) resourceSuffix(defaultPort int32) string {
+	if l.Port == defaultPort {
+		return ""
+	}
+	return fmt.Sprintf("%d", l.Port)
+}
+
+func (l Listener) secure() bool {
+	return l.Protocol == ListenerProtocolHTTPS || l.Protocol == ListenerProtocolTLS
+}
+
+// ParseListeners parses a comma separated list of listeners in the form
+// protocol:port, e.g. HTTP:80,HTTPS:443,HTTPS:8443, for a load balancer of the
+// given type. Listeners of network load balancers can have their own target
+// port in the form protocol:port:targetPort, e.g. TCP:5432:30432. The
+// listeners are returned ordered by port. An empty string results in no
+// listeners.
+func ParseListeners(value string, loadBalancerType string) ([]Listener, error) {
+	protocols, ok := listenerProtocols[loadBalancerType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported load balancer type %q", loadBalancerType)
+	}
+
+	var listeners []Listener
+	for _, term := range strings.Split(value, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		parts := strings.Split(term, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid listener %q, expected protocol:port or protocol:port:targetPort", term)
+		}
+		protocol, port := parts[0], parts[1]
+
+		protocol = strings.ToUpper(strings.TrimSpace(protocol))
+		if !slices.Contains(protocols, protocol) {
+			return nil, fmt.Errorf("listener protocol %q is not supported by %s load balancers, supported are %v", protocol, loadBalancerType, protocols)
+		}
+
+		p, err := strconv.ParseInt(strings.TrimSpace(port), 10, 32)
+		if err != nil || p < 1 || p > 65535 {
+			return nil, fmt.Errorf("invalid listener port in %q", term)
+		}
+
+		if slices.ContainsFunc(listeners, func(l Listener) bool { return l.Port == int32(p) }) {
+			return nil, fmt.Errorf("duplicate listener port %d", p)
+		}
+
+		listener := Listener{Protocol: protocol, Port: int32(p)}
+		if len(parts) == 3 {
+			if loadBalancerType != LoadBalancerTypeNetwork {
+				return nil, fmt.Errorf("listener target ports are only supported by %s load balancers", LoadBalancerTypeNetwork)
+			}
+			tp, err := strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 32)
+			if err != nil || tp < 1 || tp > 65535 {
+				return nil, fmt.Errorf("invalid listener target port in %q", term)
+			}
+			listener.TargetPort = int32(tp)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].Port < listeners[j].Port
+	})
+
+	return listeners, nil
+}
+
+// FormatListeners returns the canonical representation of the listeners
+// which can be used to compare them.
+func FormatListeners(listeners []Listener) string {
+	terms := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		terms = append(terms, l.String())
+	}
+	return strings.Join(terms, ",")
+}
+
+// defaultListeners returns the listeners of a load balancer without listener
+// override: HTTP on port 80 unless disabled and HTTPS on port 443.
+func defaultListeners(loadBalancerType string, httpDisabled bool) []Listener {
+	httpProtocol, httpsProtocol := ListenerProtocolHTTP, ListenerProtocolHTTPS
+	if loadBalancerType == LoadBalancerTypeNetwork {
+		httpProtocol, httpsProtocol = ListenerProtocolTCP, ListenerProtocolTLS
+	}
+
+	var listeners []Listener
+	if !httpDisabled {
+		listeners = append(listeners, Listener{Protocol: httpProtocol, Port: defaultHTTPListenerPort})
+	}
+	return append(listeners, Listener{Protocol: httpsProtocol, Port: defaultHTTPSListenerPort})
+}
+
+// listenersOrDefault returns the listeners configured for the stack or the
+// default listeners if none are configured.
+func (spec *stackSpec) listenersOrDefault() []Listener {
+	if len(spec.listeners) > 0 {
+		return spec.listeners
+	}
+	return defaultListeners(spec.loadbalancerType, spec.httpDisabled)
+}
+
+// hasInsecureListener returns true if any of the listeners forwards to the
+// HTTP target group.
+func hasInsecureListener(listeners []Listener) bool {
+	return slices.ContainsFunc(listeners, func(l Listener) bool { return !l.secure() && !l.dedicated() })
+}
+
+// dedicatedListeners returns the listeners forwarding to their own target
+// group.
+func dedicatedListeners(listeners []Listener) []Listener {
+	var dedicated []Listener
+	for _, l := range listeners {
+		if l.dedicated() {
+			dedicated = append(dedicated, l)
+		}
+	}
+	return dedicated
+}
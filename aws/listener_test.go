@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"testing"
+
+	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListeners(tt *testing.T) {
+	for _, test := range []struct {
+		name             string
+		value            string
+		loadBalancerType string
+		expected         []Listener
+		canonical        string
+		err              bool
+	}{
+		{
+			name:             "empty",
+			loadBalancerType: LoadBalancerTypeApplication,
+		},
+		{
+			name:             "listeners are sorted by port",
+			value:            "https:8443, HTTP:80,HTTPS:443",
+			loadBalancerType: LoadBalancerTypeApplication,
+			expected: []Listener{
+				{Protocol: ListenerProtocolHTTP, Port: 80},
+				{Protocol: ListenerProtocolHTTPS, Port: 443},
+				{Protocol: ListenerProtocolHTTPS, Port: 8443},
+			},
+			canonical: "HTTP:80,HTTPS:443,HTTPS:8443",
+		},
+		{
+			name:             "network load balancer",
+			value:            "TLS:443,TLS:8443",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			expected: []Listener{
+				{Protocol: ListenerProtocolTLS, Port: 443},
+				{Protocol: ListenerProtocolTLS, Port: 8443},
+			},
+			canonical: "TLS:443,TLS:8443",
+		},
+		{
+			name:             "network load balancer listeners with target ports",
+			value:            "TLS:443,TCP:5432:30432,TLS:8883:31883",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			expected: []Listener{
+				{Protocol: ListenerProtocolTLS, Port: 443},
+				{Protocol: ListenerProtocolTCP, Port: 5432, TargetPort: 30432},
+				{Protocol: ListenerProtocolTLS, Port: 8883, TargetPort: 31883},
+			},
+			canonical: "TLS:443,TCP:5432:30432,TLS:8883:31883",
+		},
+		{
+			name:             "target port not supported by application load balancers",
+			value:            "HTTPS:443:9999",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "invalid target port",
+			value:            "TCP:5432:0",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			err:              true,
+		},
+		{
+			name:             "too many parts",
+			value:            "TCP:5432:30432:1",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			err:              true,
+		},
+		{
+			name:             "protocol not supported by load balancer type",
+			value:            "TLS:443",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "duplicate port",
+			value:            "HTTP:443,HTTPS:443",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "invalid port",
+			value:            "HTTPS:70000",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "missing port",
+			value:            "HTTPS",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+	} {
+		tt.Run(test.name, func(t *testing.T) {
+			listeners, err := ParseListeners(test.value, test.loadBalancerType)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, listeners)
+			assert.Equal(t, test.canonical, FormatListeners(listeners))
+		})
+	}
+}
+
+func TestNewStackSpecListenerTargetPorts(t *testing.T) {
+	settings := &StackSettings{
+		LoadBalancerType: LoadBalancerTypeNetwork,
+		Listeners:        "TLS:443,TCP:5432:30432",
+	}
+
+	a := &Adapter{manifest: &manifest{}, targetType: elbv2Types.TargetTypeEnumInstance}
+	spec, err := a.newStackSpec("stack", nil, settings)
+	require.NoError(t, err)
+	assert.Equal(t, []Listener{{Protocol: ListenerProtocolTCP, Port: 5432, TargetPort: 30432}}, dedicatedListeners(spec.listeners))
+
+	a.targetType = elbv2Types.TargetTypeEnumIp
+	_, err = a.newStackSpec("stack", nil, settings)
+	assert.Error(t, err)
+}
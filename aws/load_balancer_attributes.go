@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+// loadBalancerAttributes are the load balancer attributes which can be
+// configured in addition to the ones managed by dedicated settings, see
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/application-load-balancers.html#load-balancer-attributes
+var loadBalancerAttributes = map[string]attribute{
+	"routing.http.drop_invalid_header_fields.enabled": {
+		loadBalancerTypes: albOnly,
+		validate:          boolean,
+	},
+	"routing.http.desync_mitigation_mode": {
+		loadBalancerTypes: albOnly,
+		validate:          oneOf("monitor", "defensive", "strictest"),
+	},
+	"routing.http.xff_header_processing.mode": {
+		loadBalancerTypes: albOnly,
+		validate:          oneOf("append", "preserve", "remove"),
+	},
+	"routing.http.preserve_host_header.enabled": {
+		loadBalancerTypes: albOnly,
+		validate:          boolean,
+	},
+	"waf.fail_open.enabled": {
+		loadBalancerTypes: albOnly,
+		validate:          boolean,
+	},
+}
+
+// ParseLoadBalancerAttributes parses a comma separated list of load balancer
+// attributes in the form key=value, e.g.
+// routing.http.desync_mitigation_mode=strictest, for a load balancer of the
+// given type. An empty string results in no attributes.
+func ParseLoadBalancerAttributes(value string, loadBalancerType string) (map[string]string, error) {
+	return parseAttributes(value, loadBalancerType, "load balancer", loadBalancerAttributes)
+}
+
+// FormatLoadBalancerAttributes returns the canonical representation of the
+// load balancer attributes which can be used to compare them.
+func FormatLoadBalancerAttributes(attributes map[string]string) string {
+	return formatAttributes(attributes)
+}
+
+// loadBalancerAttributeList returns the load balancer attributes ordered by
+// key.
+func loadBalancerAttributeList(attributes map[string]string) cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttributeList {
+	list := make(cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttributeList, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		list = append(list, cloudformation.ElasticLoadBalancingV2LoadBalancerLoadBalancerAttribute{
+			Key:   cloudformation.String(key),
+			Value: cloudformation.String(attributes[key]),
+		})
+	}
+	return list
+}
@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoadBalancerAttributes(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		value            string
+		loadBalancerType string
+		want             map[string]string
+		err              bool
+	}{
+		{
+			name:             "empty",
+			loadBalancerType: LoadBalancerTypeApplication,
+			want:             map[string]string{},
+		},
+		{
+			name:             "all attributes",
+			value:            "routing.http.drop_invalid_header_fields.enabled=true, routing.http.desync_mitigation_mode=strictest,routing.http.xff_header_processing.mode=remove,routing.http.preserve_host_header.enabled=false,waf.fail_open.enabled=true",
+			loadBalancerType: LoadBalancerTypeApplication,
+			want: map[string]string{
+				"routing.http.drop_invalid_header_fields.enabled": "true",
+				"routing.http.desync_mitigation_mode":             "strictest",
+				"routing.http.xff_header_processing.mode":         "remove",
+				"routing.http.preserve_host_header.enabled":       "false",
+				"waf.fail_open.enabled":                           "true",
+			},
+		},
+		{
+			name:             "not supported by network load balancers",
+			value:            "routing.http.desync_mitigation_mode=strictest",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			err:              true,
+		},
+		{
+			name:             "invalid desync mitigation mode",
+			value:            "routing.http.desync_mitigation_mode=strict",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "invalid boolean",
+			value:            "routing.http.drop_invalid_header_fields.enabled=yes",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "attribute managed by a dedicated setting",
+			value:            "idle_timeout.timeout_seconds=60",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseLoadBalancerAttributes(test.value, test.loadBalancerType)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestNewStackSpecLoadBalancerAttributes(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		want     map[string]string
+		err      bool
+	}{
+		{
+			name: "defaults",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+			},
+			want: map[string]string{
+				"routing.http.drop_invalid_header_fields.enabled": "true",
+			},
+		},
+		{
+			name: "override",
+			settings: &StackSettings{
+				LoadBalancerType:       LoadBalancerTypeApplication,
+				LoadBalancerAttributes: "routing.http.xff_header_processing.mode=preserve,routing.http.drop_invalid_header_fields.enabled=false",
+			},
+			want: map[string]string{
+				"routing.http.drop_invalid_header_fields.enabled": "false",
+				"routing.http.xff_header_processing.mode":         "preserve",
+			},
+		},
+		{
+			name: "no defaults for network load balancers",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+			},
+		},
+		{
+			name: "override for network load balancers",
+			settings: &StackSettings{
+				LoadBalancerType:       LoadBalancerTypeNetwork,
+				LoadBalancerAttributes: "waf.fail_open.enabled=true",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := (&Adapter{manifest: &manifest{}}).WithLoadBalancerAttributes(map[string]string{
+				"routing.http.drop_invalid_header_fields.enabled": "true",
+			})
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, spec.loadBalancerAttributes)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+			assert.Equal(t, "true", a.loadBalancerAttributes["routing.http.drop_invalid_header_fields.enabled"])
+		})
+	}
+}
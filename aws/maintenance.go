@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	// maintenanceRulePriorityOffset is the priority of the first rule
+	// responding to requests for hosts in maintenance. The rules must be
+	// evaluated before the forward rules, which limits their number.
+	maintenanceRulePriorityOffset int64 = 2
+	maxMaintenanceRules                 = int(forwardRulePriorityOffset - maintenanceRulePriorityOffset)
+)
+
+var defaultMaintenanceResponse = denyResp{
+	statusCode:  503,
+	contentType: "text/plain",
+	body:        "Service Unavailable",
+}
+
+// addMaintenanceRules adds rules responding with the maintenance response
+// to requests for the hosts in maintenance.
+func addMaintenanceRules(template *cloudformation.Template, spec *stackSpec, listenerName string) {
+	i := 0
+	for hosts := range slices.Chunk(spec.maintenanceHosts, maxRuleConditionValues) {
+		template.AddResource(fmt.Sprintf("%sRuleMaintenance%d", listenerName, i+1), &cloudformation.ElasticLoadBalancingV2ListenerRule{
+			Conditions: &cloudformation.ElasticLoadBalancingV2ListenerRuleRuleConditionList{
+				ruleCondition(listenerRuleConditionHostField, hosts),
+			},
+			Actions: &cloudformation.ElasticLoadBalancingV2ListenerRuleActionList{
+				{
+					Type: cloudformation.String(listenerRuleActionTypeFixedRes),
+					FixedResponseConfig: &cloudformation.ElasticLoadBalancingV2ListenerRuleFixedResponseConfig{
+						ContentType: cloudformation.String(spec.maintenanceResponse.contentType),
+						MessageBody: cloudformation.String(spec.maintenanceResponse.body),
+						StatusCode:  cloudformation.String(fmt.Sprintf("%d", spec.maintenanceResponse.statusCode)),
+					},
+				},
+			},
+			Priority:    cloudformation.Integer(maintenanceRulePriorityOffset + int64(i)),
+			ListenerArn: cloudformation.Ref(listenerName).String(),
+		})
+		i++
+	}
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStackSpecMaintenance(t *testing.T) {
+	hosts := func(n int) []string {
+		var h []string
+		for i := 0; i < n; i++ {
+			h = append(h, fmt.Sprintf("host-%d.example.org", i))
+		}
+		return h
+	}
+
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		err      bool
+	}{
+		{
+			name: "maintenance mode",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				Maintenance:      true,
+			},
+		},
+		{
+			name: "hosts in maintenance",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MaintenanceHosts: hosts(maxMaintenanceRules * maxRuleConditionValues),
+			},
+		},
+		{
+			name: "too many hosts in maintenance",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MaintenanceHosts: hosts(maxMaintenanceRules*maxRuleConditionValues + 1),
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				Maintenance:      true,
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{manifest: &manifest{}, maintenanceResponse: defaultMaintenanceResponse}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.settings.Maintenance, spec.maintenance)
+			assert.ElementsMatch(t, test.settings.MaintenanceHosts, spec.maintenanceHosts)
+			assert.Equal(t, defaultMaintenanceResponse, spec.maintenanceResponse)
+
+			tags := convertCloudFormationTags(stackTags(spec))
+			if test.settings.Maintenance {
+				assert.Equal(t, "true", tags[maintenanceTag])
+				assert.NotContains(t, tags, maintenanceHostsHashTag)
+			} else {
+				assert.NotContains(t, tags, maintenanceTag)
+				assert.Equal(t, HostnamesHash(test.settings.MaintenanceHosts), tags[maintenanceHostsHashTag])
+			}
+		})
+	}
+}
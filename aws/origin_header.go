@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	listenerRuleConditionHTTPHeaderField = "http-header"
+
+	// maxOriginHeaderNameLength and maxOriginHeaderValueLength are the
+	// limits of HTTP header conditions of listener rules.
+	maxOriginHeaderNameLength  = 40
+	maxOriginHeaderValueLength = 128
+)
+
+// OriginHeader is a secret HTTP header added by the CDN in front of an
+// application load balancer to its requests. The load balancer only forwards
+// requests with the header to the targets. PreviousValue is accepted in
+// addition to Value, so that the secret can be rotated without rejecting
+// requests from the CDN while it switches to the new value.
+type OriginHeader struct {
+	Name          string `json:"name"`
+	Value         string `json:"value"`
+	PreviousValue string `json:"previousValue,omitempty"`
+}
+
+// Hash returns the hash identifying the origin header, including its
+// values, or an empty string if there is no origin header.
+func (h *OriginHeader) Hash() string {
+	if h == nil {
+		return ""
+	}
+	// marshaling a struct of strings can't fail
+	data, _ := json.Marshal(h)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (h *OriginHeader) validate() error {
+	switch {
+	case h.Name == "":
+		return errors.New("the header name must not be empty")
+	case len(h.Name) > maxOriginHeaderNameLength:
+		return fmt.Errorf("the header name must be at most %d characters", maxOriginHeaderNameLength)
+	case strings.ContainsFunc(h.Name, func(r rune) bool { return r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) }):
+		return fmt.Errorf("invalid header name %q", h.Name)
+	case h.Value == "":
+		return errors.New("the header value must not be empty")
+	}
+
+	for _, v := range []string{h.Value, h.PreviousValue} {
+		if len(v) > maxOriginHeaderValueLength {
+			return fmt.Errorf("the header values must be at most %d characters", maxOriginHeaderValueLength)
+		}
+		// listener rules treat * and ? as wildcards
+		if strings.ContainsAny(v, "*?") {
+			return errors.New("the header values must not contain wildcards (* or ?)")
+		}
+	}
+	return nil
+}
+
+// ruleCondition returns the listener rule condition matching the origin
+// header. The values are referenced from NoEcho parameters to not expose
+// them in the template.
+func (h *OriginHeader) ruleCondition() cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition {
+	values := cloudformation.StringList(cloudformation.Ref(parameterOriginHeaderValueParameter))
+	if h.PreviousValue != "" {
+		values.Literal = append(values.Literal, cloudformation.Ref(parameterOriginHeaderPreviousValueParameter).String())
+	}
+
+	return cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition{
+		Field: cloudformation.String(listenerRuleConditionHTTPHeaderField),
+		HTTPHeaderConfig: &cloudformation.ElasticLoadBalancingV2ListenerRuleHTTPHeaderConfig{
+			HTTPHeaderName: cloudformation.String(h.Name),
+			Values:         values,
+		},
+	}
+}
+
+// conditionValues returns the number of values of the rule condition.
+func (h *OriginHeader) conditionValues() int {
+	if h.PreviousValue != "" {
+		return 2
+	}
+	return 1
+}
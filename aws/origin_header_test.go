@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginHeaderValidate(t *testing.T) {
+	assert.NoError(t, (&OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"}).validate())
+
+	for _, h := range []*OriginHeader{
+		{Value: "secret"},
+		{Name: "X-Origin-Secret"},
+		{Name: "X Origin", Value: "secret"},
+		{Name: strings.Repeat("x", maxOriginHeaderNameLength+1), Value: "secret"},
+		{Name: "X-Origin-Secret", Value: strings.Repeat("x", maxOriginHeaderValueLength+1)},
+		{Name: "X-Origin-Secret", Value: "secret", PreviousValue: "*"},
+	} {
+		assert.Error(t, h.validate(), "%+v", h)
+	}
+}
+
+func TestOriginHeaderHash(t *testing.T) {
+	var nilHeader *OriginHeader
+	assert.Empty(t, nilHeader.Hash())
+
+	h := &OriginHeader{Name: "X-Origin-Secret", Value: "old"}
+	rotated := &OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"}
+	assert.Equal(t, h.Hash(), (&OriginHeader{Name: "X-Origin-Secret", Value: "old"}).Hash())
+	assert.NotEqual(t, h.Hash(), rotated.Hash())
+}
+
+func TestForwardRuleConditionsOriginHeader(t *testing.T) {
+	spec := &stackSpec{
+		loadbalancerType: LoadBalancerTypeApplication,
+		sourceRanges:     []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "2001:db8::/32"},
+		originHeader:     &OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"},
+	}
+	require.True(t, spec.restrictsForwarding())
+
+	rules := spec.forwardRuleConditions()
+	require.Len(t, rules, 2)
+	for _, conditions := range rules {
+		require.Len(t, conditions, 2)
+		assert.Equal(t, "X-Origin-Secret", conditions[0].HTTPHeaderConfig.HTTPHeaderName.Literal)
+		assert.Len(t, conditions[0].HTTPHeaderConfig.Values.Literal, 2)
+		assert.LessOrEqual(t, len(conditions[1].SourceIPConfig.Values.Literal), maxRuleConditionValues-2)
+	}
+
+	spec.sourceRanges = nil
+	assert.Len(t, spec.forwardRuleConditions(), 1)
+}
+
+func TestNewStackSpecOriginHeader(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		err      bool
+	}{
+		{
+			name: "application load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				OriginHeaderRef:  "default/origin",
+				OriginHeader:     &OriginHeader{Name: "X-Origin-Secret", Value: "secret"},
+			},
+		},
+		{
+			name: "origin header not resolved",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				OriginHeaderRef:  "default/origin",
+			},
+			err: true,
+		},
+		{
+			name: "invalid origin header",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				OriginHeaderRef:  "default/origin",
+				OriginHeader:     &OriginHeader{Name: "X-Origin-Secret"},
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				OriginHeaderRef:  "default/origin",
+				OriginHeader:     &OriginHeader{Name: "X-Origin-Secret", Value: "secret"},
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{manifest: &manifest{}}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.settings.OriginHeader, spec.originHeader)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+
+			assert.Contains(t, stackParameters(spec), cfParam(parameterOriginHeaderValueParameter, "secret"))
+			assert.Contains(t, stackTags(spec), cfTag(originHeaderHashTag, test.settings.OriginHeader.Hash()))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const maxResponseHeaderValueLength = 1024
+
+// responseHeaders are the response headers which application load balancers
+// can add to the responses of HTTPS listeners and the listener attributes
+// configuring them, see
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/header-modification.html
+var responseHeaders = map[string]struct {
+	listenerAttribute string
+	validate          func(value string) error
+}{
+	"Strict-Transport-Security": {
+		listenerAttribute: "routing.http.response.strict_transport_security.header_value",
+		validate:          matches(regexp.MustCompile(`(?i)^max-age=\d+(\s*;\s*(includeSubDomains|preload))*$`), "must be max-age=<seconds> optionally followed by includeSubDomains and preload"),
+	},
+	"Content-Security-Policy": {
+		listenerAttribute: "routing.http.response.content_security_policy.header_value",
+		validate:          notEmpty,
+	},
+	"X-Frame-Options": {
+		listenerAttribute: "routing.http.response.x_frame_options.header_value",
+		validate:          matches(regexp.MustCompile(`^(DENY|SAMEORIGIN|ALLOW-FROM \S+)$`), "must be DENY, SAMEORIGIN or ALLOW-FROM <uri>"),
+	},
+	"X-Content-Type-Options": {
+		listenerAttribute: "routing.http.response.x_content_type_options.header_value",
+		validate:          matches(regexp.MustCompile(`^nosniff$`), "must be nosniff"),
+	},
+	"Access-Control-Allow-Origin": {
+		listenerAttribute: "routing.http.response.access_control_allow_origin.header_value",
+		validate:          notEmpty,
+	},
+	"Access-Control-Allow-Methods": {
+		listenerAttribute: "routing.http.response.access_control_allow_methods.header_value",
+		validate:          matches(regexp.MustCompile(`^(\*|[A-Z]+(\s*,\s*[A-Z]+)*)$`), "must be * or a comma separated list of methods"),
+	},
+	"Access-Control-Allow-Headers": {
+		listenerAttribute: "routing.http.response.access_control_allow_headers.header_value",
+		validate:          notEmpty,
+	},
+	"Access-Control-Allow-Credentials": {
+		listenerAttribute: "routing.http.response.access_control_allow_credentials.header_value",
+		validate:          matches(regexp.MustCompile(`^true$`), "must be true"),
+	},
+	"Access-Control-Expose-Headers": {
+		listenerAttribute: "routing.http.response.access_control_expose_headers.header_value",
+		validate:          notEmpty,
+	},
+	"Access-Control-Max-Age": {
+		listenerAttribute: "routing.http.response.access_control_max_age.header_value",
+		validate:          func(value string) error { return intRange(0, 86400)("", value) },
+	},
+}
+
+func matches(re *regexp.Regexp, msg string) func(string) error {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return errors.New(msg)
+		}
+		return nil
+	}
+}
+
+func notEmpty(value string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+// ParseResponseHeaders parses the response headers added by application load
+// balancers, one "Name: value" pair per line. Only the headers supported by
+// the listener attributes are allowed. An empty string results in no
+// headers.
+func ParseResponseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name, v, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid response header %q, expected Name: value", line)
+		}
+		name, v = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name)), strings.TrimSpace(v)
+
+		header, ok := responseHeaders[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported response header %q", name)
+		}
+		if len(v) > maxResponseHeaderValueLength {
+			return nil, fmt.Errorf("invalid response header %q: must be at most %d characters", name, maxResponseHeaderValueLength)
+		}
+		if err := header.validate(v); err != nil {
+			return nil, fmt.Errorf("invalid response header %q: %w", name, err)
+		}
+		if _, ok := headers[name]; ok {
+			return nil, fmt.Errorf("duplicate response header %q", name)
+		}
+		headers[name] = v
+	}
+
+	return headers, nil
+}
+
+// FormatResponseHeaders returns the canonical representation of the response
+// headers ordered by name, which can be used to compare them.
+func FormatResponseHeaders(headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		lines = append(lines, name+": "+headers[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// responseHeaderListenerAttributes returns the listener attributes adding the
+// response headers ordered by attribute key.
+func responseHeaderListenerAttributes(headers map[string]string) *cloudformation.ElasticLoadBalancingV2ListenerListenerAttributeList {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(headers))
+	for name, value := range headers {
+		attributes[responseHeaders[name].listenerAttribute] = value
+	}
+
+	list := make(cloudformation.ElasticLoadBalancingV2ListenerListenerAttributeList, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		list = append(list, cloudformation.ElasticLoadBalancingV2ListenerListenerAttribute{
+			Key:   cloudformation.String(key),
+			Value: cloudformation.String(attributes[key]),
+		})
+	}
+	return &list
+}
@@ -0,0 +1,156 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResponseHeaders(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		want  map[string]string
+		err   bool
+	}{
+		{
+			name: "empty",
+			want: map[string]string{},
+		},
+		{
+			name: "security and CORS headers",
+			value: `
+strict-transport-security: max-age=31536000; includeSubDomains; preload
+X-Frame-Options: SAMEORIGIN
+X-Content-Type-Options: nosniff
+Content-Security-Policy: default-src 'self'; img-src *
+Access-Control-Allow-Origin: https://example.org
+Access-Control-Allow-Methods: GET, POST
+Access-Control-Max-Age: 600
+`,
+			want: map[string]string{
+				"Strict-Transport-Security":    "max-age=31536000; includeSubDomains; preload",
+				"X-Frame-Options":              "SAMEORIGIN",
+				"X-Content-Type-Options":       "nosniff",
+				"Content-Security-Policy":      "default-src 'self'; img-src *",
+				"Access-Control-Allow-Origin":  "https://example.org",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:  "unsupported header",
+			value: "X-Powered-By: foo",
+			err:   true,
+		},
+		{
+			name:  "invalid HSTS",
+			value: "Strict-Transport-Security: includeSubDomains",
+			err:   true,
+		},
+		{
+			name:  "invalid X-Frame-Options",
+			value: "X-Frame-Options: ALLOWALL",
+			err:   true,
+		},
+		{
+			name:  "max age out of range",
+			value: "Access-Control-Max-Age: 100000",
+			err:   true,
+		},
+		{
+			name:  "empty value",
+			value: "Content-Security-Policy:",
+			err:   true,
+		},
+		{
+			name:  "duplicate header",
+			value: "X-Frame-Options: DENY\nx-frame-options: SAMEORIGIN",
+			err:   true,
+		},
+		{
+			name:  "missing separator",
+			value: "X-Frame-Options DENY",
+			err:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseResponseHeaders(test.value)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFormatResponseHeaders(t *testing.T) {
+	assert.Empty(t, FormatResponseHeaders(nil))
+	assert.Equal(t, "Strict-Transport-Security: max-age=300\nX-Frame-Options: DENY", FormatResponseHeaders(map[string]string{
+		"X-Frame-Options":           "DENY",
+		"Strict-Transport-Security": "max-age=300",
+	}))
+}
+
+func TestNewStackSpecResponseHeaders(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		want     map[string]string
+		err      bool
+	}{
+		{
+			name: "defaults",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+			},
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000",
+			},
+		},
+		{
+			name: "override",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				ResponseHeaders:  "X-Frame-Options: DENY\nStrict-Transport-Security: max-age=300",
+			},
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=300",
+				"X-Frame-Options":           "DENY",
+			},
+		},
+		{
+			name: "no defaults for network load balancers",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+			},
+		},
+		{
+			name: "override for network load balancers",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				ResponseHeaders:  "X-Frame-Options: DENY",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := (&Adapter{manifest: &manifest{}}).WithResponseHeaders(map[string]string{
+				"Strict-Transport-Security": "max-age=31536000",
+			})
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, spec.responseHeaders)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+			assert.Equal(t, "max-age=31536000", a.responseHeaders["Strict-Transport-Security"])
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	listenerRuleConditionSourceIPField = "source-ip"
+
+	// maxSourceRangesLength is the maximum length of the source ranges,
+	// which is limited by the maximum size of CloudFormation parameters.
+	maxSourceRangesLength = 4096
+	// maxSecurityGroupRules is the default quota of inbound rules per
+	// security group.
+	maxSecurityGroupRules = 60
+
+	sourceRangesSecurityGroupResourceName = "SourceRangesSecurityGroup"
+)
+
+var defaultSourceRangesDenyResponse = forbiddenResponse
+
+// ParseSourceRanges parses a comma separated list of IPv4 and IPv6 CIDRs and
+// returns their canonical representation which can be used to compare them.
+// An empty string results in no source ranges.
+func ParseSourceRanges(value string) (string, error) {
+	var ranges []string
+	for _, term := range strings.Split(value, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(term)
+		if err != nil {
+			return "", fmt.Errorf("invalid source range %q: %w", term, err)
+		}
+		ranges = append(ranges, prefix.Masked().String())
+	}
+	slices.Sort(ranges)
+
+	sourceRanges := strings.Join(slices.Compact(ranges), ",")
+	if len(sourceRanges) > maxSourceRangesLength {
+		return "", errors.New("too many source ranges")
+	}
+	return sourceRanges, nil
+}
+
+// SourceRangesHash returns the hash identifying the canonical source ranges
+// of a load balancer and an empty string if there are none.
+func SourceRangesHash(sourceRanges string) string {
+	if sourceRanges == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(sourceRanges))
+	return hex.EncodeToString(hash[:])
+}
+
+// addSourceRangesSecurityGroup adds a security group to the network load
+// balancer which only allows traffic from the source ranges to the
+// listeners.
+func addSourceRangesSecurityGroup(template *cloudformation.Template, spec *stackSpec, lb *cloudformation.ElasticLoadBalancingV2LoadBalancer) {
+	var ingress cloudformation.EC2SecurityGroupIngressPropertyList
+	for _, listener := range spec.listenersOrDefault() {
+		for _, sourceRange := range spec.sourceRanges {
+			rule := cloudformation.EC2SecurityGroupIngressProperty{
+				IPProtocol: cloudformation.String("tcp"),
+				FromPort:   cloudformation.Integer(int64(listener.Port)),
+				ToPort:     cloudformation.Integer(int64(listener.Port)),
+			}
+			if strings.Contains(sourceRange, ":") {
+				rule.CidrIPv6 = cloudformation.String(sourceRange)
+			} else {
+				rule.CidrIP = cloudformation.String(sourceRange)
+			}
+			ingress = append(ingress, rule)
+		}
+	}
+
+	template.AddResource(sourceRangesSecurityGroupResourceName, &cloudformation.EC2SecurityGroup{
+		GroupDescription:     cloudformation.String("Source ranges of the load balancer"),
+		SecurityGroupIngress: &ingress,
+		VpcID:                cloudformation.String(spec.vpcID),
+		Tags: &cloudformation.TagList{
+			{
+				Key:   cloudformation.String("StackName"),
+				Value: cloudformation.Ref("AWS::StackName").String(),
+			},
+		},
+	})
+	lb.SecurityGroups = cloudformation.StringList(cloudformation.Ref(sourceRangesSecurityGroupResourceName))
+}
@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceRanges(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		want  string
+		err   bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "canonical order and masked",
+			value: " 10.0.0.1/8, 2001:db8::1/32,192.168.0.0/16,10.0.0.0/8",
+			want:  "10.0.0.0/8,192.168.0.0/16,2001:db8::/32",
+		},
+		{
+			name:  "missing prefix length",
+			value: "10.0.0.1",
+			err:   true,
+		},
+		{
+			name:  "invalid address",
+			value: "10.0.0.256/32",
+			err:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseSourceRanges(test.value)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSourceRangesHash(t *testing.T) {
+	assert.Empty(t, SourceRangesHash(""))
+	assert.NotEmpty(t, SourceRangesHash("10.0.0.0/8"))
+	assert.NotEqual(t, SourceRangesHash("10.0.0.0/8"), SourceRangesHash("10.0.0.0/8,192.168.0.0/16"))
+}
+
+func TestNewStackSpecSourceRanges(t *testing.T) {
+	sourceRanges := func(n int) string {
+		var r []string
+		for i := 0; i < n; i++ {
+			r = append(r, fmt.Sprintf("10.0.%d.0/24", i))
+		}
+		return strings.Join(r, ",")
+	}
+
+	for _, test := range []struct {
+		name         string
+		settings     *StackSettings
+		sourceRanges []string
+		err          bool
+	}{
+		{
+			name: "application load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				SourceRanges:     "192.168.0.0/16,10.0.0.0/8",
+			},
+			sourceRanges: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+		{
+			name: "rule limit exceeded",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				SourceRanges:     sourceRanges(MaxListenerRules*maxRuleConditionValues/2 + 1),
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				SourceRanges:     sourceRanges(maxSecurityGroupRules),
+			},
+			sourceRanges: strings.Split(sourceRanges(maxSecurityGroupRules), ","),
+		},
+		{
+			name: "network load balancer security group rule limit exceeded",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				SourceRanges:     sourceRanges(maxSecurityGroupRules + 1),
+			},
+			err: true,
+		},
+		{
+			name: "invalid source ranges",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				SourceRanges:     "foo",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{manifest: &manifest{}, sourceRangesDenyResponse: defaultSourceRangesDenyResponse}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.sourceRanges, spec.sourceRanges)
+			assert.Equal(t, defaultSourceRangesDenyResponse, spec.sourceRangesDenyResponse)
+		})
+	}
+}
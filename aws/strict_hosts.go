@@ -0,0 +1,295 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	// maxRuleConditionValues is the maximum number of values of all the
+	// conditions of a listener rule.
+	maxRuleConditionValues = 5
+	// MaxListenerRules is the default quota of listener rules per
+	// application load balancer, not counting the default rules.
+	MaxListenerRules = 100
+	// forwardRulePriorityOffset is the priority of the first rule
+	// forwarding requests to the targets when the listeners don't forward
+	// by default. It leaves room for the rules denying internal traffic
+	// which must be evaluated first.
+	forwardRulePriorityOffset int64 = 10
+)
+
+var forbiddenResponse = denyResp{
+	statusCode:  403,
+	contentType: "text/plain",
+	body:        "Forbidden",
+}
+
+var notFoundResponse = denyResp{
+	statusCode:  404,
+	contentType: "text/plain",
+	body:        "Not Found",
+}
+
+// HostnamesHash returns the hash identifying the hostnames of a load
+// balancer in strict hosts mode.
+func HostnamesHash(hostnames []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(normalizeHostnames(hostnames), ",")))
+	return hex.EncodeToString(hash[:])
+}
+
+// normalizeHostnames returns the sorted unique lower case hostnames.
+func normalizeHostnames(hostnames []string) []string {
+	normalized := make([]string, 0, len(hostnames))
+	for _, h := range hostnames {
+		normalized = append(normalized, strings.ToLower(h))
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// forwardingListeners returns the number of listeners which forward to the
+// targets, i.e. which get forward rules if the listeners don't forward by
+// default.
+func (spec *stackSpec) forwardingListeners() int {
+	n := 0
+	for _, l := range spec.listenersOrDefault() {
+		if l.secure() || !spec.httpRedirectToHTTPS {
+			n++
+		}
+	}
+	return n
+}
+
+// listenerRules returns the number of listener rules of the load balancer.
+func (spec *stackSpec) listenerRules() int {
+	if spec.loadbalancerType != LoadBalancerTypeApplication {
+		return 0
+	}
+
+	rulesPerListener := len(spec.forwardRuleConditions()) + chunks(len(spec.maintenanceHosts), maxRuleConditionValues) + spec.fleetRules()
+	if spec.denyInternalDomains {
+		rulesPerListener++
+	}
+	return rulesPerListener * spec.forwardingListeners()
+}
+
+// ListenerRules returns the number of listener rules of a load balancer with
+// the given settings. HTTP requests are redirected to HTTPS unless the HTTP
+// listener mode says otherwise if httpRedirectToHTTPS is set, and the rules
+// denying internal traffic are always counted. Invalid settings are ignored,
+// they are reported when the stack is created.
+func ListenerRules(settings *StackSettings, httpRedirectToHTTPS bool) int {
+	spec := &stackSpec{
+		loadbalancerType:    settings.LoadBalancerType,
+		httpRedirectToHTTPS: httpRedirectToHTTPS,
+		denyInternalDomains: true,
+	}
+
+	if settings.Listeners != "" {
+		listeners, err := ParseListeners(settings.Listeners, settings.LoadBalancerType)
+		if err == nil {
+			spec.listeners = listeners
+		}
+	}
+
+	switch settings.HTTPListenerMode {
+	case HTTPListenerModeRedirect:
+		spec.httpRedirectToHTTPS = true
+	case HTTPListenerModeForward:
+		spec.httpRedirectToHTTPS = false
+	case HTTPListenerModeDisabled:
+		spec.httpDisabled = true
+	}
+
+	if settings.StrictHosts {
+		spec.strictHosts = true
+		spec.hostnames = normalizeHostnames(settings.Hostnames)
+	}
+
+	if settings.SourceRanges != "" {
+		sourceRanges, err := ParseSourceRanges(settings.SourceRanges)
+		if err == nil {
+			spec.sourceRanges = strings.Split(sourceRanges, ",")
+		}
+	}
+
+	spec.originHeader = settings.OriginHeader
+
+	if len(settings.Fleets) > 0 {
+		spec.fleets = make(map[string][]string, len(settings.Fleets))
+		for name, hostnames := range settings.Fleets {
+			spec.fleets[name] = normalizeHostnames(hostnames)
+		}
+	}
+
+	return spec.listenerRules()
+}
+
+// restrictsForwarding returns true if the listeners of the application load
+// balancer only forward requests matching the forward rules.
+func (spec *stackSpec) restrictsForwarding() bool {
+	return spec.loadbalancerType == LoadBalancerTypeApplication &&
+		(spec.strictHosts || len(spec.sourceRanges) > 0 || spec.originHeader != nil)
+}
+
+// forwardRuleConditions returns the conditions of the rules forwarding
+// requests to the targets, one list per rule. Every restriction is split
+// into chunks, so that the rules match all combinations of them without
+// exceeding the number of condition values per rule. The origin header
+// condition is part of every rule.
+func (spec *stackSpec) forwardRuleConditions() [][]cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition {
+	type restriction struct {
+		field  string
+		values []string
+	}
+
+	var restrictions []restriction
+	if spec.strictHosts {
+		if len(spec.hostnames) == 0 {
+			return nil
+		}
+		restrictions = append(restrictions, restriction{listenerRuleConditionHostField, spec.hostnames})
+	}
+	if len(spec.sourceRanges) > 0 {
+		restrictions = append(restrictions, restriction{listenerRuleConditionSourceIPField, spec.sourceRanges})
+	}
+
+	rules := [][]cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition{nil}
+	values := maxRuleConditionValues
+	if spec.originHeader != nil {
+		rules[0] = append(rules[0], spec.originHeader.ruleCondition())
+		values -= spec.originHeader.conditionValues()
+	}
+
+	chunkSizes := make([]int, len(restrictions))
+	switch len(restrictions) {
+	case 0:
+		if spec.originHeader == nil {
+			return nil
+		}
+	case 1:
+		chunkSizes[0] = values
+	default:
+		// use the split of the condition values which results in the
+		// fewest rules
+		best := -1
+		for n := 1; n < values; n++ {
+			rules := chunks(len(restrictions[0].values), n) * chunks(len(restrictions[1].values), values-n)
+			if best < 0 || rules < best {
+				best = rules
+				chunkSizes[0], chunkSizes[1] = n, values-n
+			}
+		}
+	}
+
+	for i, r := range restrictions {
+		var combined [][]cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition
+		for _, conditions := range rules {
+			for values := range slices.Chunk(r.values, chunkSizes[i]) {
+				combined = append(combined, append(slices.Clone(conditions), ruleCondition(r.field, values)))
+			}
+		}
+		rules = combined
+	}
+	return rules
+}
+
+func chunks(n, size int) int {
+	return (n + size - 1) / size
+}
+
+func ruleCondition(field string, values []string) cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition {
+	list := cloudformation.StringList()
+	for _, v := range values {
+		list.Literal = append(list.Literal, cloudformation.String(v))
+	}
+
+	if field == listenerRuleConditionSourceIPField {
+		return cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition{
+			Field:          cloudformation.String(field),
+			SourceIPConfig: &cloudformation.ElasticLoadBalancingV2ListenerRuleSourceIPConfig{Values: list},
+		}
+	}
+	return cloudformation.ElasticLoadBalancingV2ListenerRuleRuleCondition{
+		Field:  cloudformation.String(field),
+		Values: list,
+	}
+}
+
+// addListenerRules adds the rules of a listener of an application load
+// balancer forwarding to the target group. In maintenance mode the listener
+// responds with the maintenance response instead.
+func addListenerRules(template *cloudformation.Template, spec *stackSpec, listenerName string, listener *cloudformation.ElasticLoadBalancingV2Listener, targetGroupName string, authenticate bool) {
+	if spec.loadbalancerType != LoadBalancerTypeApplication {
+		return
+	}
+
+	if spec.maintenance {
+		setFixedResponse(listener, spec.maintenanceResponse)
+		return
+	}
+
+	if spec.restrictsForwarding() {
+		addForwardRules(template, spec, listenerName, listener, targetGroupName, authenticate)
+	}
+	addMaintenanceRules(template, spec, listenerName)
+}
+
+// setFixedResponse replaces the default action of the listener by a fixed
+// response.
+func setFixedResponse(listener *cloudformation.ElasticLoadBalancingV2Listener, resp denyResp) {
+	listener.DefaultActions = &cloudformation.ElasticLoadBalancingV2ListenerActionList{
+		{
+			Type: cloudformation.String(listenerRuleActionTypeFixedRes),
+			FixedResponseConfig: &cloudformation.ElasticLoadBalancingV2ListenerFixedResponseConfig{
+				ContentType: cloudformation.String(resp.contentType),
+				MessageBody: cloudformation.String(resp.body),
+				StatusCode:  cloudformation.String(fmt.Sprintf("%d", resp.statusCode)),
+			},
+		},
+	}
+}
+
+// addForwardRules replaces the default action of the listener by a fixed
+// response and adds rules forwarding the requests matching the restrictions
+// of the load balancer to the target group. The rules authenticate users
+// first if authenticate is set.
+func addForwardRules(template *cloudformation.Template, spec *stackSpec, listenerName string, listener *cloudformation.ElasticLoadBalancingV2Listener, targetGroupName string, authenticate bool) {
+	resp := notFoundResponse
+	switch {
+	case len(spec.sourceRanges) > 0:
+		resp = spec.sourceRangesDenyResponse
+	case spec.originHeader != nil:
+		resp = forbiddenResponse
+	}
+
+	setFixedResponse(listener, resp)
+
+	for i, conditions := range spec.forwardRuleConditions() {
+		ruleConditions := cloudformation.ElasticLoadBalancingV2ListenerRuleRuleConditionList(conditions)
+
+		actions := cloudformation.ElasticLoadBalancingV2ListenerRuleActionList{
+			{
+				Type:           cloudformation.String("forward"),
+				TargetGroupArn: cloudformation.Ref(targetGroupName).String(),
+			},
+		}
+		if authenticate {
+			actions[0].Order = cloudformation.Integer(2)
+			actions = append(cloudformation.ElasticLoadBalancingV2ListenerRuleActionList{spec.auth.authenticateRuleAction()}, actions...)
+		}
+
+		template.AddResource(fmt.Sprintf("%sRuleForward%d", listenerName, i+1), &cloudformation.ElasticLoadBalancingV2ListenerRule{
+			Conditions:  &ruleConditions,
+			Actions:     &actions,
+			Priority:    cloudformation.Integer(forwardRulePriorityOffset + int64(i)),
+			ListenerArn: cloudformation.Ref(listenerName).String(),
+		})
+	}
+}
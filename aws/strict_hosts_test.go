@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostnamesHash(t *testing.T) {
+	assert.Equal(t, HostnamesHash([]string{"foo.org", "bar.org"}), HostnamesHash([]string{"bar.org", "Foo.org", "foo.org"}))
+	assert.NotEqual(t, HostnamesHash([]string{"foo.org"}), HostnamesHash([]string{"foo.org", "bar.org"}))
+}
+
+func TestNewStackSpecStrictHosts(t *testing.T) {
+	hostnames := func(n int) []string {
+		var h []string
+		for i := 0; i < n; i++ {
+			h = append(h, fmt.Sprintf("host-%d.example.org", i))
+		}
+		return h
+	}
+
+	for _, test := range []struct {
+		name      string
+		settings  *StackSettings
+		hostnames []string
+		err       bool
+	}{
+		{
+			name: "hostnames are normalized",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Hostnames:        []string{"foo.org", "Bar.org", "foo.org"},
+			},
+			hostnames: []string{"bar.org", "foo.org"},
+		},
+		{
+			name: "rule limit with HTTP redirect",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeRedirect,
+				StrictHosts:      true,
+				Hostnames:        hostnames(MaxListenerRules * maxRuleConditionValues),
+			},
+			hostnames: hostnames(MaxListenerRules * maxRuleConditionValues),
+		},
+		{
+			name: "rule limit exceeded with HTTP forward",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				HTTPListenerMode: HTTPListenerModeForward,
+				StrictHosts:      true,
+				Hostnames:        hostnames(MaxListenerRules * maxRuleConditionValues),
+			},
+			err: true,
+		},
+		{
+			name: "network load balancer",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeNetwork,
+				StrictHosts:      true,
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{manifest: &manifest{}}
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, spec.strictHosts)
+			assert.ElementsMatch(t, test.hostnames, spec.hostnames)
+		})
+	}
+}
+
+func TestListenerRules(t *testing.T) {
+	settings := &StackSettings{
+		LoadBalancerType: LoadBalancerTypeApplication,
+		StrictHosts:      true,
+		Hostnames:        []string{"a.org", "b.org", "c.org", "d.org", "e.org", "f.org", "g.org"},
+	}
+	// two forward rules and the rule denying internal traffic per listener
+	assert.Equal(t, 3, ListenerRules(settings, true))
+	assert.Equal(t, 6, ListenerRules(settings, false))
+
+	settings.HTTPListenerMode = HTTPListenerModeForward
+	assert.Equal(t, 6, ListenerRules(settings, true))
+
+	settings.Listeners = "HTTPS:443,HTTPS:8443,HTTPS:9443"
+	assert.Equal(t, 9, ListenerRules(settings, true))
+
+	// the hostnames are combined with the source ranges
+	settings.SourceRanges = "10.0.0.0/8,192.168.0.0/16"
+	assert.Equal(t, 12, ListenerRules(settings, true))
+
+	// the origin header values leave fewer values for the other conditions
+	settings.OriginHeader = &OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"}
+	assert.Equal(t, 24, ListenerRules(settings, true))
+
+	settings.LoadBalancerType = LoadBalancerTypeNetwork
+	assert.Equal(t, 0, ListenerRules(settings, true))
+
+	// the rules of every fleet match up to five hostnames
+	settings = &StackSettings{
+		LoadBalancerType: LoadBalancerTypeApplication,
+		Fleets:           map[string][]string{"canary": {"a.org", "b.org", "c.org", "d.org", "e.org", "f.org"}, "edge": {"g.org"}},
+	}
+	assert.Equal(t, 4, ListenerRules(settings, true))
+}
+
+func TestForwardRuleConditions(t *testing.T) {
+	spec := &stackSpec{
+		loadbalancerType: LoadBalancerTypeApplication,
+		strictHosts:      true,
+		hostnames:        []string{"a.org", "b.org", "c.org", "d.org", "e.org", "f.org"},
+		sourceRanges:     []string{"10.0.0.0/8", "192.168.0.0/16"},
+	}
+
+	rules := spec.forwardRuleConditions()
+	require.Len(t, rules, 2)
+	for _, conditions := range rules {
+		require.Len(t, conditions, 2)
+		values := len(conditions[0].Values.Literal) + len(conditions[1].SourceIPConfig.Values.Literal)
+		assert.LessOrEqual(t, values, maxRuleConditionValues)
+		assert.Len(t, conditions[1].SourceIPConfig.Values.Literal, 2)
+	}
+	assert.Equal(t, 4, spec.listenerRules())
+}
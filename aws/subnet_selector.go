@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+const (
+	subnetSelectorTagPrefix = "tag:"
+	subnetSelectorAZPrefix  = "az:"
+	subnetIDPrefix          = "subnet-"
+)
+
+// SubnetSelector selects the subnets of a load balancer from the subnets
+// discovered for the cluster. Subnets are either selected explicitly by ID or
+// filtered by tags and availability zones, in which case one subnet per
+// availability zone is chosen.
+type SubnetSelector struct {
+	IDs []string
+	// Tags that a subnet must have. An empty value matches any value.
+	Tags              map[string]string
+	AvailabilityZones []string
+}
+
+// ParseSubnetSelector parses a comma separated list of subnet IDs
+// (subnet-123), tag selectors (tag:key=value or tag:key) and availability
+// zones (az:eu-central-1a). Subnet IDs can't be combined with tags or
+// availability zones. An empty string results in a nil selector.
+func ParseSubnetSelector(value string) (*SubnetSelector, error) {
+	selector := &SubnetSelector{}
+	for _, term := range strings.Split(value, ",") {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == "":
+			continue
+		case strings.HasPrefix(term, subnetSelectorTagPrefix):
+			key, value, _ := strings.Cut(strings.TrimPrefix(term, subnetSelectorTagPrefix), "=")
+			if key == "" {
+				return nil, fmt.Errorf("invalid subnet tag selector %q", term)
+			}
+			if selector.Tags == nil {
+				selector.Tags = make(map[string]string)
+			}
+			selector.Tags[key] = value
+		case strings.HasPrefix(term, subnetSelectorAZPrefix):
+			az := strings.TrimPrefix(term, subnetSelectorAZPrefix)
+			if az == "" {
+				return nil, fmt.Errorf("invalid subnet availability zone selector %q", term)
+			}
+			if !slices.Contains(selector.AvailabilityZones, az) {
+				selector.AvailabilityZones = append(selector.AvailabilityZones, az)
+			}
+		case strings.HasPrefix(term, subnetIDPrefix):
+			if !slices.Contains(selector.IDs, term) {
+				selector.IDs = append(selector.IDs, term)
+			}
+		default:
+			return nil, fmt.Errorf("invalid subnet selector %q", term)
+		}
+	}
+
+	if len(selector.IDs) == 0 && len(selector.Tags) == 0 && len(selector.AvailabilityZones) == 0 {
+		return nil, nil
+	}
+
+	if len(selector.IDs) > 0 && (len(selector.Tags) > 0 || len(selector.AvailabilityZones) > 0) {
+		return nil, fmt.Errorf("subnet IDs can't be combined with tag or availability zone selectors in %q", value)
+	}
+
+	sort.Strings(selector.IDs)
+	sort.Strings(selector.AvailabilityZones)
+
+	return selector, nil
+}
+
+// String returns the canonical representation of the selector which can be
+// used to compare selectors.
+func (s *SubnetSelector) String() string {
+	if s == nil {
+		return ""
+	}
+
+	terms := make([]string, 0, len(s.IDs)+len(s.Tags)+len(s.AvailabilityZones))
+	terms = append(terms, s.IDs...)
+
+	tags := make([]string, 0, len(s.Tags))
+	for key, value := range s.Tags {
+		if value == "" {
+			tags = append(tags, subnetSelectorTagPrefix+key)
+		} else {
+			tags = append(tags, subnetSelectorTagPrefix+key+"="+value)
+		}
+	}
+	sort.Strings(tags)
+	terms = append(terms, tags...)
+
+	for _, az := range s.AvailabilityZones {
+		terms = append(terms, subnetSelectorAZPrefix+az)
+	}
+
+	return strings.Join(terms, ",")
+}
+
+// matches returns true if the subnet has all the tags and is in one of the
+// availability zones of the selector.
+func (s *SubnetSelector) matches(subnet *subnetDetails) bool {
+	for key, value := range s.Tags {
+		v, ok := subnet.tags[key]
+		if !ok || (value != "" && v != value) {
+			return false
+		}
+	}
+
+	if len(s.AvailabilityZones) > 0 && !slices.Contains(s.AvailabilityZones, subnet.availabilityZone) {
+		return false
+	}
+
+	return true
+}
+
+// selectSubnets returns the IDs of the subnets selected for a load balancer
+// with the given scheme. Explicitly selected subnets must be part of the
+// discovered subnets, public for internet-facing load balancers and in
+// distinct availability zones.
+func selectSubnets(subnets []*subnetDetails, scheme string, selector *SubnetSelector) ([]string, error) {
+	internal := scheme == string(elbv2Types.LoadBalancerSchemeEnumInternal)
+
+	if selector == nil {
+		return findSubnets(subnets, internal, nil), nil
+	}
+
+	if len(selector.IDs) == 0 {
+		subnetIDs := findSubnets(subnets, internal, selector.matches)
+		if len(subnetIDs) == 0 {
+			return nil, fmt.Errorf("no %s subnets match the selector %q", scheme, selector)
+		}
+		return subnetIDs, nil
+	}
+
+	azs := make(map[string]string, len(selector.IDs))
+	for _, id := range selector.IDs {
+		idx := slices.IndexFunc(subnets, func(s *subnetDetails) bool { return s.id == id })
+		if idx < 0 {
+			return nil, fmt.Errorf("subnet %s is not one of the subnets discovered for the cluster", id)
+		}
+
+		subnet := subnets[idx]
+		if !internal && !subnet.public {
+			return nil, fmt.Errorf("subnet %s is not public and can't be used for an %s load balancer", id, scheme)
+		}
+
+		if other, ok := azs[subnet.availabilityZone]; ok {
+			return nil, fmt.Errorf("subnets %s and %s are in the same availability zone %s", other, id, subnet.availabilityZone)
+		}
+		azs[subnet.availabilityZone] = id
+	}
+
+	return selector.IDs, nil
+}
+
+type subnetMapping struct {
+	subnetID           string
+	allocationID       string
+	privateIPv4Address string
+}
+
+// mapSubnets assigns the Elastic IP allocations or private IPv4 addresses to
+// the selected subnets of a load balancer. Allocations are assigned to the
+// subnets ordered by availability zone, private IPv4 addresses to the subnet
+// containing the address. Exactly one allocation or address is required per
+// subnet.
+func mapSubnets(subnets []*subnetDetails, subnetIDs []string, allocationIDs []string, privateIPv4Addresses []string) ([]*subnetMapping, error) {
+	if len(allocationIDs) == 0 && len(privateIPv4Addresses) == 0 {
+		return nil, nil
+	}
+
+	if len(allocationIDs) > 0 && len(privateIPv4Addresses) > 0 {
+		return nil, errors.New("elastic IP allocations and private IPv4 addresses can't be combined")
+	}
+
+	selected := make([]*subnetDetails, 0, len(subnetIDs))
+	for _, id := range subnetIDs {
+		idx := slices.IndexFunc(subnets, func(s *subnetDetails) bool { return s.id == id })
+		if idx < 0 {
+			return nil, fmt.Errorf("subnet %s is not one of the subnets discovered for the cluster", id)
+		}
+		selected = append(selected, subnets[idx])
+	}
+
+	if n := len(allocationIDs) + len(privateIPv4Addresses); n != len(selected) {
+		return nil, fmt.Errorf("%d subnet mappings given for %d subnets %v, exactly one per subnet is required", n, len(selected), subnetIDs)
+	}
+
+	mappings := make([]*subnetMapping, 0, len(selected))
+	if len(allocationIDs) > 0 {
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].availabilityZone < selected[j].availabilityZone
+		})
+
+		for i, subnet := range selected {
+			mappings = append(mappings, &subnetMapping{subnetID: subnet.id, allocationID: allocationIDs[i]})
+		}
+		return mappings, nil
+	}
+
+	for _, subnet := range selected {
+		_, cidr, err := net.ParseCIDR(subnet.cidrBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CIDR block of subnet %s: %w", subnet.id, err)
+		}
+
+		idx := slices.IndexFunc(privateIPv4Addresses, func(address string) bool {
+			ip := net.ParseIP(address)
+			return ip != nil && cidr.Contains(ip)
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("no private IPv4 address given for subnet %s (%s)", subnet.id, subnet.cidrBlock)
+		}
+
+		mappings = append(mappings, &subnetMapping{subnetID: subnet.id, privateIPv4Address: privateIPv4Addresses[idx]})
+	}
+
+	return mappings, nil
+}
+
+// checkIPv6Subnets returns an error if one of the subnets has no IPv6 CIDR
+// block or is not one of the subnets discovered for the cluster.
+func checkIPv6Subnets(subnets []*subnetDetails, subnetIDs []string) error {
+	for _, id := range subnetIDs {
+		idx := slices.IndexFunc(subnets, func(s *subnetDetails) bool { return s.id == id })
+		if idx < 0 {
+			return fmt.Errorf("subnet %s is not one of the subnets discovered for the cluster", id)
+		}
+		if !subnets[idx].ipv6 {
+			return fmt.Errorf("subnet %s has no IPv6 CIDR block", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"testing"
+
+	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubnetSelector(tt *testing.T) {
+	for _, test := range []struct {
+		name      string
+		value     string
+		expected  *SubnetSelector
+		canonical string
+		err       bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:      "subnet IDs are sorted and deduplicated",
+			value:     "subnet-2, subnet-1,subnet-2",
+			expected:  &SubnetSelector{IDs: []string{"subnet-1", "subnet-2"}},
+			canonical: "subnet-1,subnet-2",
+		},
+		{
+			name:  "tags and availability zones",
+			value: "az:eu-central-1b,tag:role=ingress,tag:kubernetes.io/role/elb,az:eu-central-1a",
+			expected: &SubnetSelector{
+				Tags:              map[string]string{"role": "ingress", "kubernetes.io/role/elb": ""},
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+			},
+			canonical: "tag:kubernetes.io/role/elb,tag:role=ingress,az:eu-central-1a,az:eu-central-1b",
+		},
+		{
+			name:  "subnet IDs can't be combined with other selectors",
+			value: "subnet-1,az:eu-central-1a",
+			err:   true,
+		},
+		{
+			name:  "unknown selector",
+			value: "foo",
+			err:   true,
+		},
+		{
+			name:  "empty tag key",
+			value: "tag:=foo",
+			err:   true,
+		},
+	} {
+		tt.Run(test.name, func(t *testing.T) {
+			selector, err := ParseSubnetSelector(test.value)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, selector)
+			assert.Equal(t, test.canonical, selector.String())
+		})
+	}
+}
+
+func TestSelectSubnets(tt *testing.T) {
+	subnets := []*subnetDetails{
+		{id: "subnet-1", availabilityZone: "a", public: true},
+		{id: "subnet-2", availabilityZone: "b", public: true},
+		{id: "subnet-3", availabilityZone: "c", public: true, tags: map[string]string{elbRoleTagName: ""}},
+		{id: "subnet-4", availabilityZone: "a", tags: map[string]string{"role": "ingress"}},
+		{id: "subnet-5", availabilityZone: "b", tags: map[string]string{"role": "ingress"}},
+		{id: "subnet-6", availabilityZone: "a"},
+	}
+
+	internal := string(elbv2Types.LoadBalancerSchemeEnumInternal)
+	public := string(elbv2Types.LoadBalancerSchemeEnumInternetFacing)
+
+	for _, test := range []struct {
+		name     string
+		scheme   string
+		selector string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "no selector discovers subnets",
+			scheme:   public,
+			expected: []string{"subnet-1", "subnet-2", "subnet-3"},
+		},
+		{
+			name:     "explicit subnet IDs",
+			scheme:   internal,
+			selector: "subnet-4,subnet-2",
+			expected: []string{"subnet-2", "subnet-4"},
+		},
+		{
+			name:     "unknown subnet ID",
+			scheme:   internal,
+			selector: "subnet-9",
+			err:      true,
+		},
+		{
+			name:     "private subnet for internet-facing load balancer",
+			scheme:   public,
+			selector: "subnet-4",
+			err:      true,
+		},
+		{
+			name:     "subnets in the same availability zone",
+			scheme:   internal,
+			selector: "subnet-4,subnet-6",
+			err:      true,
+		},
+		{
+			name:     "tag selector",
+			scheme:   internal,
+			selector: "tag:role=ingress",
+			expected: []string{"subnet-4", "subnet-5"},
+		},
+		{
+			name:     "availability zone allowlist",
+			scheme:   public,
+			selector: "az:a,az:c",
+			expected: []string{"subnet-1", "subnet-3"},
+		},
+		{
+			name:     "no matching subnets",
+			scheme:   public,
+			selector: "tag:role=ingress",
+			err:      true,
+		},
+	} {
+		tt.Run(test.name, func(t *testing.T) {
+			selector, err := ParseSubnetSelector(test.selector)
+			require.NoError(t, err)
+
+			got, err := selectSubnets(subnets, test.scheme, selector)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestMapSubnets(tt *testing.T) {
+	subnets := []*subnetDetails{
+		{id: "subnet-1", availabilityZone: "b", cidrBlock: "10.0.1.0/24"},
+		{id: "subnet-2", availabilityZone: "a", cidrBlock: "10.0.2.0/24"},
+		{id: "subnet-3", availabilityZone: "c", cidrBlock: "10.0.3.0/24"},
+	}
+
+	for _, test := range []struct {
+		name                 string
+		subnetIDs            []string
+		allocationIDs        []string
+		privateIPv4Addresses []string
+		expected             []*subnetMapping
+		err                  bool
+	}{
+		{
+			name:      "no mappings",
+			subnetIDs: []string{"subnet-1", "subnet-2"},
+		},
+		{
+			name:          "allocations are assigned by availability zone",
+			subnetIDs:     []string{"subnet-1", "subnet-2"},
+			allocationIDs: []string{"eipalloc-a", "eipalloc-b"},
+			expected: []*subnetMapping{
+				{subnetID: "subnet-2", allocationID: "eipalloc-a"},
+				{subnetID: "subnet-1", allocationID: "eipalloc-b"},
+			},
+		},
+		{
+			name:                 "private addresses are assigned by CIDR block",
+			subnetIDs:            []string{"subnet-1", "subnet-2"},
+			privateIPv4Addresses: []string{"10.0.2.10", "10.0.1.10"},
+			expected: []*subnetMapping{
+				{subnetID: "subnet-1", privateIPv4Address: "10.0.1.10"},
+				{subnetID: "subnet-2", privateIPv4Address: "10.0.2.10"},
+			},
+		},
+		{
+			name:          "one allocation per subnet is required",
+			subnetIDs:     []string{"subnet-1", "subnet-2", "subnet-3"},
+			allocationIDs: []string{"eipalloc-a", "eipalloc-b"},
+			err:           true,
+		},
+		{
+			name:                 "private address outside of the subnets",
+			subnetIDs:            []string{"subnet-1", "subnet-2"},
+			privateIPv4Addresses: []string{"10.0.1.10", "10.0.3.10"},
+			err:                  true,
+		},
+		{
+			name:                 "allocations and private addresses can't be combined",
+			subnetIDs:            []string{"subnet-1"},
+			allocationIDs:        []string{"eipalloc-a"},
+			privateIPv4Addresses: []string{"10.0.1.10"},
+			err:                  true,
+		},
+	} {
+		tt.Run(test.name, func(t *testing.T) {
+			got, err := mapSubnets(subnets, test.subnetIDs, test.allocationIDs, test.privateIPv4Addresses)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestCheckIPv6Subnets(t *testing.T) {
+	subnets := []*subnetDetails{
+		{id: "subnet-1", ipv6: true},
+		{id: "subnet-2", ipv6: true},
+		{id: "subnet-3"},
+	}
+
+	assert.NoError(t, checkIPv6Subnets(subnets, []string{"subnet-1", "subnet-2"}))
+	assert.EqualError(t, checkIPv6Subnets(subnets, []string{"subnet-1", "subnet-3"}), "subnet subnet-3 has no IPv6 CIDR block")
+	assert.EqualError(t, checkIPv6Subnets(subnets, []string{"subnet-1", "subnet-4"}), "subnet subnet-4 is not one of the subnets discovered for the cluster")
+}
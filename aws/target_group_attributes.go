@@ -0,0 +1,156 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	targetGroupAttributeAlgorithmType              = "load_balancing.algorithm.type"
+	targetGroupAttributeSlowStartDuration          = "slow_start.duration_seconds"
+	targetGroupAttributeStickinessEnabled          = "stickiness.enabled"
+	targetGroupAttributeStickinessType             = "stickiness.type"
+	targetGroupAttributeStickinessCookieDuration   = "stickiness.lb_cookie.duration_seconds"
+	targetGroupAttributeStickinessAppCookieName    = "stickiness.app_cookie.cookie_name"
+	targetGroupAttributeStickinessAppCookieTimeout = "stickiness.app_cookie.duration_seconds"
+	targetGroupAttributeConnectionTermination      = "deregistration_delay.connection_termination.enabled"
+
+	// TargetGroupAttributeProxyProtocolV2 enables the proxy protocol v2
+	// on the targets of network load balancers.
+	TargetGroupAttributeProxyProtocolV2 = "proxy_protocol_v2.enabled"
+	// TargetGroupAttributePreserveClientIP makes network load balancers
+	// preserve the IP addresses of the clients.
+	TargetGroupAttributePreserveClientIP = "preserve_client_ip.enabled"
+
+	algorithmLeastOutstandingRequests = "least_outstanding_requests"
+	stickinessTypeAppCookie           = "app_cookie"
+)
+
+var (
+	albOnly  = []string{LoadBalancerTypeApplication}
+	albOrNLB = []string{LoadBalancerTypeApplication, LoadBalancerTypeNetwork}
+)
+
+// targetGroupAttributes are the target group attributes which can be
+// configured, see
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/edit-target-group-attributes.html
+// and
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/network/edit-target-group-attributes.html
+var targetGroupAttributes = map[string]attribute{
+	targetGroupAttributeAlgorithmType: {
+		loadBalancerTypes: albOnly,
+		validate:          oneOf("round_robin", algorithmLeastOutstandingRequests, "weighted_random"),
+	},
+	targetGroupAttributeSlowStartDuration: {
+		loadBalancerTypes: albOnly,
+		validate: func(_, value string) error {
+			if value == "0" {
+				return nil
+			}
+			return intRange(30, 900)("", value)
+		},
+	},
+	targetGroupAttributeStickinessEnabled: {
+		loadBalancerTypes: albOrNLB,
+		validate:          boolean,
+	},
+	targetGroupAttributeStickinessType: {
+		loadBalancerTypes: albOrNLB,
+		validate: func(loadBalancerType, value string) error {
+			if loadBalancerType == LoadBalancerTypeNetwork {
+				return oneOf("source_ip")("", value)
+			}
+			return oneOf("lb_cookie", stickinessTypeAppCookie)("", value)
+		},
+	},
+	targetGroupAttributeStickinessCookieDuration: {
+		loadBalancerTypes: albOnly,
+		validate:          intRange(1, 604800),
+	},
+	targetGroupAttributeStickinessAppCookieName: {
+		loadBalancerTypes: albOnly,
+		validate: func(_, value string) error {
+			if value == "" || strings.HasPrefix(strings.ToUpper(value), "AWSALB") {
+				return errors.New("must not be empty or start with AWSALB")
+			}
+			return nil
+		},
+	},
+	targetGroupAttributeStickinessAppCookieTimeout: {
+		loadBalancerTypes: albOnly,
+		validate:          intRange(1, 604800),
+	},
+	targetGroupAttributeConnectionTermination: {
+		loadBalancerTypes: []string{LoadBalancerTypeNetwork},
+		validate:          boolean,
+	},
+	TargetGroupAttributeProxyProtocolV2: {
+		loadBalancerTypes: []string{LoadBalancerTypeNetwork},
+		validate:          boolean,
+	},
+	TargetGroupAttributePreserveClientIP: {
+		loadBalancerTypes: []string{LoadBalancerTypeNetwork},
+		validate:          boolean,
+	},
+}
+
+// ParseTargetGroupAttributes parses a comma separated list of target group
+// attributes in the form key=value, e.g.
+// load_balancing.algorithm.type=least_outstanding_requests, for a load
+// balancer of the given type. An empty string results in no attributes.
+func ParseTargetGroupAttributes(value string, loadBalancerType string) (map[string]string, error) {
+	return parseAttributes(value, loadBalancerType, "target group", targetGroupAttributes)
+}
+
+// SetTargetGroupAttribute validates the target group attribute for a load
+// balancer of the given type and adds it to the attributes. It fails if the
+// attributes already contain a different value for the key.
+func SetTargetGroupAttribute(attributes map[string]string, key, value, loadBalancerType string) error {
+	parsed, err := ParseTargetGroupAttributes(key+"="+value, loadBalancerType)
+	if err != nil {
+		return err
+	}
+	if v, ok := attributes[key]; ok && v != parsed[key] {
+		return fmt.Errorf("conflicting values %q and %q for target group attribute %q", v, parsed[key], key)
+	}
+	attributes[key] = parsed[key]
+	return nil
+}
+
+// FormatTargetGroupAttributes returns the canonical representation of the
+// target group attributes which can be used to compare them.
+func FormatTargetGroupAttributes(attributes map[string]string) string {
+	return formatAttributes(attributes)
+}
+
+// validateTargetGroupAttributes checks the combination of the target group
+// attributes.
+func validateTargetGroupAttributes(attributes map[string]string) error {
+	if attributes[targetGroupAttributeAlgorithmType] == algorithmLeastOutstandingRequests {
+		if v, ok := attributes[targetGroupAttributeSlowStartDuration]; ok && v != "0" {
+			return fmt.Errorf("slow start is not supported with the %s algorithm", algorithmLeastOutstandingRequests)
+		}
+	}
+
+	if attributes[targetGroupAttributeStickinessType] == stickinessTypeAppCookie && attributes[targetGroupAttributeStickinessAppCookieName] == "" {
+		return fmt.Errorf("%s stickiness requires the %s attribute", stickinessTypeAppCookie, targetGroupAttributeStickinessAppCookieName)
+	}
+	return nil
+}
+
+// targetGroupAttributeList returns the target group attributes ordered by key.
+func targetGroupAttributeList(attributes map[string]string) cloudformation.ElasticLoadBalancingV2TargetGroupTargetGroupAttributeList {
+	list := make(cloudformation.ElasticLoadBalancingV2TargetGroupTargetGroupAttributeList, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		list = append(list, cloudformation.ElasticLoadBalancingV2TargetGroupTargetGroupAttribute{
+			Key:   cloudformation.String(key),
+			Value: cloudformation.String(attributes[key]),
+		})
+	}
+	return list
+}
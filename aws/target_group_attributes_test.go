@@ -0,0 +1,184 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetGroupAttributes(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		value            string
+		loadBalancerType string
+		want             map[string]string
+		err              bool
+	}{
+		{
+			name:             "empty",
+			loadBalancerType: LoadBalancerTypeApplication,
+			want:             map[string]string{},
+		},
+		{
+			name:             "application load balancer",
+			value:            " load_balancing.algorithm.type = least_outstanding_requests, stickiness.enabled=true,stickiness.type=lb_cookie",
+			loadBalancerType: LoadBalancerTypeApplication,
+			want: map[string]string{
+				"load_balancing.algorithm.type": "least_outstanding_requests",
+				"stickiness.enabled":            "true",
+				"stickiness.type":               "lb_cookie",
+			},
+		},
+		{
+			name:             "network load balancer",
+			value:            "stickiness.type=source_ip,deregistration_delay.connection_termination.enabled=true",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			want: map[string]string{
+				"stickiness.type": "source_ip",
+				"deregistration_delay.connection_termination.enabled": "true",
+			},
+		},
+		{
+			name:             "attribute not supported by network load balancers",
+			value:            "slow_start.duration_seconds=30",
+			loadBalancerType: LoadBalancerTypeNetwork,
+			err:              true,
+		},
+		{
+			name:             "stickiness type not supported by application load balancers",
+			value:            "stickiness.type=source_ip",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "slow start out of range",
+			value:            "slow_start.duration_seconds=10",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "unsupported attribute",
+			value:            "deregistration_delay.timeout_seconds=10",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "duplicate attribute",
+			value:            "stickiness.enabled=true,stickiness.enabled=false",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+		{
+			name:             "missing value",
+			value:            "stickiness.enabled",
+			loadBalancerType: LoadBalancerTypeApplication,
+			err:              true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseTargetGroupAttributes(test.value, test.loadBalancerType)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSetTargetGroupAttribute(t *testing.T) {
+	attributes := map[string]string{TargetGroupAttributeProxyProtocolV2: "true"}
+
+	require.NoError(t, SetTargetGroupAttribute(attributes, TargetGroupAttributePreserveClientIP, "false", LoadBalancerTypeNetwork))
+	require.NoError(t, SetTargetGroupAttribute(attributes, TargetGroupAttributeProxyProtocolV2, "true", LoadBalancerTypeNetwork))
+	assert.Equal(t, map[string]string{
+		TargetGroupAttributeProxyProtocolV2:  "true",
+		TargetGroupAttributePreserveClientIP: "false",
+	}, attributes)
+
+	assert.Error(t, SetTargetGroupAttribute(attributes, TargetGroupAttributeProxyProtocolV2, "false", LoadBalancerTypeNetwork), "conflicting value")
+	assert.Error(t, SetTargetGroupAttribute(attributes, TargetGroupAttributePreserveClientIP, "yes", LoadBalancerTypeNetwork), "invalid value")
+	assert.Error(t, SetTargetGroupAttribute(map[string]string{}, TargetGroupAttributeProxyProtocolV2, "true", LoadBalancerTypeApplication), "not supported by ALB")
+}
+
+func TestFormatTargetGroupAttributes(t *testing.T) {
+	assert.Empty(t, FormatTargetGroupAttributes(nil))
+	assert.Equal(t, "slow_start.duration_seconds=30,stickiness.enabled=true", FormatTargetGroupAttributes(map[string]string{
+		"stickiness.enabled":          "true",
+		"slow_start.duration_seconds": "30",
+	}))
+}
+
+func TestNewStackSpecTargetGroupAttributes(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		settings *StackSettings
+		want     map[string]string
+		err      bool
+	}{
+		{
+			name: "defaults",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+			},
+			want: map[string]string{
+				"load_balancing.algorithm.type": "round_robin",
+				"slow_start.duration_seconds":   "30",
+			},
+		},
+		{
+			name: "override",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetGroupAttributes: "slow_start.duration_seconds=0,load_balancing.algorithm.type=least_outstanding_requests",
+			},
+			want: map[string]string{
+				"load_balancing.algorithm.type": "least_outstanding_requests",
+				"slow_start.duration_seconds":   "0",
+			},
+		},
+		{
+			name: "slow start with least outstanding requests",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetGroupAttributes: "load_balancing.algorithm.type=least_outstanding_requests",
+			},
+			err: true,
+		},
+		{
+			name: "app cookie stickiness without cookie name",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeApplication,
+				TargetGroupAttributes: "stickiness.type=app_cookie",
+			},
+			err: true,
+		},
+		{
+			name: "no defaults for network load balancers",
+			settings: &StackSettings{
+				LoadBalancerType:      LoadBalancerTypeNetwork,
+				TargetGroupAttributes: "stickiness.enabled=true",
+			},
+			want: map[string]string{"stickiness.enabled": "true"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := (&Adapter{manifest: &manifest{}}).WithTargetGroupAttributes(LoadBalancerTypeApplication, map[string]string{
+				"load_balancing.algorithm.type": "round_robin",
+				"slow_start.duration_seconds":   "30",
+			})
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, spec.targetGroupAttributes)
+			assert.Equal(t, SettingsHash(test.settings), spec.settingsHash)
+			assert.Equal(t, "30", a.targetGroupAttributes[LoadBalancerTypeApplication]["slow_start.duration_seconds"])
+		})
+	}
+}
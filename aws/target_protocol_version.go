@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+)
+
+const (
+	// TargetProtocolVersionHTTP1 sends requests to the targets using
+	// HTTP/1.1, the default.
+	TargetProtocolVersionHTTP1 = "HTTP1"
+	// TargetProtocolVersionHTTP2 sends requests to the targets using
+	// HTTP/2.
+	TargetProtocolVersionHTTP2 = "HTTP2"
+	// TargetProtocolVersionGRPC sends requests to the targets using gRPC.
+	TargetProtocolVersionGRPC = "GRPC"
+
+	DefaultTargetProtocolVersion = TargetProtocolVersionHTTP1
+)
+
+// TargetProtocolVersions are the protocol versions of the target groups of
+// application load balancers, see
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-target-groups.html#target-group-protocol-version
+var TargetProtocolVersions = []string{
+	TargetProtocolVersionHTTP1,
+	TargetProtocolVersionHTTP2,
+	TargetProtocolVersionGRPC,
+}
+
+// ValidateTargetProtocolVersion returns an error if the protocol version is
+// unknown.
+func ValidateTargetProtocolVersion(protocolVersion string) error {
+	if !slices.Contains(TargetProtocolVersions, protocolVersion) {
+		return fmt.Errorf("invalid target protocol version %q, must be one of %v", protocolVersion, TargetProtocolVersions)
+	}
+	return nil
+}
+
+// ParseHealthCheckMatcher parses the success codes of the target health
+// checks, either comma separated codes like 200,202 or a range like
+// 200-299, and returns them without whitespace. The codes are validated
+// against the protocol version by ValidateHealthCheckMatcher.
+func ParseHealthCheckMatcher(value string) (string, error) {
+	matcher := strings.Join(strings.Fields(value), "")
+	if _, _, err := healthCheckMatcherRange(matcher); err != nil {
+		return "", err
+	}
+	return matcher, nil
+}
+
+// ValidateHealthCheckMatcher returns an error if the health check matcher is
+// invalid or its codes are not supported by the target protocol version: HTTP
+// status codes from 200 to 499, or gRPC status codes from 0 to 99 for the
+// GRPC protocol version.
+func ValidateHealthCheckMatcher(matcher, protocolVersion string) error {
+	lowest, highest, err := healthCheckMatcherRange(matcher)
+	if err != nil {
+		return err
+	}
+	minCode, maxCode := 200, 499
+	if protocolVersion == TargetProtocolVersionGRPC {
+		minCode, maxCode = 0, 99
+	}
+	if lowest < minCode || highest > maxCode {
+		return fmt.Errorf("invalid health check matcher %q, the codes of the %s protocol version must be between %d and %d", matcher, protocolVersion, minCode, maxCode)
+	}
+	return nil
+}
+
+// healthCheckMatcherRange returns the lowest and highest code of the health
+// check matcher.
+func healthCheckMatcherRange(matcher string) (int, int, error) {
+	from, to, isRange := strings.Cut(matcher, "-")
+	codes := strings.Split(matcher, ",")
+	if isRange {
+		codes = []string{from, to}
+	}
+
+	values := make([]int, 0, len(codes))
+	for _, c := range codes {
+		code, err := strconv.Atoi(c)
+		if err != nil || code < 0 {
+			return 0, 0, fmt.Errorf("invalid health check matcher %q, must be codes separated by commas or a range of codes", matcher)
+		}
+		values = append(values, code)
+	}
+	if isRange && values[0] > values[1] {
+		return 0, 0, fmt.Errorf("invalid health check matcher %q, the range must be ascending", matcher)
+	}
+	return slices.Min(values), slices.Max(values), nil
+}
+
+// healthCheckMatcherProperty returns the health check matcher of the target
+// groups or nil to keep the default of the protocol version.
+func (spec *stackSpec) healthCheckMatcherProperty() *cloudformation.ElasticLoadBalancingV2TargetGroupMatcher {
+	if spec.healthCheckMatcher == "" {
+		return nil
+	}
+	if spec.targetProtocolVersion == TargetProtocolVersionGRPC {
+		return &cloudformation.ElasticLoadBalancingV2TargetGroupMatcher{GrpcCode: cloudformation.String(spec.healthCheckMatcher)}
+	}
+	return &cloudformation.ElasticLoadBalancingV2TargetGroupMatcher{HTTPCode: cloudformation.String(spec.healthCheckMatcher)}
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthCheckMatcher(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		want  string
+		err   bool
+	}{
+		{
+			name:  "code",
+			value: "200",
+			want:  "200",
+		},
+		{
+			name:  "codes",
+			value: "200, 202",
+			want:  "200,202",
+		},
+		{
+			name:  "range",
+			value: " 200-299 ",
+			want:  "200-299",
+		},
+		{
+			name:  "empty",
+			value: "",
+			err:   true,
+		},
+		{
+			name:  "invalid code",
+			value: "2xx",
+			err:   true,
+		},
+		{
+			name:  "descending range",
+			value: "299-200",
+			err:   true,
+		},
+		{
+			name:  "codes and range",
+			value: "200,300-399",
+			err:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseHealthCheckMatcher(test.value)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateHealthCheckMatcher(t *testing.T) {
+	assert.NoError(t, ValidateHealthCheckMatcher("200-499", TargetProtocolVersionHTTP1))
+	assert.NoError(t, ValidateHealthCheckMatcher("200,204", TargetProtocolVersionHTTP2))
+	assert.NoError(t, ValidateHealthCheckMatcher("0-99", TargetProtocolVersionGRPC))
+	assert.Error(t, ValidateHealthCheckMatcher("200-500", TargetProtocolVersionHTTP1))
+	assert.Error(t, ValidateHealthCheckMatcher("0", TargetProtocolVersionHTTP2))
+	assert.Error(t, ValidateHealthCheckMatcher("200", TargetProtocolVersionGRPC))
+}
+
+func TestValidateTargetProtocolVersion(t *testing.T) {
+	for _, protocolVersion := range TargetProtocolVersions {
+		assert.NoError(t, ValidateTargetProtocolVersion(protocolVersion))
+	}
+	assert.Error(t, ValidateTargetProtocolVersion("http2"))
+}
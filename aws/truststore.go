@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// MTLSModeOff disables mutual TLS authentication
+	MTLSModeOff = "off"
+	// MTLSModeVerify verifies client certificates with a trust store
+	MTLSModeVerify = "verify"
+	// MTLSModePassthrough forwards client certificates to the targets
+	// in the X-Amzn-Mtls-Clientcert header without verification
+	MTLSModePassthrough = "passthrough"
+
+	trustStoreResourceName = "TrustStore"
+)
+
+// MTLSModes are the mutual TLS modes of application load balancer
+// listeners.
+var MTLSModes = []string{
+	MTLSModeOff,
+	MTLSModeVerify,
+	MTLSModePassthrough,
+}
+
+type S3API interface {
+	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// CABundleHash returns the hash identifying a CA bundle or an empty string
+// if there is no CA bundle.
+func CABundleHash(caBundle string) string {
+	if caBundle == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(caBundle))
+	return hex.EncodeToString(hash[:])
+}
+
+// validateCABundle checks that the CA bundle only contains PEM encoded
+// certificates.
+func validateCABundle(caBundle string) error {
+	rest := []byte(caBundle)
+	certificates := 0
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block %q in CA bundle", block.Type)
+		}
+		certificates++
+	}
+
+	if certificates == 0 || strings.TrimSpace(string(rest)) != "" {
+		return errors.New("CA bundle must contain PEM encoded certificates only")
+	}
+	return nil
+}
+
+// caBundleS3Key returns the S3 key of the CA bundle of a trust store. The
+// key changes with the content of the CA bundle, so that the trust store is
+// updated.
+func caBundleS3Key(prefix, clusterID, caBundleHash string) string {
+	return path.Join(prefix, clusterID, caBundleHash+".pem")
+}
+
+func putCABundle(ctx context.Context, svc S3API, bucket, key, caBundle string) error {
+	_, err := svc.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        strings.NewReader(caBundle),
+		ContentType: aws.String("application/x-pem-file"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload CA bundle to s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
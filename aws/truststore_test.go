@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws/fake"
+)
+
+func testCABundle(blockTypes ...string) string {
+	var bundle []byte
+	for _, typ := range blockTypes {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: []byte("foo")})...)
+	}
+	return string(bundle)
+}
+
+func TestValidateCABundle(t *testing.T) {
+	assert.NoError(t, validateCABundle(testCABundle("CERTIFICATE")))
+	assert.NoError(t, validateCABundle(testCABundle("CERTIFICATE", "CERTIFICATE")+"\n"))
+	assert.Error(t, validateCABundle(""))
+	assert.Error(t, validateCABundle("foo"))
+	assert.Error(t, validateCABundle(testCABundle("CERTIFICATE")+"foo"))
+	assert.Error(t, validateCABundle(testCABundle("CERTIFICATE", "PRIVATE KEY")))
+}
+
+func TestCABundleHash(t *testing.T) {
+	assert.Empty(t, CABundleHash(""))
+	assert.Len(t, CABundleHash("foo"), 64)
+	assert.Equal(t, CABundleHash("foo"), CABundleHash("foo"))
+	assert.NotEqual(t, CABundleHash("foo"), CABundleHash("bar"))
+}
+
+func TestNewStackSpecMTLS(t *testing.T) {
+	caBundle := testCABundle("CERTIFICATE")
+
+	for _, test := range []struct {
+		name     string
+		bucket   string
+		settings *StackSettings
+		s3Key    string
+		err      bool
+	}{
+		{
+			name:     "off",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, MTLSMode: MTLSModeOff},
+		},
+		{
+			name:     "passthrough",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, MTLSMode: MTLSModePassthrough},
+		},
+		{
+			name:   "verify",
+			bucket: "bucket",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MTLSMode:         MTLSModeVerify,
+				MTLSCABundleRef:  "configmap/default/ca/ca.crt",
+				MTLSCABundle:     caBundle,
+			},
+			s3Key: "prefix/cluster/" + CABundleHash(caBundle) + ".pem",
+		},
+		{
+			name: "verify without bucket",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MTLSMode:         MTLSModeVerify,
+				MTLSCABundle:     caBundle,
+			},
+			err: true,
+		},
+		{
+			name:   "verify without CA bundle",
+			bucket: "bucket",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MTLSMode:         MTLSModeVerify,
+			},
+			err: true,
+		},
+		{
+			name:   "verify with invalid CA bundle",
+			bucket: "bucket",
+			settings: &StackSettings{
+				LoadBalancerType: LoadBalancerTypeApplication,
+				MTLSMode:         MTLSModeVerify,
+				MTLSCABundle:     "foo",
+			},
+			err: true,
+		},
+		{
+			name:     "network load balancer",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeNetwork, MTLSMode: MTLSModePassthrough},
+			err:      true,
+		},
+		{
+			name:     "invalid mode",
+			settings: &StackSettings{LoadBalancerType: LoadBalancerTypeApplication, MTLSMode: "foo"},
+			err:      true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Adapter{
+				manifest: &manifest{clusterID: "cluster"},
+				s3:       &fake.S3Client{},
+			}
+			a = a.WithCABundleS3Location(test.bucket, "prefix")
+
+			spec, err := a.newStackSpec("stack", nil, test.settings)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.s3Key, spec.mtlsCABundleS3Key)
+
+			require.NoError(t, a.uploadCABundle(context.Background(), spec))
+			objects := a.s3.(*fake.S3Client).Objects
+			if test.s3Key == "" {
+				assert.Empty(t, objects)
+			} else {
+				assert.Equal(t, caBundle, objects[test.bucket][test.s3Key])
+			}
+		})
+	}
+}
+
+func TestUploadCABundleError(t *testing.T) {
+	a := &Adapter{s3: &fake.S3Client{Err: errors.New("failed")}}
+	err := a.uploadCABundle(context.Background(), &stackSpec{mtlsCABundle: "foo", mtlsCABundleS3Bucket: "bucket", mtlsCABundleS3Key: "key"})
+	assert.Error(t, err)
+}
@@ -54,6 +54,11 @@ var (
 	certFilterTag                 string
 	stackTerminationProtection    bool
 	additionalStackTags           = make(map[string]string)
+	ingressLabelTags              []string
+	namespaceLabelTags            []string
+	internalSubnetSelector        string
+	internetFacingSubnetSelector  string
+	subnetSelectors               = make(map[string]*aws.SubnetSelector)
 	idleConnectionTimeout         time.Duration
 	deregistrationDelayTimeout    time.Duration
 	minLoadBalancerAge            time.Duration
@@ -69,6 +74,8 @@ var (
 	ipAddressType                 string
 	albLogsS3Bucket               string
 	albLogsS3Prefix               string
+	caBundleS3Bucket              string
+	caBundleS3Prefix              string
 	wafWebAclId                   string
 	httpRedirectToHTTPS           bool
 	debugFlag                     bool
@@ -76,19 +83,47 @@ var (
 	firstRun                      bool = true
 	cwAlarmConfigMap              string
 	cwAlarmConfigMapLocation      *kubernetes.ResourceLocation
+	maintenanceConfigMap          string
+	maintenanceConfigMapLocation  *kubernetes.ResourceLocation
+	maintenanceRespBody           string
+	maintenanceRespContentType    string
+	maintenanceRespStatusCode     int
+	capacityReservationConfigMap  string
+	capacityReservationLocation   *kubernetes.ResourceLocation
+	fleetWeightsConfigMap         string
+	fleetWeightsLocation          *kubernetes.ResourceLocation
+	albTargetGroupAttributes      string
+	nlbTargetGroupAttributes      string
+	nlbProxyProtocol              string
+	nlbPreserveClientIP           string
+	targetGroupAttributes         = make(map[string]map[string]string)
+	albLoadBalancerAttributesFlag string
+	albLoadBalancerAttributes     map[string]string
+	responseHeadersFlag           []string
+	responseHeaders               map[string]string
 	loadBalancerType              string
 	nlbZoneAffinity               string
+	targetProtocolVersion         string
+	healthCheckMatcherFlag        string
+	healthCheckMatcher            string
 	nlbCrossZone                  bool
 	nlbHTTPEnabled                bool
+	nlbSecurityGroup              bool
 	ingressAPIVersion             string
 	internalDomains               []string
 	targetAccessMode              string
 	targetCNINamespace            string
 	targetCNIPodLabelSelector     string
+	targetCNIService              string
+	fleetsFlag                    []string
+	fleets                        map[string]string
 	denyInternalDomains           bool
 	denyInternalRespBody          string
 	denyInternalRespContentType   string
 	denyInternalRespStatusCode    int
+	sourceRangesRespBody          string
+	sourceRangesRespContentType   string
+	sourceRangesRespStatusCode    int
 	defaultInternalDomains        = fmt.Sprintf("*%s", kubernetes.DefaultClusterLocalDomain)
 )
 
@@ -112,6 +147,10 @@ func loadSettings() error {
 		Default("false").BoolVar(&stackTerminationProtection)
 	kingpin.Flag("additional-stack-tags", "set additional custom tags on the Cloudformation Stacks managed by the controller.").
 		StringMapVar(&additionalStackTags)
+	kingpin.Flag("ingress-label-tags", "Ingress label keys whose values are added as tags to the Cloudformation Stacks and load balancer resources. Set it multiple times for multiple labels.").
+		StringsVar(&ingressLabelTags)
+	kingpin.Flag("namespace-label-tags", "Namespace label keys whose values are added as tags to the Cloudformation Stacks and load balancer resources of the Ingresses in the namespace. Set it multiple times for multiple labels.").
+		StringsVar(&namespaceLabelTags)
 	kingpin.Flag("cert-ttl-timeout", "sets the timeout of how long a certificate is kept on an old ALB to be decommissioned.").
 		Default(defaultCertTTL).DurationVar(&certTTL)
 	kingpin.Flag("cert-filter-tag", "sets a tag so the ingress controller only consider ACM or IAM certificates that have this tag set when adding a certificate to a load balancer.").
@@ -160,26 +199,64 @@ func loadSettings() error {
 	kingpin.Flag("ssl-policy", "Security policy that will define the protocols/ciphers accepts by the SSL listener").
 		Default(aws.DefaultSslPolicy).EnumVar(&sslPolicy, aws.SSLPoliciesList...)
 	kingpin.Flag("blacklist-certificate-arns", "Certificate ARNs to not consider by the controller.").StringsVar(&blacklistCertARNs)
-	kingpin.Flag("ip-addr-type", "IP Address type to use.").
-		Default(aws.DefaultIpAddressType).EnumVar(&ipAddressType, aws.IPAddressTypeIPV4, aws.IPAddressTypeDualstack)
+	kingpin.Flag("ip-addr-type", "IP Address type to use. dualstack-without-public-ipv4 only applies to internet-facing Application Load Balancers, the others use dualstack instead.").
+		Default(aws.DefaultIpAddressType).EnumVar(&ipAddressType, aws.IPAddressTypes...)
 	kingpin.Flag("logs-s3-bucket", "S3 bucket to be used for ALB logging").
 		Default(aws.DefaultAlbS3LogsBucket).StringVar(&albLogsS3Bucket)
 	kingpin.Flag("logs-s3-prefix", "Prefix within S3 bucket to be used for ALB logging").
 		Default(aws.DefaultAlbS3LogsPrefix).StringVar(&albLogsS3Prefix)
+	kingpin.Flag("mtls-ca-bundle-s3-bucket", "S3 bucket the CA bundles of mutual TLS trust stores are uploaded to. Required for the mutual TLS verify mode.").
+		StringVar(&caBundleS3Bucket)
+	kingpin.Flag("mtls-ca-bundle-s3-prefix", "Prefix within the S3 bucket the CA bundles of mutual TLS trust stores are uploaded to.").
+		StringVar(&caBundleS3Prefix)
+	kingpin.Flag("internal-subnet-selector", "Selects the subnets of internal load balancers by subnet IDs (subnet-1,subnet-2), tags (tag:key=value,tag:key) or availability zones (az:eu-central-1a). Subnets are discovered automatically if empty.").
+		StringVar(&internalSubnetSelector)
+	kingpin.Flag("internet-facing-subnet-selector", "Selects the subnets of internet-facing load balancers by subnet IDs (subnet-1,subnet-2), tags (tag:key=value,tag:key) or availability zones (az:eu-central-1a). Subnets are discovered automatically if empty.").
+		StringVar(&internetFacingSubnetSelector)
 	kingpin.Flag("aws-waf-web-acl-id", "WAF web acl id to be associated with the ALB. For WAF v2 it is possible to specify the WebACL ARN arn:aws:wafv2:<region>:<account>:regional/webacl/<name>/<id>").
 		Default("").StringVar(&wafWebAclId)
 	kingpin.Flag("cloudwatch-alarms-config-map", "ConfigMap location of the form 'namespace/config-map-name' where to read CloudWatch Alarm configuration from. Ignored if empty.").
 		StringVar(&cwAlarmConfigMap)
+	kingpin.Flag("maintenance-config-map", "ConfigMap location of the form 'namespace/config-map-name' where to read the hosts in maintenance from. The key 'hosts' lists the hosts separated by commas or whitespace. Ignored if empty.").
+		StringVar(&maintenanceConfigMap)
+	kingpin.Flag("capacity-reservation-config-map", "ConfigMap location of the form 'namespace/config-map-name' where to read the minimum capacity reservations of load balancers from. The key 'reservations' lists the reservations with hosts, capacityUnits, from and until. Ignored if empty.").
+		StringVar(&capacityReservationConfigMap)
+	kingpin.Flag("fleet-weights-config-map", "ConfigMap location of the form 'namespace/config-map-name' where to read the percentages of the requests forwarded to fleets from. The key 'weights' lists <fleet>=<weight> pairs separated by commas or whitespace and applies to the application load balancers without the fleet weights annotation. Ignored if empty.").
+		StringVar(&fleetWeightsConfigMap)
+	kingpin.Flag("maintenance-response", "Defines the response body of application load balancers for requests to hosts or Ingresses in maintenance.").
+		Default("Service Unavailable").StringVar(&maintenanceRespBody)
+	kingpin.Flag("maintenance-response-content-type", "Defines the response content-type of application load balancers for requests to hosts or Ingresses in maintenance.").
+		Default("text/plain").StringVar(&maintenanceRespContentType)
+	kingpin.Flag("maintenance-response-status-code", "Defines the response status code of application load balancers for requests to hosts or Ingresses in maintenance.").
+		Default("503").IntVar(&maintenanceRespStatusCode)
 	kingpin.Flag("redirect-http-to-https", "Configure HTTP listener to redirect to HTTPS").
 		Default(defaultHTTPRedirectToHTTPS).BoolVar(&httpRedirectToHTTPS)
 	kingpin.Flag("load-balancer-type", "Sets default Load Balancer type (application or network).").
 		Default(aws.LoadBalancerTypeApplication).EnumVar(&loadBalancerType, aws.LoadBalancerTypeApplication, aws.LoadBalancerTypeNetwork)
 	kingpin.Flag("nlb-zone-affinity", "Specify whether Route53 should return zone aware Network Load Balancers IPs. It configures dns_record.client_routing_policy NLB configuration. This setting only apply to 'network' Load Balancers.").
 		Default(aws.DefaultZoneAffinity).StringVar(&nlbZoneAffinity)
+	kingpin.Flag("alb-target-group-attributes", "Sets the default target group attributes of Application Load Balancers as comma separated list of key=value pairs, e.g. load_balancing.algorithm.type=least_outstanding_requests,slow_start.duration_seconds=30. Supported are load_balancing.algorithm.type, slow_start.duration_seconds and the stickiness attributes.").
+		StringVar(&albTargetGroupAttributes)
+	kingpin.Flag("alb-target-protocol-version", "Sets the default protocol version of the target groups of Application Load Balancers: HTTP1, HTTP2 or GRPC. HTTP2 and GRPC require HTTP listeners to redirect to HTTPS or a separate --alb-http-target-port.").
+		Default(aws.DefaultTargetProtocolVersion).EnumVar(&targetProtocolVersion, aws.TargetProtocolVersions...)
+	kingpin.Flag("alb-health-check-matcher", "Sets the default success codes of the health checks of Application Load Balancer target groups with the default protocol version, either comma separated codes like 200,202 or a range like 200-299. These are HTTP status codes, or gRPC status codes for the GRPC protocol version. The AWS defaults are used if empty.").
+		StringVar(&healthCheckMatcherFlag)
+	kingpin.Flag("nlb-target-group-attributes", "Sets the default target group attributes of Network Load Balancers as comma separated list of key=value pairs. Supported are stickiness.enabled, stickiness.type and deregistration_delay.connection_termination.enabled.").
+		StringVar(&nlbTargetGroupAttributes)
+	kingpin.Flag("alb-load-balancer-attributes", "Sets the default attributes of Application Load Balancers as comma separated list of key=value pairs, e.g. routing.http.drop_invalid_header_fields.enabled=true,routing.http.desync_mitigation_mode=strictest. Supported are routing.http.drop_invalid_header_fields.enabled, routing.http.desync_mitigation_mode, routing.http.xff_header_processing.mode, routing.http.preserve_host_header.enabled and waf.fail_open.enabled.").
+		StringVar(&albLoadBalancerAttributesFlag)
+	kingpin.Flag("response-header", "Adds a response header to the responses of the HTTPS listeners of Application Load Balancers in the form 'Name: value', e.g. 'Strict-Transport-Security: max-age=31536000; includeSubDomains'. Can be repeated. Supported are Strict-Transport-Security, Content-Security-Policy, X-Frame-Options, X-Content-Type-Options and the Access-Control-* CORS headers.").
+		StringsVar(&responseHeadersFlag)
+	kingpin.Flag("nlb-proxy-protocol", "Enables or disables the proxy protocol v2 on the target groups of Network Load Balancers, the targets must accept it. It configures the proxy_protocol_v2.enabled target group attribute.").
+		EnumVar(&nlbProxyProtocol, "true", "false")
+	kingpin.Flag("nlb-preserve-client-ip", "Enables or disables the preservation of the client IP addresses by the target groups of Network Load Balancers. It configures the preserve_client_ip.enabled target group attribute.").
+		EnumVar(&nlbPreserveClientIP, "true", "false")
 	kingpin.Flag("nlb-cross-zone", "Specify whether Network Load Balancers should balance cross availablity zones. This setting only apply to 'network' Load Balancers.").
 		Default("false").BoolVar(&nlbCrossZone)
 	kingpin.Flag("nlb-http-enabled", "Enable HTTP (port 80) for Network Load Balancers. By default this is disabled as NLB can't provide HTTP -> HTTPS redirect.").
 		Default("false").BoolVar(&nlbHTTPEnabled)
+	kingpin.Flag("nlb-security-group", "Attaches the controller security group to new Network Load Balancers. Existing Network Load Balancers are not changed because security groups can't be added to them after their creation.").
+		Default("false").BoolVar(&nlbSecurityGroup)
 	kingpin.Flag("deny-internal-domains", "Sets a rule on ALB's Listeners that denies requests with the Host header as a internal domain. Domains can be set with the -internal-domains flag.").
 		Default("false").BoolVar(&denyInternalDomains)
 	kingpin.Flag("internal-domains", "Define the internal domains to be blocked when -deny-internal-domains is set to true. Set it multiple times for multiple domains. The maximum size of each name is 128 characters. The following wildcard characters are supported: * (matches 0 or more characters) and ? (matches exactly 1 character).").
@@ -190,15 +267,26 @@ func loadSettings() error {
 		Default("text/plain").StringVar(&denyInternalRespContentType)
 	kingpin.Flag("deny-internal-domains-response-status-code", "Defines the response status code for a request identified as to an internal domain when -deny-internal-domains is set.").
 		Default("401").IntVar(&denyInternalRespStatusCode)
+	kingpin.Flag("source-ranges-response", "Defines the response body for a request from outside of the source ranges of an application load balancer.").
+		Default("Forbidden").StringVar(&sourceRangesRespBody)
+	kingpin.Flag("source-ranges-response-content-type", "Defines the response content-type for a request from outside of the source ranges of an application load balancer.").
+		Default("text/plain").StringVar(&sourceRangesRespContentType)
+	kingpin.Flag("source-ranges-response-status-code", "Defines the response status code for a request from outside of the source ranges of an application load balancer.").
+		Default("403").IntVar(&sourceRangesRespStatusCode)
 	kingpin.Flag("target-access-mode", "Defines target type of the target groups in CloudFormation and how loadbalancer targets are discovered. "+
 		"HostPort sets target type to 'instance' and discovers EC2 instances using AWS API and instance filters. "+
-		"AWSCNI sets target type to 'ip' and discovers target IPs using Kubernetes API and Pod label selector. "+
+		"AWSCNI sets target type to 'ip' and discovers target IPs using Kubernetes API and Pod label selector or the EndpointSlices of a Service. "+
 		"Legacy is the same as HostPort but does not set target type and relies on CloudFormation to use 'instance' as a default value. "+
 		"Changing value from 'Legacy' to 'HostPort' will change target type in CloudFormation and trigger target group recreation and downtime.").
 		Required().EnumVar(&targetAccessMode, aws.TargetAccessModeHostPort, aws.TargetAccessModeAWSCNI, aws.TargetAccessModeLegacy)
 	kingpin.Flag("target-cni-namespace", "AWS VPC CNI only. Defines the namespace for ingress pods that should be linked to target group.").StringVar(&targetCNINamespace)
 	// LabelSelector semantics https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
 	kingpin.Flag("target-cni-pod-labelselector", "AWS VPC CNI only. Defines the labelselector for ingress pods that should be linked to target group. Supports simple equality and multi value form (a=x,b=y) as well as complex forms (a IN (x,y,z).").StringVar(&targetCNIPodLabelSelector)
+	kingpin.Flag("target-cni-service", "AWS VPC CNI only. Defines the Service in the namespace of target-cni-namespace whose ready endpoints are linked to target groups on the endpoint port, in the format <name>[:<port name>]. "+
+		"Without a port name the first port of the Service is used. Replaces target-cni-pod-labelselector.").StringVar(&targetCNIService)
+	kingpin.Flag("fleet", "Defines a fleet of targets which ingresses can select by annotation to serve their hosts from a target group of its own, in the format <name>=<selector>. "+
+		"The selector is an Auto Scaling Group tag filter in the format of "+customTagFilterEnvVarName+", or a pod label selector in the namespace of target-cni-namespace when target-access-mode is set to "+aws.TargetAccessModeAWSCNI+", or a Service in the format of target-cni-service if that is set. "+
+		"Auto Scaling Groups of fleets don't get the default target groups. Can be repeated.").StringsVar(&fleetsFlag)
 	kingpin.Parse()
 
 	// We currently only support one Ingress API Version
@@ -213,12 +301,51 @@ func loadSettings() error {
 		if targetCNINamespace == "" {
 			return fmt.Errorf("target-cni-namespace is required when target-access-mode is set to %s", aws.TargetAccessModeAWSCNI)
 		}
+		switch {
+		case targetCNIService != "":
+			if targetCNIPodLabelSelector != "" {
+				return fmt.Errorf("target-cni-service and target-cni-pod-labelselector are mutually exclusive")
+			}
+			if _, _, err := kubernetes.ParseTargetService(targetCNIService); err != nil {
+				return err
+			}
 		// complex selector formats possible, late validation by the k8s client
-		if targetCNIPodLabelSelector == "" {
+		case targetCNIPodLabelSelector == "":
 			return fmt.Errorf("target-cni-pod-labelselector definition cannot be empty when target-access-mode is set to %s", aws.TargetAccessModeAWSCNI)
 		}
 	}
 
+	var err error
+	if fleets, err = aws.ParseFleets(fleetsFlag); err != nil {
+		return err
+	}
+	// complex pod label selector formats possible, late validation by the k8s client
+	switch {
+	case targetAccessMode != aws.TargetAccessModeAWSCNI:
+		for name, selector := range fleets {
+			if _, err := aws.ParseFilterTags(selector); err != nil {
+				return fmt.Errorf("invalid filter of fleet %q: %w", name, err)
+			}
+		}
+	case targetCNIService != "":
+		for name, service := range fleets {
+			if _, _, err := kubernetes.ParseTargetService(service); err != nil {
+				return fmt.Errorf("invalid service of fleet %q: %w", name, err)
+			}
+		}
+	}
+
+	for scheme, value := range map[string]string{
+		string(elbv2Types.LoadBalancerSchemeEnumInternal):       internalSubnetSelector,
+		string(elbv2Types.LoadBalancerSchemeEnumInternetFacing): internetFacingSubnetSelector,
+	} {
+		selector, err := aws.ParseSubnetSelector(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s subnet selector: %w", scheme, err)
+		}
+		subnetSelectors[scheme] = selector
+	}
+
 	if creationTimeout < 1*time.Minute {
 		return fmt.Errorf("invalid creation timeout %d. please specify a value > 1min", creationTimeout)
 	}
@@ -249,6 +376,11 @@ func loadSettings() error {
 		return fmt.Errorf("NLB HTTP is not enabled")
 	}
 
+	// the endpoints of the target service are registered on their port in all target groups
+	if targetCNIService != "" && ((albHTTPTargetPort != 0 && albHTTPTargetPort != targetPort) || (nlbHTTPTargetPort != 0 && nlbHTTPTargetPort != targetPort)) {
+		return fmt.Errorf("target-cni-service doesn't support HTTP target ports different from the target port")
+	}
+
 	if maxCertsPerALB > aws.DefaultMaxCertsPerALB {
 		return fmt.Errorf("invalid max number of certificates per ALB: %d. AWS does not allow more than %d", maxCertsPerALB, aws.DefaultMaxCertsPerALB)
 	}
@@ -262,6 +394,82 @@ func loadSettings() error {
 		cwAlarmConfigMapLocation = loc
 	}
 
+	for loadBalancerType, value := range map[string]string{
+		aws.LoadBalancerTypeApplication: albTargetGroupAttributes,
+		aws.LoadBalancerTypeNetwork:     nlbTargetGroupAttributes,
+	} {
+		attributes, err := aws.ParseTargetGroupAttributes(value, loadBalancerType)
+		if err != nil {
+			return fmt.Errorf("invalid %s target group attributes: %w", loadBalancerType, err)
+		}
+		targetGroupAttributes[loadBalancerType] = attributes
+	}
+	if healthCheckMatcherFlag != "" {
+		matcher, err := aws.ParseHealthCheckMatcher(healthCheckMatcherFlag)
+		if err != nil {
+			return err
+		}
+		if err := aws.ValidateHealthCheckMatcher(matcher, targetProtocolVersion); err != nil {
+			return err
+		}
+		healthCheckMatcher = matcher
+	}
+
+	if targetProtocolVersion != aws.TargetProtocolVersionHTTP1 && !httpRedirectToHTTPS && (albHTTPTargetPort == 0 || albHTTPTargetPort == targetPort) {
+		return fmt.Errorf("target protocol version %s requires --redirect-http-to-https or a separate --alb-http-target-port", targetProtocolVersion)
+	}
+
+	for key, value := range map[string]string{
+		aws.TargetGroupAttributeProxyProtocolV2:  nlbProxyProtocol,
+		aws.TargetGroupAttributePreserveClientIP: nlbPreserveClientIP,
+	} {
+		if value == "" {
+			continue
+		}
+		if err := aws.SetTargetGroupAttribute(targetGroupAttributes[aws.LoadBalancerTypeNetwork], key, value, aws.LoadBalancerTypeNetwork); err != nil {
+			return fmt.Errorf("invalid %s target group attributes: %w", aws.LoadBalancerTypeNetwork, err)
+		}
+	}
+
+	attributes, err := aws.ParseLoadBalancerAttributes(albLoadBalancerAttributesFlag, aws.LoadBalancerTypeApplication)
+	if err != nil {
+		return fmt.Errorf("invalid application load balancer attributes: %w", err)
+	}
+	albLoadBalancerAttributes = attributes
+
+	headers, err := aws.ParseResponseHeaders(strings.Join(responseHeadersFlag, "\n"))
+	if err != nil {
+		return fmt.Errorf("invalid response headers: %w", err)
+	}
+	responseHeaders = headers
+
+	if maintenanceConfigMap != "" {
+		loc, err := kubernetes.ParseResourceLocation(maintenanceConfigMap)
+		if err != nil {
+			return fmt.Errorf("failed to parse maintenance config map location: %w", err)
+		}
+
+		maintenanceConfigMapLocation = loc
+	}
+
+	if capacityReservationConfigMap != "" {
+		loc, err := kubernetes.ParseResourceLocation(capacityReservationConfigMap)
+		if err != nil {
+			return fmt.Errorf("failed to parse capacity reservation config map location: %w", err)
+		}
+
+		capacityReservationLocation = loc
+	}
+
+	if fleetWeightsConfigMap != "" {
+		loc, err := kubernetes.ParseResourceLocation(fleetWeightsConfigMap)
+		if err != nil {
+			return fmt.Errorf("failed to parse fleet weights config map location: %w", err)
+		}
+
+		fleetWeightsLocation = loc
+	}
+
 	if kv := strings.Split(certFilterTag, "="); len(kv) != 2 && certFilterTag != "" {
 		log.Errorf("Certificate filter tag should be in the format \"key=value\", instead it is set to: %s", certFilterTag)
 	}
@@ -338,9 +546,12 @@ func main() {
 		WithIpAddressType(ipAddressType).
 		WithAlbLogsS3Bucket(albLogsS3Bucket).
 		WithAlbLogsS3Prefix(albLogsS3Prefix).
+		WithCABundleS3Location(caBundleS3Bucket, caBundleS3Prefix).
 		WithHTTPRedirectToHTTPS(httpRedirectToHTTPS).
 		WithNLBCrossZone(nlbCrossZone).
 		WithNLBZoneAffinity(nlbZoneAffinity).
+		WithTargetProtocolVersion(targetProtocolVersion).
+		WithHealthCheckMatcher(healthCheckMatcher).
 		WithNLBHTTPEnabled(nlbHTTPEnabled).
 		WithCustomFilter(customFilter).
 		WithStackTags(additionalStackTags).
@@ -349,7 +560,30 @@ func main() {
 		WithInternalDomainsDenyResponse(denyInternalRespBody).
 		WithInternalDomainsDenyResponseStatusCode(denyInternalRespStatusCode).
 		WithInternalDomainsDenyResponseContenType(denyInternalRespContentType).
-		WithTargetAccessMode(targetAccessMode)
+		WithSourceRangesDenyResponse(sourceRangesRespStatusCode, sourceRangesRespContentType, sourceRangesRespBody).
+		WithMaintenanceResponse(maintenanceRespStatusCode, maintenanceRespContentType, maintenanceRespBody).
+		WithTargetAccessMode(targetAccessMode).
+		WithCNITargetPorts(targetCNIService != "").
+		WithFleets(fleets)
+
+	for scheme, selector := range subnetSelectors {
+		awsAdapter.WithSubnetSelector(scheme, selector)
+	}
+
+	for loadBalancerType, attributes := range targetGroupAttributes {
+		awsAdapter.WithTargetGroupAttributes(loadBalancerType, attributes)
+	}
+	awsAdapter.WithLoadBalancerAttributes(albLoadBalancerAttributes)
+	awsAdapter.WithResponseHeaders(responseHeaders)
+
+	internalSubnets, err := awsAdapter.SelectLBSubnets(string(elbv2Types.LoadBalancerSchemeEnumInternal), "")
+	if err != nil {
+		log.Fatalf("Failed to select internal subnets: %v", err)
+	}
+	publicSubnets, err := awsAdapter.SelectLBSubnets(string(elbv2Types.LoadBalancerSchemeEnumInternetFacing), "")
+	if err != nil {
+		log.Fatalf("Failed to select public subnets: %v", err)
+	}
 
 	log.Debug("certs.NewCachingProvider")
 	certificatesProvider, err := certs.NewCachingProvider(
@@ -384,11 +618,19 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	kubeAdapter.WithLabelTags(ingressLabelTags).
+		WithNamespaceLabelTags(namespaceLabelTags).
+		WithNLBSecurityGroup(nlbSecurityGroup).
+		WithFleets(fleets)
 	if targetAccessMode == aws.TargetAccessModeAWSCNI {
 		if err = kubeAdapter.NewInclusterConfigClientset(ctx); err != nil {
 			log.Fatal(err)
 		}
-		kubeAdapter.WithTargetCNIPodSelector(targetCNINamespace, targetCNIPodLabelSelector)
+		if targetCNIService != "" {
+			kubeAdapter.WithTargetCNIService(targetCNINamespace, targetCNIService)
+		} else {
+			kubeAdapter.WithTargetCNIPodSelector(targetCNINamespace, targetCNIPodLabelSelector)
+		}
 	}
 
 	certificatesPerALB := maxCertsPerALB
@@ -401,38 +643,56 @@ func main() {
 	log.Infof("VPC ID: %s", awsAdapter.VpcID())
 	log.Infof("Instance ID: %s", awsAdapter.InstanceID())
 	log.Infof("Security group ID: %s", awsAdapter.SecurityGroupID())
-	log.Infof("Internal subnet IDs: %s", awsAdapter.FindLBSubnets(string(elbv2Types.LoadBalancerSchemeEnumInternal)))
-	log.Infof("Public subnet IDs: %s", awsAdapter.FindLBSubnets(string(elbv2Types.LoadBalancerSchemeEnumInternetFacing)))
+	log.Infof("Internal subnet IDs: %s", internalSubnets)
+	log.Infof("Public subnet IDs: %s", publicSubnets)
 	log.Infof("EC2 filters: %s", awsAdapter.FiltersString())
 	log.Infof("Certificates per ALB: %d (SNI: %t)", certificatesPerALB, certificatesPerALB > 1)
 	log.Infof("Blacklisted Certificate ARNs (%d): %s", len(blacklistCertARNs), strings.Join(blacklistCertARNs, ","))
 	log.Infof("Ingress class filters: %s", kubeAdapter.IngressFiltersString())
 	log.Infof("ALB Logging S3 Bucket: %s", awsAdapter.S3Bucket())
 	log.Infof("ALB Logging S3 Prefix: %s", awsAdapter.S3Prefix())
+	log.Infof("mTLS CA bundle S3 Bucket: %s", caBundleS3Bucket)
 	log.Infof("CloudWatch Alarm ConfigMap: %s", cwAlarmConfigMapLocation)
+	log.Infof("Maintenance ConfigMap: %s", maintenanceConfigMapLocation)
+	log.Infof("Capacity reservation ConfigMap: %s", capacityReservationLocation)
 	log.Infof("Default LoadBalancer type: %s", loadBalancerType)
 	log.Infof("Target access mode: %s", targetAccessMode)
 	log.Infof("NLB Cross Zone: %t", nlbCrossZone)
 	log.Infof("NLB Zone Affinity: %s", nlbZoneAffinity)
+	log.Infof("NLB Security Group: %t", nlbSecurityGroup)
+	log.Infof("ALB target protocol version: %s", targetProtocolVersion)
+	log.Infof("ALB health check matcher: %s", healthCheckMatcher)
+	log.Infof("Fleets: %v", fleets)
+	log.Infof("Fleet weights ConfigMap: %s", fleetWeightsLocation)
 
 	metrics := newMetrics()
 
 	go handleTerminationSignals(cancel, syscall.SIGTERM, syscall.SIGQUIT)
 	go metrics.serve(metricsAddress)
 	if awsAdapter.TargetCNI.Enabled {
-		go cniEventHandler(ctx, awsAdapter.TargetCNI, awsAdapter.SetTargetsOnCNITargetGroups, kubeAdapter.PodInformer)
+		informer, fleetInformer := kubeAdapter.PodInformer, kubeAdapter.FleetPodInformer
+		if targetCNIService != "" {
+			informer, fleetInformer = kubeAdapter.EndpointSliceInformer, kubeAdapter.FleetEndpointSliceInformer
+		}
+		go cniEventHandler(ctx, awsAdapter.TargetCNI, awsAdapter.SetTargetsOnCNITargetGroups, informer)
+		for name, targetCNI := range awsAdapter.FleetTargetCNI {
+			go cniEventHandler(ctx, targetCNI, awsAdapter.SetTargetsOnCNITargetGroups, fleetInformer(name))
+		}
 	}
 
 	w := &worker{
-		awsAdapter:         awsAdapter,
-		kubeAPI:            kubeAdapter,
-		metrics:            metrics,
-		certsProvider:      certificatesProvider,
-		certsPerALB:        certificatesPerALB,
-		certTTL:            certTTL,
-		globalWAFACL:       wafWebAclId,
-		cwAlarmConfig:      cwAlarmConfigMapLocation,
-		minLoadBalancerAge: minLoadBalancerAge,
+		awsAdapter:                awsAdapter,
+		kubeAPI:                   kubeAdapter,
+		metrics:                   metrics,
+		certsProvider:             certificatesProvider,
+		certsPerALB:               certificatesPerALB,
+		certTTL:                   certTTL,
+		globalWAFACL:              wafWebAclId,
+		cwAlarmConfig:             cwAlarmConfigMapLocation,
+		maintenanceConfig:         maintenanceConfigMapLocation,
+		capacityReservationConfig: capacityReservationLocation,
+		fleetWeightsConfig:        fleetWeightsLocation,
+		minLoadBalancerAge:        minLoadBalancerAge,
 	}
 
 	w.startPolling(ctx, pollingInterval)
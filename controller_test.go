@@ -68,12 +68,15 @@ func TestDefaultLoadSettings(t *testing.T) {
 	require.Equal(t, (*kubernetes.ResourceLocation)(nil), cwAlarmConfigMapLocation)
 	require.Equal(t, "application", loadBalancerType)
 	require.Equal(t, "any_availability_zone", nlbZoneAffinity)
+	require.Equal(t, "HTTP1", targetProtocolVersion)
+	require.Equal(t, "", healthCheckMatcher)
 	require.Equal(t, false, nlbCrossZone)
 	require.Equal(t, false, nlbHTTPEnabled)
 	require.Equal(t, "networking.k8s.io/v1", ingressAPIVersion)
 	require.Equal(t, []string{"*.cluster.local"}, internalDomains)
 	require.Equal(t, "", targetCNINamespace)
 	require.Equal(t, "", targetCNIPodLabelSelector)
+	require.Equal(t, "", targetCNIService)
 	require.Equal(t, false, denyInternalDomains)
 	require.Equal(t, "Unauthorized", denyInternalRespBody)
 	require.Equal(t, "text/plain", denyInternalRespContentType)
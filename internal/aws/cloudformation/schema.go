@@ -165,6 +165,92 @@ func (l *CloudWatchAlarmMetricStatList) UnmarshalJSON(buf []byte) error {
 	return err
 }
 
+// EC2SecurityGroupEgressProperty represents the AWS::EC2::SecurityGroup.Egress CloudFormation property type
+// See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html
+type EC2SecurityGroupEgressProperty struct {
+	// CidrIP docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-cidrip
+	CidrIP *StringExpr `json:"CidrIp,omitempty"`
+	// CidrIPv6 docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-cidripv6
+	CidrIPv6 *StringExpr `json:"CidrIpv6,omitempty"`
+	// Description docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-description
+	Description *StringExpr `json:"Description,omitempty"`
+	// DestinationPrefixListID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-destinationprefixlistid
+	DestinationPrefixListID *StringExpr `json:"DestinationPrefixListId,omitempty"`
+	// DestinationSecurityGroupID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-destinationsecuritygroupid
+	DestinationSecurityGroupID *StringExpr `json:"DestinationSecurityGroupId,omitempty"`
+	// FromPort docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-fromport
+	FromPort *IntegerExpr `json:"FromPort,omitempty"`
+	// IPProtocol docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-ipprotocol
+	IPProtocol *StringExpr `json:"IpProtocol,omitempty" validate:"dive,required"`
+	// ToPort docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-egress.html#cfn-ec2-securitygroup-egress-toport
+	ToPort *IntegerExpr `json:"ToPort,omitempty"`
+}
+
+// EC2SecurityGroupEgressPropertyList represents a list of EC2SecurityGroupEgressProperty
+type EC2SecurityGroupEgressPropertyList []EC2SecurityGroupEgressProperty
+
+// UnmarshalJSON sets the object from the provided JSON representation
+func (l *EC2SecurityGroupEgressPropertyList) UnmarshalJSON(buf []byte) error {
+	// Cloudformation allows a single object when a list of objects is expected
+	item := EC2SecurityGroupEgressProperty{}
+	if err := json.Unmarshal(buf, &item); err == nil {
+		*l = EC2SecurityGroupEgressPropertyList{item}
+		return nil
+	}
+	list := []EC2SecurityGroupEgressProperty{}
+	err := json.Unmarshal(buf, &list)
+	if err == nil {
+		*l = EC2SecurityGroupEgressPropertyList(list)
+		return nil
+	}
+	return err
+}
+
+// EC2SecurityGroupIngressProperty represents the AWS::EC2::SecurityGroup.Ingress CloudFormation property type
+// See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html
+type EC2SecurityGroupIngressProperty struct {
+	// CidrIP docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-cidrip
+	CidrIP *StringExpr `json:"CidrIp,omitempty"`
+	// CidrIPv6 docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-cidripv6
+	CidrIPv6 *StringExpr `json:"CidrIpv6,omitempty"`
+	// Description docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-description
+	Description *StringExpr `json:"Description,omitempty"`
+	// FromPort docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-fromport
+	FromPort *IntegerExpr `json:"FromPort,omitempty"`
+	// IPProtocol docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-ipprotocol
+	IPProtocol *StringExpr `json:"IpProtocol,omitempty" validate:"dive,required"`
+	// SourcePrefixListID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-sourceprefixlistid
+	SourcePrefixListID *StringExpr `json:"SourcePrefixListId,omitempty"`
+	// SourceSecurityGroupID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-sourcesecuritygroupid
+	SourceSecurityGroupID *StringExpr `json:"SourceSecurityGroupId,omitempty"`
+	// SourceSecurityGroupName docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-sourcesecuritygroupname
+	SourceSecurityGroupName *StringExpr `json:"SourceSecurityGroupName,omitempty"`
+	// SourceSecurityGroupOwnerID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-sourcesecuritygroupownerid
+	SourceSecurityGroupOwnerID *StringExpr `json:"SourceSecurityGroupOwnerId,omitempty"`
+	// ToPort docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-ec2-securitygroup-ingress.html#cfn-ec2-securitygroup-ingress-toport
+	ToPort *IntegerExpr `json:"ToPort,omitempty"`
+}
+
+// EC2SecurityGroupIngressPropertyList represents a list of EC2SecurityGroupIngressProperty
+type EC2SecurityGroupIngressPropertyList []EC2SecurityGroupIngressProperty
+
+// UnmarshalJSON sets the object from the provided JSON representation
+func (l *EC2SecurityGroupIngressPropertyList) UnmarshalJSON(buf []byte) error {
+	// Cloudformation allows a single object when a list of objects is expected
+	item := EC2SecurityGroupIngressProperty{}
+	if err := json.Unmarshal(buf, &item); err == nil {
+		*l = EC2SecurityGroupIngressPropertyList{item}
+		return nil
+	}
+	list := []EC2SecurityGroupIngressProperty{}
+	err := json.Unmarshal(buf, &list)
+	if err == nil {
+		*l = EC2SecurityGroupIngressPropertyList(list)
+		return nil
+	}
+	return err
+}
+
 // ElasticLoadBalancingV2ListenerAction represents the AWS::ElasticLoadBalancingV2::Listener.Action CloudFormation property type
 // See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-elasticloadbalancingv2-listener-action.html
 type ElasticLoadBalancingV2ListenerAction struct {
@@ -1364,6 +1450,34 @@ func (s CloudWatchAlarm) CfnResourceAttributes() []string {
 	return []string{"Arn"}
 }
 
+// EC2SecurityGroup represents the AWS::EC2::SecurityGroup CloudFormation resource type
+// See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html
+type EC2SecurityGroup struct {
+	// GroupDescription docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-groupdescription
+	GroupDescription *StringExpr `json:"GroupDescription,omitempty" validate:"dive,required"`
+	// GroupName docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-groupname
+	GroupName *StringExpr `json:"GroupName,omitempty"`
+	// SecurityGroupEgress docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-securitygroupegress
+	SecurityGroupEgress *EC2SecurityGroupEgressPropertyList `json:"SecurityGroupEgress,omitempty"`
+	// SecurityGroupIngress docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-securitygroupingress
+	SecurityGroupIngress *EC2SecurityGroupIngressPropertyList `json:"SecurityGroupIngress,omitempty"`
+	// Tags docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-tags
+	Tags *TagList `json:"Tags,omitempty"`
+	// VpcID docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-ec2-securitygroup.html#cfn-ec2-securitygroup-vpcid
+	VpcID *StringExpr `json:"VpcId,omitempty"`
+}
+
+// CfnResourceType returns AWS::EC2::SecurityGroup to implement the ResourceProperties interface
+func (s EC2SecurityGroup) CfnResourceType() string {
+
+	return "AWS::EC2::SecurityGroup"
+}
+
+// CfnResourceAttributes returns the attributes produced by this resource
+func (s EC2SecurityGroup) CfnResourceAttributes() []string {
+	return []string{"GroupId", "VpcId"}
+}
+
 // ElasticLoadBalancingV2Listener represents the AWS::ElasticLoadBalancingV2::Listener CloudFormation resource type
 // See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-listener.html
 type ElasticLoadBalancingV2Listener struct {
@@ -1538,6 +1652,32 @@ func (s ElasticLoadBalancingV2TargetGroup) CfnResourceAttributes() []string {
 	return []string{"LoadBalancerArns", "TargetGroupArn", "TargetGroupFullName", "TargetGroupName"}
 }
 
+// ElasticLoadBalancingV2TrustStore represents the AWS::ElasticLoadBalancingV2::TrustStore CloudFormation resource type
+// See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html
+type ElasticLoadBalancingV2TrustStore struct {
+	// CaCertificatesBundleS3Bucket docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html#cfn-elasticloadbalancingv2-truststore-cacertificatesbundles3bucket
+	CaCertificatesBundleS3Bucket *StringExpr `json:"CaCertificatesBundleS3Bucket,omitempty"`
+	// CaCertificatesBundleS3Key docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html#cfn-elasticloadbalancingv2-truststore-cacertificatesbundles3key
+	CaCertificatesBundleS3Key *StringExpr `json:"CaCertificatesBundleS3Key,omitempty"`
+	// CaCertificatesBundleS3ObjectVersion docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html#cfn-elasticloadbalancingv2-truststore-cacertificatesbundles3objectversion
+	CaCertificatesBundleS3ObjectVersion *StringExpr `json:"CaCertificatesBundleS3ObjectVersion,omitempty"`
+	// Name docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html#cfn-elasticloadbalancingv2-truststore-name
+	Name *StringExpr `json:"Name,omitempty"`
+	// Tags docs: http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-elasticloadbalancingv2-truststore.html#cfn-elasticloadbalancingv2-truststore-tags
+	Tags *TagList `json:"Tags,omitempty"`
+}
+
+// CfnResourceType returns AWS::ElasticLoadBalancingV2::TrustStore to implement the ResourceProperties interface
+func (s ElasticLoadBalancingV2TrustStore) CfnResourceType() string {
+
+	return "AWS::ElasticLoadBalancingV2::TrustStore"
+}
+
+// CfnResourceAttributes returns the attributes produced by this resource
+func (s ElasticLoadBalancingV2TrustStore) CfnResourceAttributes() []string {
+	return []string{"NumberOfCaCertificates", "Status", "TrustStoreArn"}
+}
+
 // WAFRegionalWebACLAssociation represents the AWS::WAFRegional::WebACLAssociation CloudFormation resource type
 // See http://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-wafregional-webaclassociation.html
 type WAFRegionalWebACLAssociation struct {
@@ -1583,6 +1723,8 @@ func NewResourceByType(typeName string) ResourceProperties {
 	switch typeName {
 	case "AWS::CloudWatch::Alarm":
 		return &CloudWatchAlarm{}
+	case "AWS::EC2::SecurityGroup":
+		return &EC2SecurityGroup{}
 	case "AWS::ElasticLoadBalancingV2::Listener":
 		return &ElasticLoadBalancingV2Listener{}
 	case "AWS::ElasticLoadBalancingV2::ListenerCertificate":
@@ -1593,6 +1735,8 @@ func NewResourceByType(typeName string) ResourceProperties {
 		return &ElasticLoadBalancingV2LoadBalancer{}
 	case "AWS::ElasticLoadBalancingV2::TargetGroup":
 		return &ElasticLoadBalancingV2TargetGroup{}
+	case "AWS::ElasticLoadBalancingV2::TrustStore":
+		return &ElasticLoadBalancingV2TrustStore{}
 	case "AWS::WAFRegional::WebACLAssociation":
 		return &WAFRegionalWebACLAssociation{}
 	case "AWS::WAFv2::WebACLAssociation":
@@ -611,11 +611,13 @@ func NewResourceByType(typeName string) ResourceProperties {
 
 var includeResourceTypes = []string{
 	"AWS::CloudWatch::Alarm",
+	"AWS::EC2::SecurityGroup",
 	"AWS::ElasticLoadBalancingV2::LoadBalancer",
 	"AWS::ElasticLoadBalancingV2::TargetGroup",
 	"AWS::ElasticLoadBalancingV2::Listener",
 	"AWS::ElasticLoadBalancingV2::ListenerRule",
 	"AWS::ElasticLoadBalancingV2::ListenerCertificate",
+	"AWS::ElasticLoadBalancingV2::TrustStore",
 	"AWS::WAFv2::WebACLAssociation",
 	"AWS::WAFRegional::WebACLAssociation",
 }
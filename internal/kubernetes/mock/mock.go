@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
 	"github.com/zalando-incubator/kube-ingress-aws-controller/kubernetes"
 
 	"github.com/stretchr/testify/mock"
@@ -27,3 +28,24 @@ func (m *API) GetConfigMap(namespace, name string) (*kubernetes.ConfigMap, error
 	args := m.Called(namespace, name)
 	return args.Get(0).(*kubernetes.ConfigMap), args.Error(1)
 }
+
+func (m *API) GetCABundle(ref string) (string, error) {
+	args := m.Called(ref)
+	return args.String(0), args.Error(1)
+}
+
+func (m *API) GetAuthConfig(ingress *kubernetes.Ingress) (*aws.AuthConfig, error) {
+	args := m.Called(ingress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*aws.AuthConfig), args.Error(1)
+}
+
+func (m *API) GetOriginHeader(ref string) (*aws.OriginHeader, error) {
+	args := m.Called(ref)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*aws.OriginHeader), args.Error(1)
+}
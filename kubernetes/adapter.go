@@ -3,7 +3,9 @@ package kubernetes
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 	log "github.com/sirupsen/logrus"
@@ -24,6 +26,19 @@ type API interface {
 
 	// GetConfigMap retrieves the ConfigMap with name from namespace.
 	GetConfigMap(namespace, name string) (*ConfigMap, error)
+
+	// GetCABundle retrieves the CA bundle referenced by an ingress
+	// for mutual TLS authentication.
+	GetCABundle(ref string) (string, error)
+
+	// GetAuthConfig retrieves the credentials referenced by an ingress
+	// for the authentication of users and returns its complete
+	// authentication config.
+	GetAuthConfig(ingress *Ingress) (*aws.AuthConfig, error)
+
+	// GetOriginHeader retrieves the origin header referenced by an
+	// ingress which the load balancer requires on all requests.
+	GetOriginHeader(ref string) (*aws.OriginHeader, error)
 }
 
 type Adapter struct {
@@ -31,6 +46,7 @@ type Adapter struct {
 	clientset                      kubernetes.Interface
 	cniPodNamespace                string
 	cniPodLabelSelector            string
+	cniService                     string
 	ingressClient                  *ingressClient
 	ingressFilters                 []string
 	ingressDefaultSecurityGroup    string
@@ -39,6 +55,10 @@ type Adapter struct {
 	ingressIpAddressType           string
 	clusterLocalDomain             string
 	routeGroupSupport              bool
+	labelTags                      []string
+	namespaceLabelTags             []string
+	nlbSecurityGroup               bool
+	fleets                         map[string]string
 }
 
 var _ API = &Adapter{}
@@ -104,6 +124,90 @@ type Ingress struct {
 	LoadBalancerType string
 	WAFWebACLID      string
 	Hostnames        []string
+	// AccessLogsS3Bucket and AccessLogsS3Prefix override the access log
+	// destination configured for the controller.
+	AccessLogsS3Bucket string
+	AccessLogsS3Prefix string
+	AccessLogsDisabled bool
+	// Tags are custom AWS tags to be added to the load balancer
+	// resources.
+	Tags map[string]string
+	// SubnetSelector is the canonical subnet selector of the load
+	// balancer, empty to use the default subnets.
+	SubnetSelector string
+	// EIPAllocations and PrivateIPv4Addresses are assigned to the subnets
+	// of a dedicated network load balancer.
+	EIPAllocations       []string
+	PrivateIPv4Addresses []string
+	// NLBCrossZone ("true" or "false") and NLBZoneAffinity override the
+	// global network load balancer settings when not empty.
+	NLBCrossZone    string
+	NLBZoneAffinity string
+	// Listeners are the canonical listeners of the load balancer, empty
+	// to use the default listeners.
+	Listeners string
+	// HTTPListenerMode overrides the HTTP listener of application load
+	// balancers when not empty, see aws.HTTPListenerModes.
+	HTTPListenerMode string
+	// TargetProtocolVersion and HealthCheckMatcher override the target
+	// groups of application load balancers when not empty, see
+	// aws.TargetProtocolVersions and aws.ParseHealthCheckMatcher.
+	TargetProtocolVersion string
+	HealthCheckMatcher    string
+	// MTLSMode is the mutual TLS mode of the HTTPS listeners, empty if
+	// mutual TLS is off. MTLSCABundleRef references the ConfigMap or
+	// Secret key with the CA bundle used to verify client
+	// certificates, see GetCABundle. MTLSCABundle is the resolved CA
+	// bundle.
+	MTLSMode        string
+	MTLSCABundleRef string
+	MTLSCABundle    string
+	// Auth is the authentication config of the HTTPS listeners of a
+	// dedicated application load balancer, nil if users are not
+	// authenticated. Its credentials are read from the Secret referenced
+	// by AuthSecretRef (<namespace>/<name>), see GetAuthConfig.
+	Auth          *aws.AuthConfig
+	AuthSecretRef string
+	// StrictHosts only forwards requests for the hostnames of the
+	// ingresses of the application load balancer.
+	StrictHosts bool
+	// SourceRanges is the canonical comma separated list of CIDRs allowed
+	// to access the load balancer, empty if not restricted.
+	SourceRanges string
+	// OriginHeaderRef references the Secret (<namespace>/<name>) with the
+	// header the load balancer requires on all requests, see
+	// GetOriginHeader. OriginHeader is the resolved header.
+	OriginHeaderRef string
+	OriginHeader    *aws.OriginHeader
+	// Maintenance makes the dedicated application load balancer respond
+	// to all requests with the maintenance response.
+	Maintenance bool
+	// Fleet is the name of the fleet serving the hosts of the ingress on
+	// the application load balancer, see aws.ParseFleets. The default
+	// targets serve them when empty.
+	Fleet string
+	// FleetWeights are the percentages of the requests not matching any
+	// host rule which the dedicated application load balancer forwards to
+	// fleets, see aws.FormatFleetWeights.
+	FleetWeights string
+	// TargetGroupAttributes override the default target group attributes
+	// of the load balancer, see aws.FormatTargetGroupAttributes.
+	TargetGroupAttributes string
+	// LoadBalancerAttributes override the default attributes of the
+	// application load balancer, see aws.FormatLoadBalancerAttributes.
+	LoadBalancerAttributes string
+	// ResponseHeaders override the default response headers added by the
+	// HTTPS listeners of the application load balancer, see
+	// aws.FormatResponseHeaders.
+	ResponseHeaders string
+	// NLBSecurityGroup attaches the SecurityGroup to a new network load
+	// balancer. HasSGAnnotation is set when the SecurityGroup of the
+	// network load balancer is configured by annotation.
+	NLBSecurityGroup bool
+	HasSGAnnotation  bool
+	// HasIPAddressTypeAnnotation is set when the IPAddressType is
+	// configured by annotation.
+	HasIPAddressTypeAnnotation bool
 }
 
 // String returns a string representation of the Ingress instance containing the type, namespace and the resource name.
@@ -203,7 +307,10 @@ func (a *Adapter) newIngress(typ IngressType, metadata kubeItemMetadata, host st
 		shared = false
 	}
 
-	ipAddressType := getAnnotationsString(annotations, ingressALBIPAddressType, a.ingressIpAddressType)
+	ipAddressType, hasIPAddressTypeAnnotation := annotations[ingressALBIPAddressType]
+	if !hasIPAddressTypeAnnotation {
+		ipAddressType = a.ingressIpAddressType
+	}
 
 	sslPolicy := getAnnotationsString(annotations, ingressSSLPolicyAnnotation, a.ingressDefaultSSLPolicy)
 	hasSSLPolicyAnnotation := false
@@ -234,11 +341,34 @@ func (a *Adapter) newIngress(typ IngressType, metadata kubeItemMetadata, host st
 	wafWebAclId, hasWAF := annotations[ingressWAFWebACLIDAnnotation]
 
 	if (loadBalancerType == loadBalancerTypeNLB) && (hasSG || hasWAF) {
-		if hasLB {
-			return nil, errors.New("security group or WAF are not supported by NLB (configured by annotation)")
+		switch {
+		case !hasLB:
+			// Security Group or WAF with NLB (default), falling back to ALB
+			// to keep the existing load balancers
+			loadBalancerType = loadBalancerTypeALB
+		case hasWAF:
+			return nil, errors.New("WAF is not supported by NLB (configured by annotation)")
+		}
+	}
+
+	eipAllocations := splitAnnotation(getAnnotationsString(annotations, ingressEIPAllocationsAnnotation, ""))
+	privateIPv4Addresses := splitAnnotation(getAnnotationsString(annotations, ingressPrivateIPv4Annotation, ""))
+	if len(eipAllocations) > 0 || len(privateIPv4Addresses) > 0 {
+		switch {
+		case len(eipAllocations) > 0 && len(privateIPv4Addresses) > 0:
+			return nil, errors.New("EIP allocations and private IPv4 addresses can't be combined")
+		case len(eipAllocations) > 0 && scheme != elbv2Types.LoadBalancerSchemeEnumInternetFacing:
+			return nil, errors.New("EIP allocations are only supported by internet-facing load balancers")
+		case len(privateIPv4Addresses) > 0 && scheme != elbv2Types.LoadBalancerSchemeEnumInternal:
+			return nil, errors.New("private IPv4 addresses are only supported by internal load balancers")
+		// subnet mappings require a dedicated NLB, which must be requested
+		// explicitly so that the type of existing load balancers doesn't
+		// change unnoticed.
+		case !hasLB || loadBalancerType != loadBalancerTypeNLB:
+			return nil, fmt.Errorf("EIP allocations or private IPv4 addresses require the %s annotation set to %q", ingressLoadBalancerTypeAnnotation, loadBalancerTypeNLB)
+		case shared:
+			return nil, fmt.Errorf("EIP allocations or private IPv4 addresses require a dedicated load balancer, set %s to false", ingressSharedAnnotation)
 		}
-		// Security Group or WAF are not supported by NLB (default), falling back to ALB
-		loadBalancerType = loadBalancerTypeALB
 	}
 
 	if _, ok := loadBalancerTypesIngressToAWS[loadBalancerType]; !ok {
@@ -248,31 +378,344 @@ func (a *Adapter) newIngress(typ IngressType, metadata kubeItemMetadata, host st
 	// convert to the internal naming e.g. nlb -> network
 	loadBalancerType = loadBalancerTypesIngressToAWS[loadBalancerType]
 
+	switch err := aws.ValidateIPAddressType(ipAddressType, loadBalancerType, string(scheme)); {
+	case err == nil:
+	case hasIPAddressTypeAnnotation:
+		return nil, fmt.Errorf("invalid IP address type annotation: %w", err)
+	case ipAddressType == aws.IPAddressTypeDualstackWithoutPublicIPv4:
+		// dualstack-without-public-ipv4 (default) is not supported by NLB
+		// and internal load balancers, falling back to dualstack
+		ipAddressType = aws.IPAddressTypeDualstack
+	}
+
 	http2 := true
 	if getAnnotationsString(annotations, ingressHTTP2Annotation, "") == "false" {
 		http2 = false
 	}
 
+	nlbCrossZone := getAnnotationsString(annotations, ingressNLBCrossZoneAnnotation, "")
+	switch nlbCrossZone {
+	case "", "true", "false":
+	default:
+		return nil, fmt.Errorf("invalid NLB cross zone annotation %q, must be true or false", nlbCrossZone)
+	}
+
+	nlbZoneAffinity := getAnnotationsString(annotations, ingressNLBZoneAffinityAnnotation, "")
+	if nlbZoneAffinity != "" && !slices.Contains(aws.ZoneAffinities, nlbZoneAffinity) {
+		return nil, fmt.Errorf("invalid NLB zone affinity annotation %q, must be one of %s", nlbZoneAffinity, strings.Join(aws.ZoneAffinities, ", "))
+	}
+
+	// values for application load balancers are ignored so that they
+	// don't affect the grouping of ingresses.
+	if loadBalancerType != aws.LoadBalancerTypeNetwork {
+		nlbCrossZone, nlbZoneAffinity = "", ""
+	}
+
+	accessLogsDisabled := false
+	if getAnnotationsString(annotations, ingressAccessLogsEnabledAnnotation, "") == "false" {
+		accessLogsDisabled = true
+	}
+
+	subnetSelector, err := aws.ParseSubnetSelector(getAnnotationsString(annotations, ingressSubnetsAnnotation, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnets annotation: %w", err)
+	}
+
+	listeners, err := aws.ParseListeners(getAnnotationsString(annotations, ingressListenersAnnotation, ""), loadBalancerType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listeners annotation: %w", err)
+	}
+
+	targetGroupAttributes, err := aws.ParseTargetGroupAttributes(getAnnotationsString(annotations, ingressTargetGroupAttributesAnnotation, ""), loadBalancerType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target group attributes annotation: %w", err)
+	}
+	for annotation, key := range map[string]string{
+		ingressNLBProxyProtocolAnnotation:    aws.TargetGroupAttributeProxyProtocolV2,
+		ingressNLBPreserveClientIPAnnotation: aws.TargetGroupAttributePreserveClientIP,
+	} {
+		if v, ok := annotations[annotation]; ok {
+			if err := aws.SetTargetGroupAttribute(targetGroupAttributes, key, v, loadBalancerType); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: %w", annotation, err)
+			}
+		}
+	}
+
+	loadBalancerAttributes, err := aws.ParseLoadBalancerAttributes(getAnnotationsString(annotations, ingressLoadBalancerAttributesAnnotation, ""), loadBalancerType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid load balancer attributes annotation: %w", err)
+	}
+
+	var httpListenerMode string
+	if loadBalancerType == aws.LoadBalancerTypeApplication {
+		if v, ok := annotations[ingressHTTPListenerAnnotation]; ok {
+			switch {
+			case !slices.Contains(aws.HTTPListenerModes, v):
+				return nil, fmt.Errorf("invalid HTTP listener annotation %q, supported are %v", v, aws.HTTPListenerModes)
+			case v == aws.HTTPListenerModeDisabled && slices.ContainsFunc(listeners, func(l aws.Listener) bool { return l.Protocol == aws.ListenerProtocolHTTP }):
+				return nil, errors.New("HTTP listener can't be disabled when HTTP listeners are configured by annotation")
+			}
+			httpListenerMode = v
+		}
+	}
+
+	var targetProtocolVersion, healthCheckMatcher string
+	if loadBalancerType == aws.LoadBalancerTypeApplication {
+		if v, ok := annotations[ingressTargetProtocolVersionAnnotation]; ok {
+			if err := aws.ValidateTargetProtocolVersion(v); err != nil {
+				return nil, fmt.Errorf("invalid target protocol version annotation: %w", err)
+			}
+			targetProtocolVersion = v
+		}
+
+		if v, ok := annotations[ingressHealthCheckMatcherAnnotation]; ok {
+			healthCheckMatcher, err = aws.ParseHealthCheckMatcher(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid health check matcher annotation: %w", err)
+			}
+		}
+	}
+
+	var mtlsMode, mtlsCABundleRef string
+	if v := getAnnotationsString(annotations, ingressMTLSModeAnnotation, aws.MTLSModeOff); v != aws.MTLSModeOff {
+		switch {
+		case !slices.Contains(aws.MTLSModes, v):
+			return nil, fmt.Errorf("invalid mTLS mode annotation %q, supported are %v", v, aws.MTLSModes)
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("mTLS is only supported by ALB")
+		}
+		mtlsMode = v
+
+		if mtlsMode == aws.MTLSModeVerify {
+			ref, ok := annotations[ingressMTLSCABundleAnnotation]
+			if !ok {
+				return nil, fmt.Errorf("mTLS mode %q requires the %s annotation", mtlsMode, ingressMTLSCABundleAnnotation)
+			}
+			if mtlsCABundleRef, err = parseCABundleRef(ref, metadata.Namespace); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var auth *aws.AuthConfig
+	var authSecretRef string
+	if name, ok := annotations[ingressAuthSecretAnnotation]; ok {
+		switch {
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("authentication is only supported by ALB")
+		case shared:
+			return nil, fmt.Errorf("authentication requires a dedicated load balancer, set %s to false", ingressSharedAnnotation)
+		case name == "":
+			return nil, fmt.Errorf("invalid %s annotation, the name of the Secret must not be empty", ingressAuthSecretAnnotation)
+		}
+
+		authType := getAnnotationsString(annotations, ingressAuthTypeAnnotation, aws.AuthTypeOIDC)
+		if !slices.Contains(aws.AuthTypes, authType) {
+			return nil, fmt.Errorf("invalid authentication type annotation %q, supported are %v", authType, aws.AuthTypes)
+		}
+
+		var sessionTimeout time.Duration
+		if v, ok := annotations[ingressAuthSessionTimeoutAnnotation]; ok {
+			if sessionTimeout, err = time.ParseDuration(v); err != nil || sessionTimeout < time.Second {
+				return nil, fmt.Errorf("invalid authentication session timeout annotation %q", v)
+			}
+		}
+
+		auth = &aws.AuthConfig{
+			Type:              authType,
+			SessionCookieName: getAnnotationsString(annotations, ingressAuthSessionCookieAnnotation, ""),
+			SessionTimeout:    int64(sessionTimeout.Seconds()),
+		}
+		authSecretRef = metadata.Namespace + "/" + name
+	}
+
+	strictHosts := getAnnotationsString(annotations, ingressStrictHostsAnnotation, "") == "true"
+	if strictHosts && loadBalancerType != aws.LoadBalancerTypeApplication {
+		return nil, errors.New("strict hosts mode is only supported by ALB")
+	}
+
+	sourceRanges, err := aws.ParseSourceRanges(getAnnotationsString(annotations, ingressSourceRangesAnnotation, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid source ranges annotation: %w", err)
+	}
+
+	// source ranges are enforced by their own security group on network
+	// load balancers
+	var nlbSecurityGroup, hasSGAnnotation bool
+	if loadBalancerType == aws.LoadBalancerTypeNetwork {
+		if hasSG && sourceRanges != "" {
+			return nil, errors.New("security group and source ranges can't be combined on NLB")
+		}
+		nlbSecurityGroup = sourceRanges == "" && (hasSG || a.nlbSecurityGroup)
+		hasSGAnnotation = hasSG
+	}
+
+	var originHeaderRef string
+	if name, ok := annotations[ingressOriginHeaderSecretAnnotation]; ok {
+		switch {
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("origin headers are only supported by ALB")
+		case name == "":
+			return nil, fmt.Errorf("invalid %s annotation, the name of the Secret must not be empty", ingressOriginHeaderSecretAnnotation)
+		}
+		originHeaderRef = metadata.Namespace + "/" + name
+	}
+
+	var responseHeaders map[string]string
+	if v, ok := annotations[ingressResponseHeadersAnnotation]; ok {
+		if loadBalancerType != aws.LoadBalancerTypeApplication {
+			return nil, errors.New("response headers are only supported by ALB")
+		}
+		responseHeaders, err = aws.ParseResponseHeaders(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response headers annotation: %w", err)
+		}
+	}
+
+	maintenance := getAnnotationsString(annotations, ingressMaintenanceAnnotation, "") == "true"
+	if maintenance {
+		switch {
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("maintenance mode is only supported by ALB")
+		case shared:
+			return nil, fmt.Errorf("maintenance mode requires a dedicated load balancer, set %s to false", ingressSharedAnnotation)
+		}
+	}
+
+	fleet := getAnnotationsString(annotations, ingressFleetAnnotation, "")
+	if fleet != "" {
+		if _, ok := a.fleets[fleet]; !ok {
+			return nil, fmt.Errorf("unknown fleet %q", fleet)
+		}
+		switch {
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("fleets are only supported by ALB")
+		case strictHosts || sourceRanges != "" || originHeaderRef != "":
+			return nil, errors.New("fleets can't be combined with strict hosts, source ranges or origin headers")
+		}
+	}
+
+	var fleetWeights string
+	if value, ok := annotations[ingressFleetWeightsAnnotation]; ok {
+		weights, err := aws.ParseFleetWeights(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fleet weights annotation: %w", err)
+		}
+		for name := range weights {
+			if _, ok := a.fleets[name]; !ok {
+				return nil, fmt.Errorf("unknown fleet %q", name)
+			}
+		}
+		switch {
+		case loadBalancerType != aws.LoadBalancerTypeApplication:
+			return nil, errors.New("fleet weights are only supported by ALB")
+		case shared:
+			return nil, fmt.Errorf("fleet weights require a dedicated load balancer, set %s to false", ingressSharedAnnotation)
+		case strictHosts || sourceRanges != "" || originHeaderRef != "":
+			return nil, errors.New("fleet weights can't be combined with strict hosts, source ranges or origin headers")
+		}
+		fleetWeights = aws.FormatFleetWeights(weights)
+	}
+
+	tags := make(map[string]string)
+	for _, label := range a.labelTags {
+		if value, ok := metadata.Labels[label]; ok {
+			tags[label] = value
+		}
+	}
+	annotationTags, err := parseTags(getAnnotationsString(annotations, ingressTagsAnnotation, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tags annotation: %w", err)
+	}
+	for key, value := range annotationTags {
+		tags[key] = value
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+
 	return &Ingress{
-		ResourceType:           typ,
-		Namespace:              metadata.Namespace,
-		Name:                   metadata.Name,
-		Hostname:               host,
-		Hostnames:              hostnames,
-		ClusterLocal:           len(hostnames) < 1,
-		CertificateARN:         getAnnotationsString(annotations, ingressCertificateARNAnnotation, ""),
-		Scheme:                 string(scheme),
-		Shared:                 shared,
-		SecurityGroup:          securityGroup,
-		SSLPolicy:              sslPolicy,
-		HasSSLPolicyAnnotation: hasSSLPolicyAnnotation,
-		IPAddressType:          ipAddressType,
-		LoadBalancerType:       loadBalancerType,
-		WAFWebACLID:            wafWebAclId,
-		HTTP2:                  http2,
+		ResourceType:               typ,
+		Namespace:                  metadata.Namespace,
+		Name:                       metadata.Name,
+		Hostname:                   host,
+		Hostnames:                  hostnames,
+		ClusterLocal:               len(hostnames) < 1,
+		CertificateARN:             getAnnotationsString(annotations, ingressCertificateARNAnnotation, ""),
+		Scheme:                     string(scheme),
+		Shared:                     shared,
+		SecurityGroup:              securityGroup,
+		SSLPolicy:                  sslPolicy,
+		HasSSLPolicyAnnotation:     hasSSLPolicyAnnotation,
+		IPAddressType:              ipAddressType,
+		LoadBalancerType:           loadBalancerType,
+		WAFWebACLID:                wafWebAclId,
+		HTTP2:                      http2,
+		AccessLogsS3Bucket:         getAnnotationsString(annotations, ingressAccessLogsS3BucketAnnotation, ""),
+		AccessLogsS3Prefix:         getAnnotationsString(annotations, ingressAccessLogsS3PrefixAnnotation, ""),
+		AccessLogsDisabled:         accessLogsDisabled,
+		Tags:                       tags,
+		SubnetSelector:             subnetSelector.String(),
+		EIPAllocations:             eipAllocations,
+		PrivateIPv4Addresses:       privateIPv4Addresses,
+		NLBCrossZone:               nlbCrossZone,
+		NLBZoneAffinity:            nlbZoneAffinity,
+		Listeners:                  aws.FormatListeners(listeners),
+		HTTPListenerMode:           httpListenerMode,
+		TargetProtocolVersion:      targetProtocolVersion,
+		HealthCheckMatcher:         healthCheckMatcher,
+		MTLSMode:                   mtlsMode,
+		MTLSCABundleRef:            mtlsCABundleRef,
+		Auth:                       auth,
+		AuthSecretRef:              authSecretRef,
+		StrictHosts:                strictHosts,
+		SourceRanges:               sourceRanges,
+		OriginHeaderRef:            originHeaderRef,
+		Maintenance:                maintenance,
+		Fleet:                      fleet,
+		FleetWeights:               fleetWeights,
+		TargetGroupAttributes:      aws.FormatTargetGroupAttributes(targetGroupAttributes),
+		LoadBalancerAttributes:     aws.FormatLoadBalancerAttributes(loadBalancerAttributes),
+		ResponseHeaders:            aws.FormatResponseHeaders(responseHeaders),
+		NLBSecurityGroup:           nlbSecurityGroup,
+		HasSGAnnotation:            hasSGAnnotation,
+		HasIPAddressTypeAnnotation: hasIPAddressTypeAnnotation,
 	}, nil
 }
 
+// splitAnnotation splits a comma separated annotation value and removes empty
+// elements. It returns nil if there are no elements.
+func splitAnnotation(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseTags parses a comma separated list of key=value pairs.
+func parseTags(value string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid tag %q, must be <key>=<value>", pair)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if err := aws.ValidateTag(key, value); err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
 // Get ingress class filters that are used to filter ingresses acted upon.
 func (a *Adapter) IngressFiltersString() string {
 	return strings.TrimSpace(strings.Join(a.ingressFilters, ","))
@@ -303,9 +746,46 @@ func (a *Adapter) ListResources() ([]*Ingress, error) {
 	}
 
 	ings = append(ings, rgs...)
+
+	if len(a.namespaceLabelTags) > 0 {
+		if err := a.addNamespaceLabelTags(ings); err != nil {
+			return nil, err
+		}
+	}
 	return ings, nil
 }
 
+// addNamespaceLabelTags adds the allowed labels of the namespaces of the
+// ingresses as tags. The tags of the ingresses take precedence.
+func (a *Adapter) addNamespaceLabelTags(ings []*Ingress) error {
+	nl, err := listNamespaces(a.kubeClient)
+	if err != nil {
+		return err
+	}
+
+	labels := make(map[string]map[string]string, len(nl.Items))
+	for _, ns := range nl.Items {
+		labels[ns.Metadata.Name] = ns.Metadata.Labels
+	}
+
+	for _, ing := range ings {
+		for _, label := range a.namespaceLabelTags {
+			value, ok := labels[ing.Namespace][label]
+			if !ok {
+				continue
+			}
+			if _, ok := ing.Tags[label]; ok {
+				continue
+			}
+			if ing.Tags == nil {
+				ing.Tags = make(map[string]string)
+			}
+			ing.Tags[label] = value
+		}
+	}
+	return nil
+}
+
 // ListIngress can be used to obtain the list of ingress resources for
 // all namespaces filtered by class. It returns the Ingress business
 // object, that for the controller does not matter to be
@@ -431,9 +911,48 @@ func (a *Adapter) GetConfigMap(namespace, name string) (*ConfigMap, error) {
 	}, nil
 }
 
+// WithLabelTags returns the receiver adapter after setting the label keys
+// whose values are added as tags to the load balancer resources.
+func (a *Adapter) WithLabelTags(labels []string) *Adapter {
+	a.labelTags = labels
+	return a
+}
+
+// WithNamespaceLabelTags returns the receiver adapter after setting the
+// label keys of namespaces whose values are added as tags to the load
+// balancer resources of the ingresses in the namespace.
+func (a *Adapter) WithNamespaceLabelTags(labels []string) *Adapter {
+	a.namespaceLabelTags = labels
+	return a
+}
+
+// WithNLBSecurityGroup returns the receiver adapter after setting whether
+// the default security group is attached to new network load balancers.
+func (a *Adapter) WithNLBSecurityGroup(enabled bool) *Adapter {
+	a.nlbSecurityGroup = enabled
+	return a
+}
+
+// WithFleets returns the receiver adapter after setting the fleets which
+// ingresses can select by annotation, see aws.ParseFleets. In the AWSCNI
+// target access mode the selectors are pod label selectors, see
+// FleetPodInformer, or target Services, see FleetEndpointSliceInformer.
+func (a *Adapter) WithFleets(fleets map[string]string) *Adapter {
+	a.fleets = fleets
+	return a
+}
+
 // WithTargetCNIPodSelector returns the receiver adapter after setting
 // the TargetCNIPodSelector config.
 func (a *Adapter) WithTargetCNIPodSelector(ns string, selector string) *Adapter {
 	a.cniPodNamespace, a.cniPodLabelSelector = ns, selector
 	return a
 }
+
+// WithTargetCNIService returns the receiver adapter after setting the
+// namespace and the target Service of the EndpointSliceInformer, see
+// ParseTargetService.
+func (a *Adapter) WithTargetCNIService(ns string, service string) *Adapter {
+	a.cniPodNamespace, a.cniService = ns, service
+	return a
+}
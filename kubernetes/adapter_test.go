@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
 )
 
@@ -30,6 +31,8 @@ func TestNewIngressFromKube(tt *testing.T) {
 	for _, tc := range []struct {
 		msg                     string
 		defaultLoadBalancerType string
+		nlbSecurityGroup        bool
+		defaultIPAddressType    string
 		ingress                 *Ingress
 		ingressError            bool
 		kubeIngress             *ingress
@@ -38,21 +41,22 @@ func TestNewIngressFromKube(tt *testing.T) {
 			msg:                     "test parsing a simple ingress object",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
-				Namespace:              "default",
-				Name:                   "foo",
-				Hostname:               "bar",
-				Scheme:                 "internal",
-				CertificateARN:         "zbr",
-				Shared:                 true,
-				HTTP2:                  true,
-				Hostnames:              []string{"domain.example.org"},
-				SecurityGroup:          testSecurityGroup,
-				SSLPolicy:              testSSLPolicy,
-				HasSSLPolicyAnnotation: true,
-				IPAddressType:          testIPAddressTypeDefault,
-				LoadBalancerType:       aws.LoadBalancerTypeApplication,
-				ResourceType:           TypeIngress,
-				WAFWebACLID:            testWAFWebACLID,
+				Namespace:                  "default",
+				Name:                       "foo",
+				Hostname:                   "bar",
+				Scheme:                     "internal",
+				CertificateARN:             "zbr",
+				Shared:                     true,
+				HTTP2:                      true,
+				Hostnames:                  []string{"domain.example.org"},
+				SecurityGroup:              testSecurityGroup,
+				SSLPolicy:                  testSSLPolicy,
+				HasSSLPolicyAnnotation:     true,
+				IPAddressType:              testIPAddressTypeDefault,
+				LoadBalancerType:           aws.LoadBalancerTypeApplication,
+				ResourceType:               TypeIngress,
+				WAFWebACLID:                testWAFWebACLID,
+				HasIPAddressTypeAnnotation: true,
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
@@ -91,21 +95,22 @@ func TestNewIngressFromKube(tt *testing.T) {
 			msg:                     "test parsing an ingress object with cluster.local domain",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
-			Namespace:              "default",
-			Name:                   "foo",
-			Hostname:               "bar",
-			Scheme:                 "internal",
-			CertificateARN:         "zbr",
-			Shared:                 true,
-			HTTP2:                  true,
-			ClusterLocal:           true,
-			SecurityGroup:          testSecurityGroup,
-			SSLPolicy:              testSSLPolicy,
-			HasSSLPolicyAnnotation: true,
-			IPAddressType:          testIPAddressTypeDefault,
-			LoadBalancerType:       aws.LoadBalancerTypeApplication,
-			ResourceType:           TypeIngress,
-			WAFWebACLID:            testWAFWebACLID,
+			Namespace:                  "default",
+			Name:                       "foo",
+			Hostname:                   "bar",
+			Scheme:                     "internal",
+			CertificateARN:             "zbr",
+			Shared:                     true,
+			HTTP2:                      true,
+			ClusterLocal:               true,
+			SecurityGroup:              testSecurityGroup,
+			SSLPolicy:                  testSSLPolicy,
+			HasSSLPolicyAnnotation:     true,
+			IPAddressType:              testIPAddressTypeDefault,
+			LoadBalancerType:           aws.LoadBalancerTypeApplication,
+			ResourceType:               TypeIngress,
+			WAFWebACLID:                testWAFWebACLID,
+			HasIPAddressTypeAnnotation: true,
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
@@ -144,21 +149,22 @@ func TestNewIngressFromKube(tt *testing.T) {
 			msg:                     "test parsing an ingress object with shared=false,h2-enabled=false annotations",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
-			Namespace:              "default",
-			Name:                   "foo",
-			Hostname:               "bar",
-			Scheme:                 "internal",
-			CertificateARN:         "zbr",
-			Shared:                 false,
-			HTTP2:                  false,
-			ClusterLocal:           true,
-			SecurityGroup:          testSecurityGroup,
-			SSLPolicy:              testSSLPolicy,
-			HasSSLPolicyAnnotation: true,
-			IPAddressType:          testIPAddressTypeDefault,
-			LoadBalancerType:       aws.LoadBalancerTypeApplication,
-			ResourceType:           TypeIngress,
-			WAFWebACLID:            testWAFWebACLID,
+			Namespace:                  "default",
+			Name:                       "foo",
+			Hostname:                   "bar",
+			Scheme:                     "internal",
+			CertificateARN:             "zbr",
+			Shared:                     false,
+			HTTP2:                      false,
+			ClusterLocal:               true,
+			SecurityGroup:              testSecurityGroup,
+			SSLPolicy:                  testSSLPolicy,
+			HasSSLPolicyAnnotation:     true,
+			IPAddressType:              testIPAddressTypeDefault,
+			LoadBalancerType:           aws.LoadBalancerTypeApplication,
+			ResourceType:               TypeIngress,
+			WAFWebACLID:                testWAFWebACLID,
+			HasIPAddressTypeAnnotation: true,
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
@@ -187,45 +193,1363 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test parsing an ingress object with dualstack annotation",
+			msg:                     "test parsing an ingress object with dualstack annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+			Namespace:                  "default",
+			Name:                       "foo",
+			Hostname:                   "bar",
+			Scheme:                     "internal",
+			CertificateARN:             "zbr",
+			Shared:                     true,
+			HTTP2:                      true,
+			ClusterLocal:               true,
+			SecurityGroup:              testSecurityGroup,
+			SSLPolicy:                  testSSLPolicy,
+			HasSSLPolicyAnnotation:     true,
+			IPAddressType:              testIPAddressTypeDualStack,
+			LoadBalancerType:           aws.LoadBalancerTypeApplication,
+			ResourceType:               TypeIngress,
+			WAFWebACLID:                testWAFWebACLID,
+			HasIPAddressTypeAnnotation: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressCertificateARNAnnotation:   "zbr",
+						ingressSchemeAnnotation:           "internal",
+						ingressSharedAnnotation:           "true",
+						ingressHTTP2Annotation:            "true",
+						ingressSecurityGroupAnnotation:    testSecurityGroup,
+						ingressSSLPolicyAnnotation:        testSSLPolicy,
+						ingressALBIPAddressType:           testIPAddressTypeDualStack,
+						ingressLoadBalancerTypeAnnotation: testLoadBalancerTypeIngress,
+						ingressWAFWebACLIDAnnotation:      testWAFWebACLID,
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: ""},
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test default NLB without annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with dualstack ip annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingress: &Ingress{
+				ResourceType:               TypeIngress,
+				Namespace:                  "default",
+				Name:                       "foo",
+				Hostname:                   "bar",
+				Scheme:                     "internet-facing",
+				Shared:                     true,
+				HTTP2:                      true,
+				ClusterLocal:               true,
+				SSLPolicy:                  testSSLPolicy,
+				IPAddressType:              aws.IPAddressTypeDualstack,
+				LoadBalancerType:           aws.LoadBalancerTypeNetwork,
+				SecurityGroup:              testIngressDefaultSecurityGroup,
+				HasIPAddressTypeAnnotation: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressALBIPAddressType: aws.IPAddressTypeDualstack,
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test ALB with dualstack ip annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:               TypeIngress,
+				Namespace:                  "default",
+				Name:                       "foo",
+				Hostname:                   "bar",
+				Scheme:                     "internet-facing",
+				Shared:                     true,
+				HTTP2:                      true,
+				ClusterLocal:               true,
+				SSLPolicy:                  testSSLPolicy,
+				IPAddressType:              aws.IPAddressTypeDualstack,
+				LoadBalancerType:           aws.LoadBalancerTypeApplication,
+				SecurityGroup:              testIngressDefaultSecurityGroup,
+				HasIPAddressTypeAnnotation: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressALBIPAddressType: aws.IPAddressTypeDualstack,
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test ALB with access log annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:       TypeIngress,
+				Namespace:          "default",
+				Name:               "foo",
+				Hostname:           "bar",
+				Scheme:             "internet-facing",
+				Shared:             true,
+				HTTP2:              true,
+				ClusterLocal:       true,
+				SSLPolicy:          testSSLPolicy,
+				IPAddressType:      aws.IPAddressTypeIPV4,
+				LoadBalancerType:   aws.LoadBalancerTypeApplication,
+				SecurityGroup:      testIngressDefaultSecurityGroup,
+				AccessLogsS3Bucket: "my-bucket",
+				AccessLogsS3Prefix: "my-prefix",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressAccessLogsS3BucketAnnotation: "my-bucket",
+						ingressAccessLogsS3PrefixAnnotation: "my-prefix",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test ALB with access logs disabled",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:       TypeIngress,
+				Namespace:          "default",
+				Name:               "foo",
+				Hostname:           "bar",
+				Scheme:             "internet-facing",
+				Shared:             true,
+				HTTP2:              true,
+				ClusterLocal:       true,
+				SSLPolicy:          testSSLPolicy,
+				IPAddressType:      aws.IPAddressTypeIPV4,
+				LoadBalancerType:   aws.LoadBalancerTypeApplication,
+				SecurityGroup:      testIngressDefaultSecurityGroup,
+				AccessLogsDisabled: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressAccessLogsEnabledAnnotation: "false",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test default NLB with security group fallbacks to ALB",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    "sg-custom",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSecurityGroupAnnotation: "sg-custom",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test default NLB with internal annotations fallbacks to ALB",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internal",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSchemeAnnotation: "internal",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test default ALB with lb type annotation nlb and internal annotation uses NLB",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internal",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSchemeAnnotation:           "internal",
+						ingressLoadBalancerTypeAnnotation: "nlb",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test default NLB with WAF fallbacks to ALB",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				WAFWebACLID:      "waf-custom",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressWAFWebACLIDAnnotation: "waf-custom",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test ALB with dualstack without public IPv4 annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:               TypeIngress,
+				Namespace:                  "default",
+				Name:                       "foo",
+				Hostname:                   "bar",
+				Scheme:                     "internet-facing",
+				Shared:                     true,
+				HTTP2:                      true,
+				ClusterLocal:               true,
+				SSLPolicy:                  testSSLPolicy,
+				IPAddressType:              aws.IPAddressTypeDualstackWithoutPublicIPv4,
+				LoadBalancerType:           aws.LoadBalancerTypeApplication,
+				SecurityGroup:              testIngressDefaultSecurityGroup,
+				HasIPAddressTypeAnnotation: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressALBIPAddressType: aws.IPAddressTypeDualstackWithoutPublicIPv4,
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with dualstack without public IPv4 annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressALBIPAddressType: aws.IPAddressTypeDualstackWithoutPublicIPv4,
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test internal ALB falls back from default dualstack without public IPv4",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			defaultIPAddressType:    aws.IPAddressTypeDualstackWithoutPublicIPv4,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internal",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeDualstack,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSchemeAnnotation: "internal",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test explicitly configured NLB with security group",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    "sg-custom",
+				NLBSecurityGroup: true,
+				HasSGAnnotation:  true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressLoadBalancerTypeAnnotation: loadBalancerTypeNLB,
+						ingressSecurityGroupAnnotation:    "sg-custom",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with default security group",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			nlbSecurityGroup:        true,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				NLBSecurityGroup: true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with source ranges ignores default security group",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			nlbSecurityGroup:        true,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				SourceRanges:     "10.0.0.0/8",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSourceRangesAnnotation: "10.0.0.0/8",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with security group and source ranges raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressLoadBalancerTypeAnnotation: loadBalancerTypeNLB,
+						ingressSecurityGroupAnnotation:    "sg-custom",
+						ingressSourceRangesAnnotation:     "10.0.0.0/8",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test ALB with subnets annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				SubnetSelector:   "tag:role=ingress,az:eu-central-1a",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSubnetsAnnotation: "az:eu-central-1a, tag:role=ingress",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid subnets annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSubnetsAnnotation: "subnet-1,az:eu-central-1a",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test listeners annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				Listeners:        "HTTP:80,HTTPS:443,HTTPS:8443",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressListenersAnnotation: "https:8443,http:80,https:443",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test listeners annotation with protocol not supported by the load balancer raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressListenersAnnotation: "HTTPS:443",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test HTTP listener annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				HTTPListenerMode: aws.HTTPListenerModeDisabled,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressHTTPListenerAnnotation: "disabled",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid HTTP listener annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressHTTPListenerAnnotation: "foo",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test disabled HTTP listener with HTTP listeners raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressHTTPListenerAnnotation: "disabled",
+						ingressListenersAnnotation:    "HTTP:8080,HTTPS:8443",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test target protocol version and health check matcher annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:          TypeIngress,
+				Namespace:             "default",
+				Name:                  "foo",
+				Hostname:              "bar",
+				Scheme:                "internet-facing",
+				Shared:                true,
+				HTTP2:                 true,
+				ClusterLocal:          true,
+				SSLPolicy:             testSSLPolicy,
+				IPAddressType:         aws.IPAddressTypeIPV4,
+				LoadBalancerType:      aws.LoadBalancerTypeApplication,
+				SecurityGroup:         testIngressDefaultSecurityGroup,
+				TargetProtocolVersion: aws.TargetProtocolVersionGRPC,
+				HealthCheckMatcher:    "0,12",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressTargetProtocolVersionAnnotation: "GRPC",
+						ingressHealthCheckMatcherAnnotation:    "0, 12",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid target protocol version annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressTargetProtocolVersionAnnotation: "grpc",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid health check matcher annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressHealthCheckMatcherAnnotation: "2xx",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test mTLS verify annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				MTLSMode:         aws.MTLSModeVerify,
+				MTLSCABundleRef:  "configmap/default/client-ca/ca.crt",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressMTLSModeAnnotation:     "verify",
+						ingressMTLSCABundleAnnotation: "configmap/client-ca",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test mTLS verify without CA bundle raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressMTLSModeAnnotation: "verify",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid mTLS mode raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressMTLSModeAnnotation: "foo",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test mTLS on NLB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressMTLSModeAnnotation: "passthrough",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test authentication annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           false,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				Auth: &aws.AuthConfig{
+					Type:              aws.AuthTypeCognito,
+					SessionCookieName: "session",
+					SessionTimeout:    43200,
+				},
+				AuthSecretRef: "default/auth",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSharedAnnotation:             "false",
+						ingressAuthSecretAnnotation:         "auth",
+						ingressAuthTypeAnnotation:           "cognito",
+						ingressAuthSessionCookieAnnotation:  "session",
+						ingressAuthSessionTimeoutAnnotation: "12h",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test authentication on shared load balancer raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressAuthSecretAnnotation: "auth",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid authentication type raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSharedAnnotation:     "false",
+						ingressAuthSecretAnnotation: "auth",
+						ingressAuthTypeAnnotation:   "saml",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid authentication session timeout raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSharedAnnotation:             "false",
+						ingressAuthSecretAnnotation:         "auth",
+						ingressAuthSessionTimeoutAnnotation: "12",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test strict hosts annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				StrictHosts:      true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressStrictHostsAnnotation: "true",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test strict hosts on NLB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressStrictHostsAnnotation: "true",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test source ranges annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				SourceRanges:     "10.0.0.0/8,2001:db8::/32",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSourceRangesAnnotation: "2001:db8::/32, 10.1.2.3/8",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid source ranges annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSourceRangesAnnotation: "10.0.0.1",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test origin header secret annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				OriginHeaderRef:  "default/origin",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressOriginHeaderSecretAnnotation: "origin",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test origin header on NLB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressOriginHeaderSecretAnnotation: "origin",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test maintenance annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           false,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				Maintenance:      true,
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSharedAnnotation:      "false",
+						ingressMaintenanceAnnotation: "true",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test maintenance on shared load balancer raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressMaintenanceAnnotation: "true",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           true,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				Fleet:            "canary",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetAnnotation: "canary",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test unknown fleet raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetAnnotation: "edge",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet on NLB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetAnnotation: "canary",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet with strict hosts raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetAnnotation:       "canary",
+						ingressStrictHostsAnnotation: "true",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet weights annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingress: &Ingress{
+				ResourceType:     TypeIngress,
+				Namespace:        "default",
+				Name:             "foo",
+				Hostname:         "bar",
+				Scheme:           "internet-facing",
+				Shared:           false,
+				HTTP2:            true,
+				ClusterLocal:     true,
+				SSLPolicy:        testSSLPolicy,
+				IPAddressType:    aws.IPAddressTypeIPV4,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				FleetWeights:     "canary=10",
+			},
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetWeightsAnnotation: " canary=10 ",
+						ingressSharedAnnotation:       "false",
+					},
+				},
+				Status: ingressStatus{
+					LoadBalancer: ingressLoadBalancerStatus{
+						Ingress: []ingressLoadBalancer{
+							{Hostname: "bar"},
+						},
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet weights on shared load balancer raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetWeightsAnnotation: "canary=10",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test fleet weights of unknown fleet raise error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetWeightsAnnotation: "edge=10",
+						ingressSharedAnnotation:       "false",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test invalid fleet weights raise error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressFleetWeightsAnnotation: "canary=101",
+						ingressSharedAnnotation:       "false",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test target group attributes annotation",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
-			Namespace:              "default",
-			Name:                   "foo",
-			Hostname:               "bar",
-			Scheme:                 "internal",
-			CertificateARN:         "zbr",
-			Shared:                 true,
-			HTTP2:                  true,
-			ClusterLocal:           true,
-			SecurityGroup:          testSecurityGroup,
-			SSLPolicy:              testSSLPolicy,
-			HasSSLPolicyAnnotation: true,
-			IPAddressType:          testIPAddressTypeDualStack,
-			LoadBalancerType:       aws.LoadBalancerTypeApplication,
-			ResourceType:           TypeIngress,
-			WAFWebACLID:            testWAFWebACLID,
+				ResourceType:          TypeIngress,
+				Namespace:             "default",
+				Name:                  "foo",
+				Hostname:              "bar",
+				Scheme:                "internet-facing",
+				Shared:                true,
+				HTTP2:                 true,
+				ClusterLocal:          true,
+				SSLPolicy:             testSSLPolicy,
+				IPAddressType:         aws.IPAddressTypeIPV4,
+				LoadBalancerType:      aws.LoadBalancerTypeApplication,
+				SecurityGroup:         testIngressDefaultSecurityGroup,
+				TargetGroupAttributes: "load_balancing.algorithm.type=least_outstanding_requests,stickiness.enabled=true",
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressCertificateARNAnnotation:   "zbr",
-						ingressSchemeAnnotation:           "internal",
-						ingressSharedAnnotation:           "true",
-						ingressHTTP2Annotation:            "true",
-						ingressSecurityGroupAnnotation:    testSecurityGroup,
-						ingressSSLPolicyAnnotation:        testSSLPolicy,
-						ingressALBIPAddressType:           testIPAddressTypeDualStack,
-						ingressLoadBalancerTypeAnnotation: testLoadBalancerTypeIngress,
-						ingressWAFWebACLIDAnnotation:      testWAFWebACLID,
+						ingressTargetGroupAttributesAnnotation: "stickiness.enabled=true, load_balancing.algorithm.type=least_outstanding_requests",
 					},
 				},
 				Status: ingressStatus{
 					LoadBalancer: ingressLoadBalancerStatus{
 						Ingress: []ingressLoadBalancer{
-							{Hostname: ""},
 							{Hostname: "bar"},
 						},
 					},
@@ -233,26 +1557,32 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test default NLB without annotations",
+			msg:                     "test NLB proxy protocol and client IP preservation annotations",
 			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
 			ingress: &Ingress{
-				ResourceType:     TypeIngress,
-				Namespace:        "default",
-				Name:             "foo",
-				Hostname:         "bar",
-				Scheme:           "internet-facing",
-				Shared:           true,
-				HTTP2:            true,
-				ClusterLocal:     true,
-				SSLPolicy:        testSSLPolicy,
-				IPAddressType:    aws.IPAddressTypeIPV4,
-				LoadBalancerType: aws.LoadBalancerTypeNetwork,
-				SecurityGroup:    testIngressDefaultSecurityGroup,
+				ResourceType:          TypeIngress,
+				Namespace:             "default",
+				Name:                  "foo",
+				Hostname:              "bar",
+				Scheme:                "internet-facing",
+				Shared:                true,
+				HTTP2:                 true,
+				ClusterLocal:          true,
+				SSLPolicy:             testSSLPolicy,
+				IPAddressType:         aws.IPAddressTypeIPV4,
+				LoadBalancerType:      aws.LoadBalancerTypeNetwork,
+				SecurityGroup:         testIngressDefaultSecurityGroup,
+				TargetGroupAttributes: "preserve_client_ip.enabled=false,proxy_protocol_v2.enabled=true,stickiness.enabled=true",
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
+					Annotations: map[string]string{
+						ingressNLBProxyProtocolAnnotation:      "true",
+						ingressNLBPreserveClientIPAnnotation:   "false",
+						ingressTargetGroupAttributesAnnotation: "stickiness.enabled=true,proxy_protocol_v2.enabled=true",
+					},
 				},
 				Status: ingressStatus{
 					LoadBalancer: ingressLoadBalancerStatus{
@@ -264,62 +1594,72 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test NLB with dualstack ip annotation",
+			msg:                     "test conflicting NLB proxy protocol annotations raise error",
 			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
-			ingress: &Ingress{
-				ResourceType:     TypeIngress,
-				Namespace:        "default",
-				Name:             "foo",
-				Hostname:         "bar",
-				Scheme:           "internet-facing",
-				Shared:           true,
-				HTTP2:            true,
-				ClusterLocal:     true,
-				SSLPolicy:        testSSLPolicy,
-				IPAddressType:    aws.IPAddressTypeDualstack,
-				LoadBalancerType: aws.LoadBalancerTypeNetwork,
-				SecurityGroup:    testIngressDefaultSecurityGroup,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressNLBProxyProtocolAnnotation:      "true",
+						ingressTargetGroupAttributesAnnotation: "proxy_protocol_v2.enabled=false",
+					},
+				},
 			},
+		},
+		{
+			msg:                     "test NLB proxy protocol annotation on ALB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressALBIPAddressType: aws.IPAddressTypeDualstack,
+						ingressNLBProxyProtocolAnnotation: "true",
 					},
 				},
-				Status: ingressStatus{
-					LoadBalancer: ingressLoadBalancerStatus{
-						Ingress: []ingressLoadBalancer{
-							{Hostname: "bar"},
-						},
+			},
+		},
+		{
+			msg:                     "test ALB target group attribute on NLB raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressTargetGroupAttributesAnnotation: "slow_start.duration_seconds=30",
 					},
 				},
 			},
 		},
 		{
-			msg:                     "test ALB with dualstack ip annotation",
+			msg:                     "test load balancer attributes annotation",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
-				ResourceType:     TypeIngress,
-				Namespace:        "default",
-				Name:             "foo",
-				Hostname:         "bar",
-				Scheme:           "internet-facing",
-				Shared:           true,
-				HTTP2:            true,
-				ClusterLocal:     true,
-				SSLPolicy:        testSSLPolicy,
-				IPAddressType:    aws.IPAddressTypeDualstack,
-				LoadBalancerType: aws.LoadBalancerTypeApplication,
-				SecurityGroup:    testIngressDefaultSecurityGroup,
+				ResourceType:           TypeIngress,
+				Namespace:              "default",
+				Name:                   "foo",
+				Hostname:               "bar",
+				Scheme:                 "internet-facing",
+				Shared:                 true,
+				HTTP2:                  true,
+				ClusterLocal:           true,
+				SSLPolicy:              testSSLPolicy,
+				IPAddressType:          aws.IPAddressTypeIPV4,
+				LoadBalancerType:       aws.LoadBalancerTypeApplication,
+				SecurityGroup:          testIngressDefaultSecurityGroup,
+				LoadBalancerAttributes: "routing.http.desync_mitigation_mode=strictest,routing.http.xff_header_processing.mode=remove",
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressALBIPAddressType: aws.IPAddressTypeDualstack,
+						ingressLoadBalancerAttributesAnnotation: "routing.http.xff_header_processing.mode=remove,routing.http.desync_mitigation_mode=strictest",
 					},
 				},
 				Status: ingressStatus{
@@ -332,8 +1672,22 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test default NLB with security group fallbacks to ALB",
+			msg:                     "test load balancer attributes annotation on NLB raises error",
 			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressLoadBalancerAttributesAnnotation: "waf.fail_open.enabled=true",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test response headers annotation",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
 				ResourceType:     TypeIngress,
 				Namespace:        "default",
@@ -346,14 +1700,15 @@ func TestNewIngressFromKube(tt *testing.T) {
 				SSLPolicy:        testSSLPolicy,
 				IPAddressType:    aws.IPAddressTypeIPV4,
 				LoadBalancerType: aws.LoadBalancerTypeApplication,
-				SecurityGroup:    "sg-custom",
+				SecurityGroup:    testIngressDefaultSecurityGroup,
+				ResponseHeaders:  "Strict-Transport-Security: max-age=31536000; includeSubDomains\nX-Content-Type-Options: nosniff",
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressSecurityGroupAnnotation: "sg-custom",
+						ingressResponseHeadersAnnotation: "x-content-type-options: nosniff\nStrict-Transport-Security: max-age=31536000; includeSubDomains\n",
 					},
 				},
 				Status: ingressStatus{
@@ -366,28 +1721,59 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test default NLB with internal annotations fallbacks to ALB",
+			msg:                     "test invalid response headers annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressResponseHeadersAnnotation: "Server: foo",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test response headers annotation on NLB raises error",
 			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressResponseHeadersAnnotation: "X-Frame-Options: DENY",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test EIP allocations require a dedicated NLB",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
 				ResourceType:     TypeIngress,
 				Namespace:        "default",
 				Name:             "foo",
 				Hostname:         "bar",
-				Scheme:           "internal",
-				Shared:           true,
+				Scheme:           "internet-facing",
+				Shared:           false,
 				HTTP2:            true,
 				ClusterLocal:     true,
 				SSLPolicy:        testSSLPolicy,
 				IPAddressType:    aws.IPAddressTypeIPV4,
-				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
 				SecurityGroup:    testIngressDefaultSecurityGroup,
+				EIPAllocations:   []string{"eipalloc-1", "eipalloc-2"},
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressSchemeAnnotation: "internal",
+						ingressLoadBalancerTypeAnnotation: loadBalancerTypeNLB,
+						ingressSharedAnnotation:           "false",
+						ingressEIPAllocationsAnnotation:   "eipalloc-1, eipalloc-2",
 					},
 				},
 				Status: ingressStatus{
@@ -400,14 +1786,73 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test default ALB with lb type annotation nlb and internal annotation uses NLB",
+			msg:                     "test private IPv4 addresses with internet-facing scheme raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressPrivateIPv4Annotation: "10.0.1.10",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test EIP allocations without NLB annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressSharedAnnotation:         "false",
+						ingressEIPAllocationsAnnotation: "eipalloc-1",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test EIP allocations on shared load balancer raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressLoadBalancerTypeAnnotation: loadBalancerTypeNLB,
+						ingressEIPAllocationsAnnotation:   "eipalloc-1",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test EIP allocations with explicitly configured ALB raises error",
 			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressLoadBalancerTypeAnnotation: loadBalancerTypeALB,
+						ingressEIPAllocationsAnnotation:   "eipalloc-1",
+					},
+				},
+			},
+		},
+		{
+			msg:                     "test NLB with cross zone and zone affinity annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
 			ingress: &Ingress{
 				ResourceType:     TypeIngress,
 				Namespace:        "default",
 				Name:             "foo",
 				Hostname:         "bar",
-				Scheme:           "internal",
+				Scheme:           "internet-facing",
 				Shared:           true,
 				HTTP2:            true,
 				ClusterLocal:     true,
@@ -415,14 +1860,16 @@ func TestNewIngressFromKube(tt *testing.T) {
 				IPAddressType:    aws.IPAddressTypeIPV4,
 				LoadBalancerType: aws.LoadBalancerTypeNetwork,
 				SecurityGroup:    testIngressDefaultSecurityGroup,
+				NLBCrossZone:     "true",
+				NLBZoneAffinity:  aws.ZoneAffinityAvailabilityZone,
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressSchemeAnnotation:           "internal",
-						ingressLoadBalancerTypeAnnotation: "nlb",
+						ingressNLBCrossZoneAnnotation:    "true",
+						ingressNLBZoneAffinityAnnotation: aws.ZoneAffinityAvailabilityZone,
 					},
 				},
 				Status: ingressStatus{
@@ -435,8 +1882,8 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test default NLB with WAF fallbacks to ALB",
-			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			msg:                     "test ALB ignores NLB annotations",
+			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
 			ingress: &Ingress{
 				ResourceType:     TypeIngress,
 				Namespace:        "default",
@@ -450,14 +1897,14 @@ func TestNewIngressFromKube(tt *testing.T) {
 				IPAddressType:    aws.IPAddressTypeIPV4,
 				LoadBalancerType: aws.LoadBalancerTypeApplication,
 				SecurityGroup:    testIngressDefaultSecurityGroup,
-				WAFWebACLID:      "waf-custom",
 			},
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressWAFWebACLIDAnnotation: "waf-custom",
+						ingressNLBCrossZoneAnnotation:    "true",
+						ingressNLBZoneAffinityAnnotation: aws.ZoneAffinityAvailabilityZone,
 					},
 				},
 				Status: ingressStatus{
@@ -470,23 +1917,29 @@ func TestNewIngressFromKube(tt *testing.T) {
 			},
 		},
 		{
-			msg:                     "test explicitly configured NLB with security group raises error",
-			defaultLoadBalancerType: aws.LoadBalancerTypeApplication,
+			msg:                     "test invalid NLB cross zone annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
 			ingressError:            true,
 			kubeIngress: &ingress{
 				Metadata: kubeItemMetadata{
 					Namespace: "default",
 					Name:      "foo",
 					Annotations: map[string]string{
-						ingressLoadBalancerTypeAnnotation: loadBalancerTypeNLB,
-						ingressSecurityGroupAnnotation:    "sg-custom",
+						ingressNLBCrossZoneAnnotation: "yes",
 					},
 				},
-				Status: ingressStatus{
-					LoadBalancer: ingressLoadBalancerStatus{
-						Ingress: []ingressLoadBalancer{
-							{Hostname: "bar"},
-						},
+			},
+		},
+		{
+			msg:                     "test invalid NLB zone affinity annotation raises error",
+			defaultLoadBalancerType: aws.LoadBalancerTypeNetwork,
+			ingressError:            true,
+			kubeIngress: &ingress{
+				Metadata: kubeItemMetadata{
+					Namespace: "default",
+					Name:      "foo",
+					Annotations: map[string]string{
+						ingressNLBZoneAffinityAnnotation: "foo",
 					},
 				},
 			},
@@ -515,10 +1968,16 @@ func TestNewIngressFromKube(tt *testing.T) {
 		},
 	} {
 		tt.Run(tc.msg, func(t *testing.T) {
-			a, err := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, tc.defaultLoadBalancerType, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+			ipAddressType := aws.DefaultIpAddressType
+			if tc.defaultIPAddressType != "" {
+				ipAddressType = tc.defaultIPAddressType
+			}
+			a, err := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, tc.defaultLoadBalancerType, DefaultClusterLocalDomain, ipAddressType, false)
 			if err != nil {
 				t.Fatalf("cannot create kubernetes adapter: %v", err)
 			}
+			a.WithNLBSecurityGroup(tc.nlbSecurityGroup).
+				WithFleets(map[string]string{"canary": "application=skipper-canary"})
 
 			got, err := a.newIngressFromKube(tc.kubeIngress)
 			if tc.ingressError {
@@ -566,6 +2025,8 @@ func (c *mockClient) get(res string) (io.ReadCloser, error) {
 		fixture = "testdata/fixture01.json"
 	case fmt.Sprintf(configMapResource, "foo-ns", "foo-name"):
 		fixture = "testdata/fixture02.json"
+	case namespaceListResource:
+		fixture = "testdata/fixture01_ns.json"
 	default:
 		return nil, fmt.Errorf("unexpected resource: %s", res)
 	}
@@ -815,6 +2276,106 @@ func TestListIngressFilterClass(t *testing.T) {
 	}
 }
 
+func TestNewIngressTags(t *testing.T) {
+	a, err := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, aws.LoadBalancerTypeApplication, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+	require.NoError(t, err)
+	a.WithLabelTags([]string{"team", "application"})
+
+	for _, tc := range []struct {
+		msg         string
+		labels      map[string]string
+		annotations map[string]string
+		expected    map[string]string
+		err         bool
+	}{
+		{
+			msg:    "no tags",
+			labels: map[string]string{"other": "foo"},
+		},
+		{
+			msg:      "tags from allowed labels",
+			labels:   map[string]string{"team": "foo", "other": "bar"},
+			expected: map[string]string{"team": "foo"},
+		},
+		{
+			msg:    "annotation takes precedence over labels",
+			labels: map[string]string{"team": "foo", "application": "bar"},
+			annotations: map[string]string{
+				ingressTagsAnnotation: "team=baz, cost-center = 1234 ,",
+			},
+			expected: map[string]string{"team": "baz", "application": "bar", "cost-center": "1234"},
+		},
+		{
+			msg:         "pair without value",
+			annotations: map[string]string{ingressTagsAnnotation: "team=baz,invalid"},
+			err:         true,
+		},
+		{
+			msg:         "empty key",
+			annotations: map[string]string{ingressTagsAnnotation: "=empty-key"},
+			err:         true,
+		},
+		{
+			msg:         "invalid characters",
+			annotations: map[string]string{ingressTagsAnnotation: "team=a;b"},
+			err:         true,
+		},
+		{
+			msg:         "value too long",
+			annotations: map[string]string{ingressTagsAnnotation: "team=" + strings.Repeat("ü", aws.MaxTagValueLength+1)},
+			err:         true,
+		},
+	} {
+		t.Run(tc.msg, func(t *testing.T) {
+			ing, err := a.newIngressFromKube(&ingress{
+				Metadata: kubeItemMetadata{
+					Namespace:   "default",
+					Name:        "foo",
+					Labels:      tc.labels,
+					Annotations: tc.annotations,
+				},
+			})
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ing.Tags)
+		})
+	}
+}
+
+func TestListResourcesNamespaceLabelTags(t *testing.T) {
+	a, err := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, aws.LoadBalancerTypeApplication, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+	require.NoError(t, err)
+	client := &mockClient{}
+	a.kubeClient = client
+	a.WithNamespaceLabelTags([]string{"team", "cost-center"})
+
+	ingresses, err := a.ListResources()
+	require.NoError(t, err)
+	require.NotEmpty(t, ingresses)
+	for _, ing := range ingresses {
+		assert.Equal(t, map[string]string{"team": "foo"}, ing.Tags, "tags of %s/%s", ing.Namespace, ing.Name)
+	}
+
+	t.Run("ingress tags take precedence", func(t *testing.T) {
+		ings := []*Ingress{
+			{Namespace: "default", Tags: map[string]string{"team": "baz"}},
+			{Namespace: "kube-system"},
+			{Namespace: "unknown"},
+		}
+		require.NoError(t, a.addNamespaceLabelTags(ings))
+		assert.Equal(t, map[string]string{"team": "baz"}, ings[0].Tags)
+		assert.Equal(t, map[string]string{"team": "teapot"}, ings[1].Tags)
+		assert.Nil(t, ings[2].Tags)
+	})
+
+	client.broken = true
+	_, err = a.ListResources()
+	assert.Error(t, err)
+}
+
 func TestWithTargetCNIPodSelector(t *testing.T) {
 	t.Run("WithTargetCNIPodSelector sets the targetPort property", func(t *testing.T) {
 		a := &Adapter{}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
+)
+
+// GetAuthConfig retrieves the credentials of the identity provider from the
+// Secret referenced by the AuthSecretRef of an Ingress and returns a copy of
+// its authentication config including them. OpenID Connect providers use the
+// keys issuer, authorizationEndpoint, tokenEndpoint, userInfoEndpoint,
+// clientID and clientSecret, Cognito user pools the keys userPoolArn,
+// userPoolClientID and userPoolDomain. The optional key scope applies to
+// both.
+func (a *Adapter) GetAuthConfig(ingress *Ingress) (*aws.AuthConfig, error) {
+	if ingress.Auth == nil {
+		return nil, fmt.Errorf("no authentication configured for %s", ingress)
+	}
+
+	namespace, name, found := strings.Cut(ingress.AuthSecretRef, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid authentication Secret reference %q", ingress.AuthSecretRef)
+	}
+
+	s, err := getSecret(a.kubeClient, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func(key string) string {
+		return strings.TrimSpace(string(s.Data[key]))
+	}
+
+	auth := *ingress.Auth
+	auth.Scope = value("scope")
+	switch auth.Type {
+	case aws.AuthTypeOIDC:
+		auth.Issuer = value("issuer")
+		auth.AuthorizationEndpoint = value("authorizationEndpoint")
+		auth.TokenEndpoint = value("tokenEndpoint")
+		auth.UserInfoEndpoint = value("userInfoEndpoint")
+		auth.ClientID = value("clientID")
+		auth.ClientSecret = value("clientSecret")
+	case aws.AuthTypeCognito:
+		auth.UserPoolArn = value("userPoolArn")
+		auth.UserPoolClientID = value("userPoolClientID")
+		auth.UserPoolDomain = value("userPoolDomain")
+	}
+
+	return &auth, nil
+}
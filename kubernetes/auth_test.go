@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
+)
+
+func TestGetAuthConfig(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/namespaces/foo-ns/secrets/oidc":
+			fmt.Fprintf(rw, `{"kind": "Secret", "data": {"issuer": %q, "authorizationEndpoint": %q, "tokenEndpoint": %q, "userInfoEndpoint": %q, "clientID": %q, "clientSecret": %q, "scope": %q}}`,
+				b64([]byte("https://idp.example.org")),
+				b64([]byte("https://idp.example.org/authorize")),
+				b64([]byte("https://idp.example.org/token")),
+				b64([]byte("https://idp.example.org/userinfo")),
+				b64([]byte("client")),
+				b64([]byte("secret\n")),
+				b64([]byte("openid email")),
+			)
+		case "/api/v1/namespaces/foo-ns/secrets/cognito":
+			fmt.Fprintf(rw, `{"kind": "Secret", "data": {"userPoolArn": %q, "userPoolClientID": %q, "userPoolDomain": %q}}`,
+				b64([]byte("arn:aws:cognito-idp:eu-central-1:123456789012:userpool/eu-central-1_foo")),
+				b64([]byte("client")),
+				b64([]byte("foo")),
+			)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(rw, "{}")
+		}
+	}))
+	defer testServer.Close()
+
+	client, _ := newSimpleClient(&Config{BaseURL: testServer.URL}, false)
+	a, _ := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, aws.LoadBalancerTypeApplication, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+	a.kubeClient = client
+
+	for _, test := range []struct {
+		name    string
+		ingress *Ingress
+		want    *aws.AuthConfig
+		err     bool
+	}{
+		{
+			name: "oidc",
+			ingress: &Ingress{
+				Auth:          &aws.AuthConfig{Type: aws.AuthTypeOIDC, SessionTimeout: 3600},
+				AuthSecretRef: "foo-ns/oidc",
+			},
+			want: &aws.AuthConfig{
+				Type:                  aws.AuthTypeOIDC,
+				Issuer:                "https://idp.example.org",
+				AuthorizationEndpoint: "https://idp.example.org/authorize",
+				TokenEndpoint:         "https://idp.example.org/token",
+				UserInfoEndpoint:      "https://idp.example.org/userinfo",
+				ClientID:              "client",
+				ClientSecret:          "secret",
+				Scope:                 "openid email",
+				SessionTimeout:        3600,
+			},
+		},
+		{
+			name: "cognito",
+			ingress: &Ingress{
+				Auth:          &aws.AuthConfig{Type: aws.AuthTypeCognito, SessionCookieName: "session"},
+				AuthSecretRef: "foo-ns/cognito",
+			},
+			want: &aws.AuthConfig{
+				Type:              aws.AuthTypeCognito,
+				UserPoolArn:       "arn:aws:cognito-idp:eu-central-1:123456789012:userpool/eu-central-1_foo",
+				UserPoolClientID:  "client",
+				UserPoolDomain:    "foo",
+				SessionCookieName: "session",
+			},
+		},
+		{
+			name: "missing secret",
+			ingress: &Ingress{
+				Auth:          &aws.AuthConfig{Type: aws.AuthTypeOIDC},
+				AuthSecretRef: "foo-ns/missing",
+			},
+			err: true,
+		},
+		{
+			name:    "no authentication",
+			ingress: &Ingress{},
+			err:     true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := a.GetAuthConfig(test.ingress)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.NotSame(t, test.ingress.Auth, got)
+		})
+	}
+}
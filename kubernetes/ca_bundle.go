@@ -0,0 +1,73 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	caBundleKindConfigMap = "configmap"
+	caBundleKindSecret    = "secret"
+	defaultCABundleKey    = "ca.crt"
+)
+
+// parseCABundleRef parses a reference to the CA bundle in a ConfigMap or
+// Secret of the namespace in the form configmap/<name>[/<key>] or
+// secret/<name>[/<key>]. The key defaults to ca.crt. It returns the
+// canonical reference <kind>/<namespace>/<name>/<key> that can be passed
+// to GetCABundle.
+func parseCABundleRef(value, namespace string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("invalid CA bundle reference %q, expected configmap/<name>[/<key>] or secret/<name>[/<key>]", value)
+	}
+
+	kind := strings.ToLower(parts[0])
+	if kind != caBundleKindConfigMap && kind != caBundleKindSecret {
+		return "", fmt.Errorf("invalid CA bundle reference %q, the kind must be %s or %s", value, caBundleKindConfigMap, caBundleKindSecret)
+	}
+
+	name, key := parts[1], defaultCABundleKey
+	if len(parts) == 3 {
+		key = parts[2]
+	}
+
+	if name == "" || key == "" {
+		return "", fmt.Errorf("invalid CA bundle reference %q, name and key must not be empty", value)
+	}
+
+	return strings.Join([]string{kind, namespace, name, key}, "/"), nil
+}
+
+// GetCABundle retrieves the PEM encoded CA bundle referenced by the
+// MTLSCABundleRef of an Ingress.
+func (a *Adapter) GetCABundle(ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 {
+		return "", fmt.Errorf("invalid CA bundle reference %q", ref)
+	}
+	kind, namespace, name, key := parts[0], parts[1], parts[2], parts[3]
+
+	switch kind {
+	case caBundleKindConfigMap:
+		cm, err := getConfigMap(a.kubeClient, namespace, name)
+		if err != nil {
+			return "", err
+		}
+		if v, ok := cm.Data[key]; ok {
+			return v, nil
+		}
+	case caBundleKindSecret:
+		s, err := getSecret(a.kubeClient, namespace, name)
+		if err != nil {
+			return "", err
+		}
+		if v, ok := s.Data[key]; ok {
+			return string(v), nil
+		}
+	default:
+		return "", fmt.Errorf("invalid CA bundle reference %q", ref)
+	}
+
+	return "", fmt.Errorf("key %q not found in %s %s/%s", key, kind, namespace, name)
+}
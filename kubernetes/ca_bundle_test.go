@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
+)
+
+func TestParseCABundleRef(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{value: "configmap/ca", want: "configmap/foo-ns/ca/ca.crt"},
+		{value: "configmap/ca/bundle.pem", want: "configmap/foo-ns/ca/bundle.pem"},
+		{value: "Secret/ca", want: "secret/foo-ns/ca/ca.crt"},
+		{value: " secret/ca/tls.crt ", want: "secret/foo-ns/ca/tls.crt"},
+		{value: "", err: true},
+		{value: "ca", err: true},
+		{value: "configmap/", err: true},
+		{value: "configmap/ca/", err: true},
+		{value: "configmap/ca/ca.crt/foo", err: true},
+		{value: "pod/ca", err: true},
+	} {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseCABundleRef(test.value, "foo-ns")
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestGetCABundle(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/namespaces/foo-ns/configmaps/ca":
+			fmt.Fprintln(rw, `{"kind": "ConfigMap", "data": {"ca.crt": "configmap-bundle"}}`)
+		case "/api/v1/namespaces/foo-ns/secrets/ca":
+			// base64 of "secret-bundle"
+			fmt.Fprintln(rw, `{"kind": "Secret", "data": {"ca.crt": "c2VjcmV0LWJ1bmRsZQ=="}}`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(rw, "{}")
+		}
+	}))
+	defer testServer.Close()
+
+	client, _ := newSimpleClient(&Config{BaseURL: testServer.URL}, false)
+	a, _ := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, aws.LoadBalancerTypeApplication, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+	a.kubeClient = client
+
+	for _, test := range []struct {
+		ref  string
+		want string
+		err  bool
+	}{
+		{ref: "configmap/foo-ns/ca/ca.crt", want: "configmap-bundle"},
+		{ref: "secret/foo-ns/ca/ca.crt", want: "secret-bundle"},
+		{ref: "configmap/foo-ns/ca/missing", err: true},
+		{ref: "secret/foo-ns/ca/missing", err: true},
+		{ref: "configmap/foo-ns/missing/ca.crt", err: true},
+		{ref: "configmap/ca", err: true},
+		{ref: "pod/foo-ns/ca/ca.crt", err: true},
+	} {
+		t.Run(test.ref, func(t *testing.T) {
+			got, err := a.GetCABundle(test.ref)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apisv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EndpointSliceInformer is the alternative to the PodInformer which follows
+// the EndpointSlices of the target Service instead of pods, so that the
+// selector and the readiness of the Service decide which targets get
+// traffic. It sends the ready endpoints with their port, like 10.2.3.4:9999,
+// to the endpoint channel.
+func (a *Adapter) EndpointSliceInformer(ctx context.Context, endpointChan chan<- []string) error {
+	return a.endpointSliceInformer(ctx, a.cniService, endpointChan)
+}
+
+// FleetEndpointSliceInformer returns the EndpointSliceInformer of the fleet,
+// which follows the Service selected by the fleet in the same namespace.
+func (a *Adapter) FleetEndpointSliceInformer(name string) func(context.Context, chan<- []string) error {
+	return func(ctx context.Context, endpointChan chan<- []string) error {
+		return a.endpointSliceInformer(ctx, a.fleets[name], endpointChan)
+	}
+}
+
+// ParseTargetService parses a target Service of the form <name>[:<port name>]
+// and returns the name and port name of the Service. Without a port name the
+// first port of the EndpointSlices is used.
+func ParseTargetService(value string) (string, string, error) {
+	name, portName, _ := strings.Cut(strings.TrimSpace(value), ":")
+	if name == "" {
+		return "", "", fmt.Errorf("invalid target service %q, must be <name>[:<port name>]", value)
+	}
+	return name, portName, nil
+}
+
+func (a *Adapter) endpointSliceInformer(ctx context.Context, service string, endpointChan chan<- []string) (err error) {
+	name, portName, err := ParseTargetService(service)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Watching for EndpointSlices of Service %s in namespace %s", name, a.cniPodNamespace)
+	factory := informers.NewSharedInformerFactoryWithOptions(a.clientset, resyncInterval, informers.WithNamespace(a.cniPodNamespace),
+		informers.WithTweakListOptions(func(options *apisv1.ListOptions) {
+			options.LabelSelector = discoveryv1.LabelServiceName + "=" + name
+		}))
+
+	informer := factory.Discovery().V1().EndpointSlices().Informer()
+	lister := factory.Discovery().V1().EndpointSlices().Lister()
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("timed out waiting for caches to sync")
+	}
+
+	// list verifies whether EndpointSlices of the Service can be found, preventing to fail silently
+	var endpointSlices []*discoveryv1.EndpointSlice
+	for {
+		endpointSlices, err = lister.List(labels.Everything())
+		if err == nil && len(endpointSlices) > 0 {
+			break
+		}
+		log.Errorf("Error listing EndpointSlices of Service %s in namespace %s: %v", name, a.cniPodNamespace, err)
+		time.Sleep(resyncInterval)
+	}
+	endpoints := readyEndpoints(endpointSlices, portName)
+	endpointChan <- endpoints
+
+	// every event of the Service's EndpointSlices triggers a resync of the
+	// targets if the ready endpoints changed
+	queue := func() {
+		endpointSlices, err := lister.List(labels.Everything())
+		if err != nil {
+			log.Errorf("Error listing EndpointSlices of Service %s in namespace %s: %v", name, a.cniPodNamespace, err)
+			return
+		}
+		if current := readyEndpoints(endpointSlices, portName); !slices.Equal(current, endpoints) {
+			log.Infof("Ready endpoints of Service %s changed: %v", name, current)
+			endpoints = current
+			endpointChan <- endpoints
+		}
+	}
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { queue() },
+		UpdateFunc: func(_, _ interface{}) { queue() },
+		DeleteFunc: func(interface{}) { queue() },
+	})
+	if err != nil {
+		log.Errorf("Error adding event handler to informer: %v", err)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// readyEndpoints returns the sorted IPv4 addresses of the ready endpoints of
+// the EndpointSlices joined with the port of the port name. If no endpoint
+// is ready, it returns the endpoints which are terminating but still serving,
+// like kube-proxy does, so that requests are not dropped while all endpoints
+// are replaced.
+func readyEndpoints(endpointSlices []*discoveryv1.EndpointSlice, portName string) []string {
+	ready, terminating := []string{}, []string{}
+	for _, endpointSlice := range endpointSlices {
+		if endpointSlice.AddressType != discoveryv1.AddressTypeIPv4 {
+			continue
+		}
+		port, ok := endpointSlicePort(endpointSlice, portName)
+		if !ok {
+			continue
+		}
+		for _, endpoint := range endpointSlice.Endpoints {
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			// the addresses of an endpoint are fungible, the first one is used
+			target := net.JoinHostPort(endpoint.Addresses[0], strconv.Itoa(int(port)))
+			switch {
+			case isEndpointTerminating(endpoint):
+				if isEndpointServing(endpoint) {
+					terminating = append(terminating, target)
+				}
+			case isEndpointReady(endpoint):
+				ready = append(ready, target)
+			}
+		}
+	}
+	if len(ready) == 0 {
+		ready = terminating
+	}
+	slices.Sort(ready)
+	return slices.Compact(ready)
+}
+
+// endpointSlicePort returns the port of the port name, or the first port
+// without a port name.
+func endpointSlicePort(endpointSlice *discoveryv1.EndpointSlice, portName string) (int32, bool) {
+	for _, port := range endpointSlice.Ports {
+		if port.Port == nil {
+			continue
+		}
+		if portName == "" || (port.Name != nil && *port.Name == portName) {
+			return *port.Port, true
+		}
+	}
+	return 0, false
+}
+
+// the conditions of endpoints, an unknown state is interpreted as
+// recommended by https://kubernetes.io/docs/reference/kubernetes-api/service-resources/endpoint-slice-v1/
+func isEndpointReady(e discoveryv1.Endpoint) bool {
+	return e.Conditions.Ready == nil || *e.Conditions.Ready
+}
+
+func isEndpointServing(e discoveryv1.Endpoint) bool {
+	if e.Conditions.Serving == nil {
+		return isEndpointReady(e)
+	}
+	return *e.Conditions.Serving
+}
+
+func isEndpointTerminating(e discoveryv1.Endpoint) bool {
+	return e.Conditions.Terminating != nil && *e.Conditions.Terminating
+}
@@ -0,0 +1,202 @@
+//go:build !race
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+func testEndpoint(ip string, ready, serving, terminating bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses: []string{ip},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready:       ptrTo(ready),
+			Serving:     ptrTo(serving),
+			Terminating: ptrTo(terminating),
+		},
+	}
+}
+
+func testEndpointSlice(name string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{discoveryv1.LabelServiceName: "skipper-ingress"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports: []discoveryv1.EndpointPort{
+			{Name: ptrTo("metrics"), Port: ptrTo(int32(9911))},
+			{Name: ptrTo("http"), Port: ptrTo(int32(9999))},
+		},
+		Endpoints: endpoints,
+	}
+}
+
+// The fake client fails under race tests https://github.com/kubernetes/kubernetes/issues/95372
+func TestAdapter_EndpointSliceInformer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	a := Adapter{clientset: client}
+	a.WithTargetCNIService("kube-system", "skipper-ingress:http")
+
+	endpoints := make(chan []string, 10)
+	receive := func(t *testing.T, want []string) {
+		require.Eventually(t, func() bool {
+			got := <-endpoints
+			t.Logf("Got endpoints from channel: %s", got)
+			return reflect.DeepEqual(want, got)
+		}, wait.ForeverTestTimeout, 200*time.Millisecond)
+	}
+
+	t.Run("initial state of two ready endpoints, a terminating and an unready one", func(t *testing.T) {
+		_, err := client.DiscoveryV1().EndpointSlices("kube-system").Create(context.Background(), testEndpointSlice("skipper-ingress-a",
+			testEndpoint("1.1.1.1", true, true, false),
+			testEndpoint("1.1.1.2", true, true, false),
+			testEndpoint("1.1.1.3", false, true, true),
+			testEndpoint("1.1.1.4", false, false, false),
+		), metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		other := testEndpointSlice("other", testEndpoint("9.9.9.9", true, true, false))
+		other.Labels[discoveryv1.LabelServiceName] = "other"
+		_, err = client.DiscoveryV1().EndpointSlices("kube-system").Create(context.Background(), other, metav1.CreateOptions{})
+		require.NoError(t, err)
+	})
+
+	go func() {
+		err := a.EndpointSliceInformer(context.Background(), endpoints)
+		require.NoError(t, err)
+	}()
+
+	t.Run("receiving the ready endpoints on the named port", func(t *testing.T) {
+		receive(t, []string{"1.1.1.1:9999", "1.1.1.2:9999"})
+	})
+
+	t.Run("another EndpointSlice and a terminating endpoint trigger an updated list", func(t *testing.T) {
+		_, err := client.DiscoveryV1().EndpointSlices("kube-system").Create(context.Background(), testEndpointSlice("skipper-ingress-b",
+			testEndpoint("1.1.1.5", true, true, false),
+		), metav1.CreateOptions{})
+		require.NoError(t, err)
+		receive(t, []string{"1.1.1.1:9999", "1.1.1.2:9999", "1.1.1.5:9999"})
+
+		_, err = client.DiscoveryV1().EndpointSlices("kube-system").Update(context.Background(), testEndpointSlice("skipper-ingress-a",
+			testEndpoint("1.1.1.1", true, true, false),
+			testEndpoint("1.1.1.2", false, true, true),
+		), metav1.UpdateOptions{})
+		require.NoError(t, err)
+		receive(t, []string{"1.1.1.1:9999", "1.1.1.5:9999"})
+	})
+
+	t.Run("deleting an EndpointSlice triggers an updated list", func(t *testing.T) {
+		require.NoError(t, client.DiscoveryV1().EndpointSlices("kube-system").Delete(context.Background(), "skipper-ingress-b", metav1.DeleteOptions{}))
+		receive(t, []string{"1.1.1.1:9999"})
+	})
+}
+
+func TestReadyEndpoints(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		endpointSlices []*discoveryv1.EndpointSlice
+		portName       string
+		want           []string
+	}{
+		{
+			name:     "no EndpointSlices",
+			portName: "http",
+			want:     []string{},
+		},
+		{
+			name: "ready endpoints on the first port without a port name",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				testEndpointSlice("a", testEndpoint("1.1.1.2", true, true, false), testEndpoint("1.1.1.1", true, true, false)),
+				testEndpointSlice("b", testEndpoint("1.1.1.1", true, true, false)),
+			},
+			want: []string{"1.1.1.1:9911", "1.1.1.2:9911"},
+		},
+		{
+			name: "unknown conditions are ready",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				testEndpointSlice("a", discoveryv1.Endpoint{Addresses: []string{"1.1.1.1"}}),
+			},
+			portName: "http",
+			want:     []string{"1.1.1.1:9999"},
+		},
+		{
+			name: "unready and terminating endpoints are skipped",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				testEndpointSlice("a",
+					testEndpoint("1.1.1.1", true, true, false),
+					testEndpoint("1.1.1.2", false, false, false),
+					testEndpoint("1.1.1.3", false, true, true),
+					testEndpoint("1.1.1.4", false, false, true),
+				),
+			},
+			portName: "http",
+			want:     []string{"1.1.1.1:9999"},
+		},
+		{
+			name: "serving terminating endpoints without ready endpoints",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				testEndpointSlice("a",
+					testEndpoint("1.1.1.1", false, false, false),
+					testEndpoint("1.1.1.2", false, true, true),
+					testEndpoint("1.1.1.3", false, false, true),
+				),
+			},
+			portName: "http",
+			want:     []string{"1.1.1.2:9999"},
+		},
+		{
+			name: "unknown port name",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				testEndpointSlice("a", testEndpoint("1.1.1.1", true, true, false)),
+			},
+			portName: "https",
+			want:     []string{},
+		},
+		{
+			name: "IPv6 EndpointSlices are skipped",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				func() *discoveryv1.EndpointSlice {
+					s := testEndpointSlice("a", testEndpoint("2001:db8::1", true, true, false))
+					s.AddressType = discoveryv1.AddressTypeIPv6
+					return s
+				}(),
+			},
+			portName: "http",
+			want:     []string{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, readyEndpoints(test.endpointSlices, test.portName))
+		})
+	}
+}
+
+func TestParseTargetService(t *testing.T) {
+	name, portName, err := ParseTargetService("skipper-ingress:http")
+	require.NoError(t, err)
+	assert.Equal(t, "skipper-ingress", name)
+	assert.Equal(t, "http", portName)
+
+	name, portName, err = ParseTargetService(" skipper-ingress ")
+	require.NoError(t, err)
+	assert.Equal(t, "skipper-ingress", name)
+	assert.Equal(t, "", portName)
+
+	_, _, err = ParseTargetService(":http")
+	assert.Error(t, err)
+}
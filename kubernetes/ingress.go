@@ -61,20 +61,50 @@ type ingressLoadBalancer struct {
 
 const (
 	// ingressALBIPAddressType is used in external-dns, https://github.com/kubernetes-incubator/external-dns/pull/1079
-	ingressALBIPAddressType           = "alb.ingress.kubernetes.io/ip-address-type"
-	IngressAPIVersionExtensions       = "extensions/v1beta1"
-	IngressAPIVersionNetworking       = "networking.k8s.io/v1"
-	ingressListResource               = "/apis/%s/ingresses"
-	ingressPatchStatusResource        = "/apis/%s/namespaces/%s/ingresses/%s/status"
-	ingressCertificateARNAnnotation   = "zalando.org/aws-load-balancer-ssl-cert"
-	ingressSchemeAnnotation           = "zalando.org/aws-load-balancer-scheme"
-	ingressSharedAnnotation           = "zalando.org/aws-load-balancer-shared"
-	ingressSecurityGroupAnnotation    = "zalando.org/aws-load-balancer-security-group"
-	ingressSSLPolicyAnnotation        = "zalando.org/aws-load-balancer-ssl-policy"
-	ingressLoadBalancerTypeAnnotation = "zalando.org/aws-load-balancer-type"
-	ingressHTTP2Annotation            = "zalando.org/aws-load-balancer-http2"
-	ingressWAFWebACLIDAnnotation      = "zalando.org/aws-waf-web-acl-id"
-	ingressClassAnnotation            = "kubernetes.io/ingress.class"
+	ingressALBIPAddressType                 = "alb.ingress.kubernetes.io/ip-address-type"
+	IngressAPIVersionExtensions             = "extensions/v1beta1"
+	IngressAPIVersionNetworking             = "networking.k8s.io/v1"
+	ingressListResource                     = "/apis/%s/ingresses"
+	ingressPatchStatusResource              = "/apis/%s/namespaces/%s/ingresses/%s/status"
+	ingressCertificateARNAnnotation         = "zalando.org/aws-load-balancer-ssl-cert"
+	ingressSchemeAnnotation                 = "zalando.org/aws-load-balancer-scheme"
+	ingressSharedAnnotation                 = "zalando.org/aws-load-balancer-shared"
+	ingressSecurityGroupAnnotation          = "zalando.org/aws-load-balancer-security-group"
+	ingressSSLPolicyAnnotation              = "zalando.org/aws-load-balancer-ssl-policy"
+	ingressLoadBalancerTypeAnnotation       = "zalando.org/aws-load-balancer-type"
+	ingressHTTP2Annotation                  = "zalando.org/aws-load-balancer-http2"
+	ingressWAFWebACLIDAnnotation            = "zalando.org/aws-waf-web-acl-id"
+	ingressAccessLogsS3BucketAnnotation     = "zalando.org/aws-load-balancer-access-logs-s3-bucket"
+	ingressAccessLogsS3PrefixAnnotation     = "zalando.org/aws-load-balancer-access-logs-s3-prefix"
+	ingressAccessLogsEnabledAnnotation      = "zalando.org/aws-load-balancer-access-logs-enabled"
+	ingressTagsAnnotation                   = "zalando.org/aws-load-balancer-tags"
+	ingressSubnetsAnnotation                = "zalando.org/aws-load-balancer-subnets"
+	ingressEIPAllocationsAnnotation         = "zalando.org/aws-load-balancer-eip-allocations"
+	ingressPrivateIPv4Annotation            = "zalando.org/aws-load-balancer-private-ipv4-addresses"
+	ingressNLBCrossZoneAnnotation           = "zalando.org/aws-load-balancer-nlb-cross-zone"
+	ingressNLBZoneAffinityAnnotation        = "zalando.org/aws-load-balancer-nlb-zone-affinity"
+	ingressListenersAnnotation              = "zalando.org/aws-load-balancer-listeners"
+	ingressHTTPListenerAnnotation           = "zalando.org/aws-load-balancer-http-listener"
+	ingressTargetProtocolVersionAnnotation  = "zalando.org/aws-load-balancer-target-protocol-version"
+	ingressHealthCheckMatcherAnnotation     = "zalando.org/aws-load-balancer-health-check-matcher"
+	ingressMTLSModeAnnotation               = "zalando.org/aws-load-balancer-mtls-mode"
+	ingressMTLSCABundleAnnotation           = "zalando.org/aws-load-balancer-mtls-ca-bundle"
+	ingressAuthSecretAnnotation             = "zalando.org/aws-load-balancer-auth-secret"
+	ingressAuthTypeAnnotation               = "zalando.org/aws-load-balancer-auth-type"
+	ingressAuthSessionCookieAnnotation      = "zalando.org/aws-load-balancer-auth-session-cookie"
+	ingressAuthSessionTimeoutAnnotation     = "zalando.org/aws-load-balancer-auth-session-timeout"
+	ingressStrictHostsAnnotation            = "zalando.org/aws-load-balancer-strict-hosts"
+	ingressSourceRangesAnnotation           = "zalando.org/aws-load-balancer-source-ranges"
+	ingressOriginHeaderSecretAnnotation     = "zalando.org/aws-load-balancer-origin-header-secret"
+	ingressMaintenanceAnnotation            = "zalando.org/aws-load-balancer-maintenance"
+	ingressTargetGroupAttributesAnnotation  = "zalando.org/aws-load-balancer-target-group-attributes"
+	ingressLoadBalancerAttributesAnnotation = "zalando.org/aws-load-balancer-attributes"
+	ingressResponseHeadersAnnotation        = "zalando.org/aws-load-balancer-response-headers"
+	ingressNLBProxyProtocolAnnotation       = "zalando.org/aws-load-balancer-nlb-proxy-protocol"
+	ingressNLBPreserveClientIPAnnotation    = "zalando.org/aws-load-balancer-nlb-preserve-client-ip"
+	ingressFleetAnnotation                  = "zalando.org/aws-load-balancer-fleet"
+	ingressFleetWeightsAnnotation           = "zalando.org/aws-load-balancer-fleet-weights"
+	ingressClassAnnotation                  = "kubernetes.io/ingress.class"
 )
 
 func getAnnotationsString(annotations map[string]string, key string, defaultValue string) string {
@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	namespaceListResource = "/api/v1/namespaces"
+)
+
+type namespace struct {
+	Metadata kubeItemMetadata `json:"metadata"`
+}
+
+type namespaceList struct {
+	Kind       string       `json:"kind"`
+	APIVersion string       `json:"apiVersion"`
+	Items      []*namespace `json:"items"`
+}
+
+func listNamespaces(c client) (*namespaceList, error) {
+	r, err := c.get(namespaceListResource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Namespaces: %w", err)
+	}
+
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Namespaces: %w", err)
+	}
+
+	var result namespaceList
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Namespaces: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
+)
+
+// GetOriginHeader retrieves the origin header from the Secret referenced by
+// the OriginHeaderRef of an Ingress. The Secret contains the header name in
+// the key name, its value in the key value and optionally the value it
+// replaces during a rotation in the key previousValue.
+func (a *Adapter) GetOriginHeader(ref string) (*aws.OriginHeader, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid origin header Secret reference %q", ref)
+	}
+
+	s, err := getSecret(a.kubeClient, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func(key string) string {
+		return strings.TrimSpace(string(s.Data[key]))
+	}
+
+	return &aws.OriginHeader{
+		Name:          value("name"),
+		Value:         value("value"),
+		PreviousValue: value("previousValue"),
+	}, nil
+}
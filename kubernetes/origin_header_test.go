@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
+)
+
+func TestGetOriginHeader(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/namespaces/foo-ns/secrets/origin":
+			fmt.Fprintf(rw, `{"kind": "Secret", "data": {"name": %q, "value": %q}}`,
+				b64([]byte("X-Origin-Secret")),
+				b64([]byte("secret\n")),
+			)
+		case "/api/v1/namespaces/foo-ns/secrets/rotating":
+			fmt.Fprintf(rw, `{"kind": "Secret", "data": {"name": %q, "value": %q, "previousValue": %q}}`,
+				b64([]byte("X-Origin-Secret")),
+				b64([]byte("new")),
+				b64([]byte("old")),
+			)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(rw, "{}")
+		}
+	}))
+	defer testServer.Close()
+
+	client, _ := newSimpleClient(&Config{BaseURL: testServer.URL}, false)
+	a, _ := NewAdapter(testConfig, IngressAPIVersionNetworking, testIngressFilter, testIngressDefaultSecurityGroup, testSSLPolicy, aws.LoadBalancerTypeApplication, DefaultClusterLocalDomain, aws.DefaultIpAddressType, false)
+	a.kubeClient = client
+
+	for _, test := range []struct {
+		name string
+		ref  string
+		want *aws.OriginHeader
+		err  bool
+	}{
+		{
+			name: "origin header",
+			ref:  "foo-ns/origin",
+			want: &aws.OriginHeader{Name: "X-Origin-Secret", Value: "secret"},
+		},
+		{
+			name: "origin header with previous value",
+			ref:  "foo-ns/rotating",
+			want: &aws.OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"},
+		},
+		{
+			name: "missing secret",
+			ref:  "foo-ns/missing",
+			err:  true,
+		},
+		{
+			name: "invalid reference",
+			ref:  "origin",
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := a.GetOriginHeader(test.ref)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
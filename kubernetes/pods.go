@@ -19,12 +19,24 @@ const resyncInterval = 1 * time.Minute
 
 // PodInformer is a event handler for Pod events registered to, that builds a local list of valid and relevant pods
 // and sends an event to the endpoint channel, triggering a resync of the targets.
-func (a *Adapter) PodInformer(ctx context.Context, endpointChan chan<- []string) (err error) {
+func (a *Adapter) PodInformer(ctx context.Context, endpointChan chan<- []string) error {
+	return a.podInformer(ctx, a.cniPodLabelSelector, endpointChan)
+}
+
+// FleetPodInformer returns the PodInformer of the pods of the fleet, which
+// are selected by the label selector of the fleet in the same namespace.
+func (a *Adapter) FleetPodInformer(name string) func(context.Context, chan<- []string) error {
+	return func(ctx context.Context, endpointChan chan<- []string) error {
+		return a.podInformer(ctx, a.fleets[name], endpointChan)
+	}
+}
+
+func (a *Adapter) podInformer(ctx context.Context, labelSelector string, endpointChan chan<- []string) (err error) {
 	podEndpoints := sync.Map{}
 
-	log.Infof("Watching for Pods with labelselector %s in namespace %s", a.cniPodLabelSelector, a.cniPodNamespace)
+	log.Infof("Watching for Pods with labelselector %s in namespace %s", labelSelector, a.cniPodNamespace)
 	factory := informers.NewSharedInformerFactoryWithOptions(a.clientset, resyncInterval, informers.WithNamespace(a.cniPodNamespace),
-		informers.WithTweakListOptions(func(options *apisv1.ListOptions) { options.LabelSelector = a.cniPodLabelSelector }))
+		informers.WithTweakListOptions(func(options *apisv1.ListOptions) { options.LabelSelector = labelSelector }))
 
 	informer := factory.Core().V1().Pods().Informer()
 	factory.Start(ctx.Done())
@@ -39,7 +51,7 @@ func (a *Adapter) PodInformer(ctx context.Context, endpointChan chan<- []string)
 		if err == nil && len(podList) > 0 {
 			break
 		}
-		log.Errorf("Error listing Pods with labelselector %s in namespace %s: %v", a.cniPodNamespace, a.cniPodLabelSelector, err)
+		log.Errorf("Error listing Pods with labelselector %s in namespace %s: %v", labelSelector, a.cniPodNamespace, err)
 		time.Sleep(resyncInterval)
 	}
 	for _, pod := range podList {
@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	secretResource = "/api/v1/namespaces/%s/secrets/%s"
+)
+
+type secret struct {
+	Kind       string            `json:"kind"`
+	APIVersion string            `json:"apiVersion"`
+	Metadata   configMapMetadata `json:"metadata"`
+	Data       map[string][]byte `json:"data"`
+}
+
+func getSecret(c client, namespace, name string) (*secret, error) {
+	resource := fmt.Sprintf(secretResource, namespace, name)
+
+	r, err := c.get(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, name, err)
+	}
+
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Secret %s/%s: %w", namespace, name, err)
+	}
+
+	var result secret
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Secret %s/%s: %w", namespace, name, err)
+	}
+
+	return &result, nil
+}
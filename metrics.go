@@ -19,6 +19,8 @@ type metrics struct {
 	standaloneInstancesTotal       prometheus.Gauge
 	certificatesTotal              prometheus.Gauge
 	cloudWatchAlarmsTotal          prometheus.Gauge
+	reservedCapacityUnits          *prometheus.GaugeVec
+	fleetWeight                    *prometheus.GaugeVec
 	changesTotal                   changeCounter
 }
 
@@ -104,6 +106,24 @@ func newMetrics() *metrics {
 				Help:      "Number of Cloud Watch Alarms",
 			},
 		),
+		reservedCapacityUnits: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "kube_ingress_aws",
+				Subsystem: "controller",
+				Name:      "reserved_capacity_units",
+				Help:      "Minimum capacity units reserved for the load balancer of the Cloud Formation stack",
+			},
+			[]string{"stack"},
+		),
+		fleetWeight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "kube_ingress_aws",
+				Subsystem: "controller",
+				Name:      "fleet_weight",
+				Help:      "Percentage of the requests forwarded to the fleet by the load balancer of the Cloud Formation stack",
+			},
+			[]string{"stack", "fleet"},
+		),
 		changesTotal: changeCounter{prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "kube_ingress_aws",
@@ -143,6 +163,8 @@ func (metrics *metrics) serve(address string) {
 	prometheus.MustRegister(metrics.standaloneInstancesTotal)
 	prometheus.MustRegister(metrics.certificatesTotal)
 	prometheus.MustRegister(metrics.cloudWatchAlarmsTotal)
+	prometheus.MustRegister(metrics.reservedCapacityUnits)
+	prometheus.MustRegister(metrics.fleetWeight)
 	prometheus.MustRegister(metrics.changesTotal)
 
 	http.Handle("/metrics", promhttp.Handler())
@@ -11,7 +11,9 @@ import (
 	"fmt"
 
 	"runtime/debug"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	log "github.com/sirupsen/logrus"
@@ -32,7 +34,10 @@ type worker struct {
 
 	globalWAFACL string
 
-	cwAlarmConfig *kubernetes.ResourceLocation
+	cwAlarmConfig             *kubernetes.ResourceLocation
+	maintenanceConfig         *kubernetes.ResourceLocation
+	capacityReservationConfig *kubernetes.ResourceLocation
+	fleetWeightsConfig        *kubernetes.ResourceLocation
 
 	minLoadBalancerAge time.Duration
 }
@@ -49,10 +54,41 @@ type loadBalancer struct {
 	securityGroup                string
 	sslPolicy                    string
 	ipAddressType                string
+	ipAddressTypeAnnotated       bool
 	wafWebACLID                  string
 	certTTL                      time.Duration
 	cwAlarms                     aws.CloudWatchAlarmList
 	loadBalancerType             string
+	settingsHash                 string
+	accessLogsS3Bucket           string
+	accessLogsS3Prefix           string
+	accessLogsDisabled           bool
+	subnetSelector               string
+	eipAllocations               []string
+	privateIPv4Addresses         []string
+	nlbCrossZone                 string
+	nlbZoneAffinity              string
+	listeners                    string
+	httpListenerMode             string
+	targetProtocolVersion        string
+	healthCheckMatcher           string
+	mtlsMode                     string
+	mtlsCABundleRef              string
+	mtlsCABundle                 string
+	auth                         *aws.AuthConfig
+	strictHosts                  bool
+	sourceRanges                 string
+	sourceRangesHash             string
+	nlbSecurityGroup             bool
+	originHeaderRef              string
+	originHeader                 *aws.OriginHeader
+	maintenance                  bool
+	maintenanceHosts             []string
+	targetGroupAttributes        string
+	loadBalancerAttributes       string
+	responseHeaders              string
+	capacityUnits                int
+	fleetWeights                 string
 }
 
 const (
@@ -64,6 +100,18 @@ const (
 
 const (
 	cniEventRateLimit = 5 * time.Second
+
+	// maintenanceHostsKey is the key of the maintenance ConfigMap listing
+	// the hosts in maintenance.
+	maintenanceHostsKey = "hosts"
+
+	// capacityReservationsKey is the key of the capacity reservation
+	// ConfigMap listing the reservations, see aws.CapacityReservation.
+	capacityReservationsKey = "reservations"
+
+	// fleetWeightsKey is the key of the fleet weights ConfigMap listing
+	// the weights of the fleets, see aws.ParseFleetWeights.
+	fleetWeightsKey = "weights"
 )
 
 func (l *loadBalancer) Status() int {
@@ -88,8 +136,20 @@ func (l *loadBalancer) Status() int {
 func (l *loadBalancer) inSync() bool {
 	return reflect.DeepEqual(l.CertificateARNs(), l.stack.CertificateARNs) &&
 		l.stack.CWAlarmConfigHash == l.cwAlarms.Hash() &&
+		l.stack.ResourceTagsHash == aws.ResourceTagsHash(l.Tags()) &&
 		l.wafWebACLID == l.stack.WAFWebACLID &&
-		l.sslPolicy == l.stack.SSLPolicy
+		l.ipAddressType == l.stack.IpAddressType &&
+		l.sslPolicy == l.stack.SSLPolicy &&
+		l.settingsHash == l.stack.SettingsHash &&
+		aws.CABundleHash(l.mtlsCABundle) == l.stack.MTLSCABundleHash &&
+		l.auth.Hash() == l.stack.AuthConfigHash &&
+		l.hostnamesHash() == l.stack.HostnamesHash &&
+		l.originHeader.Hash() == l.stack.OriginHeaderHash &&
+		l.maintenance == l.stack.Maintenance &&
+		l.maintenanceHostsHash() == l.stack.MaintenanceHostsHash &&
+		l.fleetsHash() == l.stack.FleetsHash &&
+		aws.FleetWeightsHash(l.fleetWeights) == l.stack.FleetWeightsHash &&
+		l.capacityUnits == l.stack.CapacityUnits
 }
 
 // addIngress adds an ingress object to the load balancer.
@@ -123,18 +183,28 @@ func (l *loadBalancer) addIngress(certificateARNs []string, ingress *kubernetes.
 
 	// settings that would require a new load balancer no matter if it's
 	// shared or not.
-	if l.ipAddressType != ingress.IPAddressType ||
+	if (l.ipAddressType != ingress.IPAddressType && !aws.IPAddressTypeChangeable(l.loadBalancerType, l.ipAddressType, ingress.IPAddressType)) ||
 		l.scheme != ingress.Scheme ||
 		l.loadBalancerType != ingress.LoadBalancerType ||
 		l.http2 != ingress.HTTP2 {
 		return false
 	}
 
+	// security groups can't be added to or all removed from a network
+	// load balancer after its creation. The default security group is
+	// only attached to new load balancers.
+	if l.loadBalancerType == aws.LoadBalancerTypeNetwork && ((l.sourceRangesHash == "") != (ingress.SourceRanges == "") ||
+		(ingress.HasSGAnnotation && l.sourceRangesHash == "" && !l.nlbSecurityGroup)) {
+		return false
+	}
+
 	// settings that can be changed on an existing load balancer if it's
 	// NOT shared.
 	if ingress.Shared && (l.securityGroup != ingress.SecurityGroup ||
 		(ingress.HasSSLPolicyAnnotation && l.sslPolicy != ingress.SSLPolicy) ||
-		l.wafWebACLID != ingress.WAFWebACLID) {
+		(ingress.HasIPAddressTypeAnnotation && l.ipAddressTypeAnnotated && l.ipAddressType != ingress.IPAddressType) ||
+		l.wafWebACLID != ingress.WAFWebACLID ||
+		l.settingsHash != settingsHash(ingress)) {
 		return false
 	}
 
@@ -154,15 +224,262 @@ func (l *loadBalancer) addIngress(certificateARNs []string, ingress *kubernetes.
 		return false
 	}
 
+	// the custom tags of all ingresses are merged and share the tag limit
+	// of the load balancer with the certificate tags.
+	tags := l.Tags()
+	newTags := 0
+	for key := range ingress.Tags {
+		if _, ok := tags[key]; !ok {
+			newTags++
+		}
+	}
+	if len(tags)+newTags > aws.MaxResourceTags(len(l.ingresses)+newCerts) {
+		return false
+	}
+
+	// the listener rules required by the merged ingresses must not exceed
+	// the limit of the load balancer.
+	if ingress.Shared && aws.ListenerRules(l.listenerRulesSettings(ingress), httpRedirectToHTTPS) > aws.MaxListenerRules {
+		return false
+	}
+
 	for _, certificateARN := range certificateARNs {
 		l.ingresses[certificateARN] = append(l.ingresses[certificateARN], ingress)
 	}
 
 	l.shared = ingress.Shared
 	l.sslPolicy = ingress.SSLPolicy
+	// ingresses using the default IP address type don't change the type
+	// set by the annotation of another ingress, so that the result doesn't
+	// depend on the order of the ingresses.
+	if ingress.HasIPAddressTypeAnnotation || !l.ipAddressTypeAnnotated {
+		l.ipAddressType = ingress.IPAddressType
+		l.ipAddressTypeAnnotated = ingress.HasIPAddressTypeAnnotation
+	}
+	l.settingsHash = settingsHash(ingress)
+	l.accessLogsS3Bucket = ingress.AccessLogsS3Bucket
+	l.accessLogsS3Prefix = ingress.AccessLogsS3Prefix
+	l.accessLogsDisabled = ingress.AccessLogsDisabled
+	l.subnetSelector = ingress.SubnetSelector
+	l.eipAllocations = ingress.EIPAllocations
+	l.privateIPv4Addresses = ingress.PrivateIPv4Addresses
+	l.nlbCrossZone = ingress.NLBCrossZone
+	l.nlbZoneAffinity = ingress.NLBZoneAffinity
+	l.listeners = ingress.Listeners
+	l.httpListenerMode = ingress.HTTPListenerMode
+	l.targetProtocolVersion = ingress.TargetProtocolVersion
+	l.healthCheckMatcher = ingress.HealthCheckMatcher
+	l.mtlsMode = ingress.MTLSMode
+	l.mtlsCABundleRef = ingress.MTLSCABundleRef
+	l.mtlsCABundle = ingress.MTLSCABundle
+	l.auth = ingress.Auth
+	l.strictHosts = ingress.StrictHosts
+	l.sourceRanges = ingress.SourceRanges
+	l.sourceRangesHash = aws.SourceRangesHash(ingress.SourceRanges)
+	l.originHeaderRef = ingress.OriginHeaderRef
+	l.originHeader = ingress.OriginHeader
+	l.maintenance = ingress.Maintenance
+	l.fleetWeights = ingress.FleetWeights
+	l.targetGroupAttributes = ingress.TargetGroupAttributes
+	l.loadBalancerAttributes = ingress.LoadBalancerAttributes
+	l.responseHeaders = ingress.ResponseHeaders
 	return true
 }
 
+// stackSettings returns the settings used to create or update the
+// CloudFormation stack of the load balancer.
+func (l *loadBalancer) stackSettings() *aws.StackSettings {
+	return &aws.StackSettings{
+		Scheme:                 l.scheme,
+		SecurityGroup:          l.securityGroup,
+		Owner:                  l.Owner(),
+		SSLPolicy:              l.sslPolicy,
+		IPAddressType:          l.ipAddressType,
+		WAFWebACLID:            l.wafWebACLID,
+		CWAlarms:               l.cwAlarms,
+		LoadBalancerType:       l.loadBalancerType,
+		HTTP2:                  l.http2,
+		AccessLogsS3Bucket:     l.accessLogsS3Bucket,
+		AccessLogsS3Prefix:     l.accessLogsS3Prefix,
+		AccessLogsDisabled:     l.accessLogsDisabled,
+		SubnetSelector:         l.subnetSelector,
+		EIPAllocations:         l.eipAllocations,
+		PrivateIPv4Addresses:   l.privateIPv4Addresses,
+		NLBCrossZone:           l.nlbCrossZone,
+		NLBZoneAffinity:        l.nlbZoneAffinity,
+		Listeners:              l.listeners,
+		HTTPListenerMode:       l.httpListenerMode,
+		TargetProtocolVersion:  l.targetProtocolVersion,
+		HealthCheckMatcher:     l.healthCheckMatcher,
+		MTLSMode:               l.mtlsMode,
+		MTLSCABundleRef:        l.mtlsCABundleRef,
+		MTLSCABundle:           l.mtlsCABundle,
+		Auth:                   l.auth,
+		StrictHosts:            l.strictHosts,
+		Hostnames:              l.Hostnames(),
+		SourceRanges:           l.sourceRanges,
+		NLBSecurityGroup:       l.nlbSecurityGroup,
+		OriginHeaderRef:        l.originHeaderRef,
+		OriginHeader:           l.originHeader,
+		Maintenance:            l.maintenance,
+		MaintenanceHosts:       l.maintenanceHosts,
+		TargetGroupAttributes:  l.targetGroupAttributes,
+		LoadBalancerAttributes: l.loadBalancerAttributes,
+		ResponseHeaders:        l.responseHeaders,
+		CapacityUnits:          l.capacityUnits,
+		Fleets:                 l.Fleets(),
+		FleetWeights:           l.fleetWeights,
+		Tags:                   l.Tags(),
+	}
+}
+
+// settingsHash returns the hash of the settings of the ingress which all
+// ingresses sharing a load balancer must agree on, see aws.SettingsHash.
+func settingsHash(ingress *kubernetes.Ingress) string {
+	return aws.SettingsHash(&aws.StackSettings{
+		AccessLogsS3Bucket:     ingress.AccessLogsS3Bucket,
+		AccessLogsS3Prefix:     ingress.AccessLogsS3Prefix,
+		AccessLogsDisabled:     ingress.AccessLogsDisabled,
+		SubnetSelector:         ingress.SubnetSelector,
+		EIPAllocations:         ingress.EIPAllocations,
+		PrivateIPv4Addresses:   ingress.PrivateIPv4Addresses,
+		NLBCrossZone:           ingress.NLBCrossZone,
+		NLBZoneAffinity:        ingress.NLBZoneAffinity,
+		Listeners:              ingress.Listeners,
+		HTTPListenerMode:       ingress.HTTPListenerMode,
+		MTLSMode:               ingress.MTLSMode,
+		MTLSCABundleRef:        ingress.MTLSCABundleRef,
+		StrictHosts:            ingress.StrictHosts,
+		SourceRanges:           ingress.SourceRanges,
+		OriginHeaderRef:        ingress.OriginHeaderRef,
+		TargetGroupAttributes:  ingress.TargetGroupAttributes,
+		LoadBalancerAttributes: ingress.LoadBalancerAttributes,
+		ResponseHeaders:        ingress.ResponseHeaders,
+		TargetProtocolVersion:  ingress.TargetProtocolVersion,
+		HealthCheckMatcher:     ingress.HealthCheckMatcher,
+	})
+}
+
+// listenerRulesSettings returns the settings determining the listener rules
+// of the load balancer with the ingress added. The settings which shared
+// ingresses must agree on are taken from the ingress, as they are not known
+// for load balancers without ingresses.
+func (l *loadBalancer) listenerRulesSettings(ingress *kubernetes.Ingress) *aws.StackSettings {
+	fleets := l.Fleets()
+	if ingress.Fleet != "" && len(ingress.Hostnames) > 0 {
+		if fleets == nil {
+			fleets = make(map[string][]string)
+		}
+		fleets[ingress.Fleet] = append(fleets[ingress.Fleet], ingress.Hostnames...)
+	}
+
+	return &aws.StackSettings{
+		LoadBalancerType: ingress.LoadBalancerType,
+		Listeners:        ingress.Listeners,
+		HTTPListenerMode: ingress.HTTPListenerMode,
+		StrictHosts:      ingress.StrictHosts,
+		Hostnames:        append(l.Hostnames(), ingress.Hostnames...),
+		SourceRanges:     ingress.SourceRanges,
+		OriginHeader:     ingress.OriginHeader,
+		Fleets:           fleets,
+	}
+}
+
+// Hostnames returns the hostnames of all ingresses of the load balancer.
+func (l *loadBalancer) Hostnames() []string {
+	var hostnames []string
+	for _, ingresses := range l.ingresses {
+		for _, ingress := range ingresses {
+			hostnames = append(hostnames, ingress.Hostnames...)
+		}
+	}
+	return hostnames
+}
+
+// hostnamesHash returns the hash of the hostnames of the load balancer in
+// strict hosts mode and an empty string otherwise.
+func (l *loadBalancer) hostnamesHash() string {
+	if !l.strictHosts {
+		return ""
+	}
+	return aws.HostnamesHash(l.Hostnames())
+}
+
+// Fleets returns the hostnames of the ingresses of the load balancer by the
+// name of the fleet serving them, or nil if no ingress selects a fleet.
+func (l *loadBalancer) Fleets() map[string][]string {
+	var fleets map[string][]string
+	for _, ingresses := range l.ingresses {
+		for _, ingress := range ingresses {
+			if ingress.Fleet == "" || len(ingress.Hostnames) == 0 {
+				continue
+			}
+			if fleets == nil {
+				fleets = make(map[string][]string)
+			}
+			fleets[ingress.Fleet] = append(fleets[ingress.Fleet], ingress.Hostnames...)
+		}
+	}
+	return fleets
+}
+
+// fleetsHash returns the hash of the hostnames served by fleets and an empty
+// string if there are none.
+func (l *loadBalancer) fleetsHash() string {
+	fleets := l.Fleets()
+	if len(fleets) == 0 {
+		return ""
+	}
+	return aws.FleetsHash(fleets)
+}
+
+// maintenanceHostsHash returns the hash of the hosts of the load balancer in
+// maintenance and an empty string if there are none.
+func (l *loadBalancer) maintenanceHostsHash() string {
+	if l.maintenance || len(l.maintenanceHosts) == 0 {
+		return ""
+	}
+	return aws.HostnamesHash(l.maintenanceHosts)
+}
+
+// Tags returns the custom tags of all ingresses of the load balancer. If
+// ingresses define different values for the same tag, the value is the
+// sorted, space separated list of all values, as commas are not allowed in
+// tag values.
+func (l *loadBalancer) Tags() map[string]string {
+	values := make(map[string]map[string]struct{})
+	for _, ingresses := range l.ingresses {
+		for _, ingress := range ingresses {
+			for key, value := range ingress.Tags {
+				if _, ok := values[key]; !ok {
+					values[key] = make(map[string]struct{})
+				}
+				values[key][value] = struct{}{}
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(values))
+	for key, set := range values {
+		list := make([]string, 0, len(set))
+		for value := range set {
+			list = append(list, value)
+		}
+		sort.Strings(list)
+
+		value := []rune(strings.Join(list, " "))
+		if len(value) > aws.MaxTagValueLength {
+			value = value[:aws.MaxTagValueLength]
+		}
+		tags[key] = string(value)
+	}
+	return tags
+}
+
 // CertificateARNs returns a map of certificates and their expiry times.
 func (l *loadBalancer) CertificateARNs() map[string]time.Time {
 	certificates := make(map[string]time.Time, len(l.ingresses))
@@ -320,6 +637,25 @@ func (w *worker) doWork(ctx context.Context) (problems *problem.List) {
 		return problems.Add("failed to retrieve cloudwatch alarm configuration: %w", err)
 	}
 
+	maintenanceHosts, err := w.getMaintenanceHosts()
+	if err != nil {
+		return problems.Add("failed to retrieve maintenance configuration: %w", err)
+	}
+
+	capacityReservations, err := w.getCapacityReservations()
+	if err != nil {
+		return problems.Add("failed to retrieve capacity reservation configuration: %w", err)
+	}
+
+	fleetWeights, err := w.getFleetWeights()
+	if err != nil {
+		return problems.Add("failed to retrieve fleet weights configuration: %w", err)
+	}
+
+	w.resolveCABundles(ingresses, problems)
+	w.resolveAuthConfigs(ingresses, problems)
+	w.resolveOriginHeaders(ingresses, problems)
+
 	counts := countByIngressType(ingresses)
 
 	w.metrics.ingressesTotal.Set(float64(counts[kubernetes.TypeIngress]))
@@ -346,6 +682,26 @@ func (w *worker) doWork(ctx context.Context) (problems *problem.List) {
 
 	certs := NewCertificates(certificateSummaries)
 	model := buildManagedModel(certs, w.certsPerALB, w.certTTL, ingresses, stackELBs, cwAlarms, w.globalWAFACL)
+	attachMaintenanceHosts(model, maintenanceHosts)
+	attachCapacityReservations(model, capacityReservations, time.Now())
+	w.metrics.reservedCapacityUnits.Reset()
+	for _, loadBalancer := range model {
+		if loadBalancer.stack != nil && loadBalancer.capacityUnits > 0 {
+			w.metrics.reservedCapacityUnits.WithLabelValues(loadBalancer.stack.Name).Set(float64(loadBalancer.capacityUnits))
+		}
+	}
+	attachFleetWeights(model, fleetWeights)
+	w.metrics.fleetWeight.Reset()
+	for _, loadBalancer := range model {
+		if loadBalancer.stack == nil || loadBalancer.fleetWeights == "" {
+			continue
+		}
+		// the weights were validated when parsed
+		weights, _ := aws.ParseFleetWeights(loadBalancer.fleetWeights)
+		for fleet, weight := range weights {
+			w.metrics.fleetWeight.WithLabelValues(loadBalancer.stack.Name, fleet).Set(float64(weight))
+		}
+	}
 	log.Debugf("Have %d model(s)", len(model))
 	for _, loadBalancer := range model {
 		switch loadBalancer.Status() {
@@ -398,6 +754,11 @@ func getAllLoadBalancers(certs CertificatesFinder, certTTL time.Duration, stackL
 			loadBalancerType:             sl.Stack.LoadBalancerType,
 			http2:                        sl.Stack.HTTP2,
 			wafWebACLID:                  sl.Stack.WAFWebACLID,
+			settingsHash:                 sl.Stack.SettingsHash,
+			strictHosts:                  sl.Stack.HostnamesHash != "",
+			sourceRangesHash:             sl.Stack.SourceRangesHash,
+			nlbSecurityGroup:             sl.Stack.NLBSecurityGroup,
+			maintenance:                  sl.Stack.Maintenance,
 			certTTL:                      certTTL,
 		}
 		// initialize ingresses map with existing certificates from the stack.
@@ -481,15 +842,44 @@ func matchIngressesToLoadBalancers(
 			loadBalancers = append(
 				loadBalancers,
 				&loadBalancer{
-					ingresses:        i,
-					scheme:           ingress.Scheme,
-					shared:           ingress.Shared,
-					securityGroup:    ingress.SecurityGroup,
-					sslPolicy:        ingress.SSLPolicy,
-					ipAddressType:    ingress.IPAddressType,
-					loadBalancerType: ingress.LoadBalancerType,
-					http2:            ingress.HTTP2,
-					wafWebACLID:      ingress.WAFWebACLID,
+					ingresses:              i,
+					scheme:                 ingress.Scheme,
+					shared:                 ingress.Shared,
+					securityGroup:          ingress.SecurityGroup,
+					sslPolicy:              ingress.SSLPolicy,
+					ipAddressType:          ingress.IPAddressType,
+					ipAddressTypeAnnotated: ingress.HasIPAddressTypeAnnotation,
+					loadBalancerType:       ingress.LoadBalancerType,
+					http2:                  ingress.HTTP2,
+					wafWebACLID:            ingress.WAFWebACLID,
+					settingsHash:           settingsHash(ingress),
+					accessLogsS3Bucket:     ingress.AccessLogsS3Bucket,
+					accessLogsS3Prefix:     ingress.AccessLogsS3Prefix,
+					accessLogsDisabled:     ingress.AccessLogsDisabled,
+					subnetSelector:         ingress.SubnetSelector,
+					eipAllocations:         ingress.EIPAllocations,
+					privateIPv4Addresses:   ingress.PrivateIPv4Addresses,
+					nlbCrossZone:           ingress.NLBCrossZone,
+					nlbZoneAffinity:        ingress.NLBZoneAffinity,
+					listeners:              ingress.Listeners,
+					httpListenerMode:       ingress.HTTPListenerMode,
+					targetProtocolVersion:  ingress.TargetProtocolVersion,
+					healthCheckMatcher:     ingress.HealthCheckMatcher,
+					mtlsMode:               ingress.MTLSMode,
+					mtlsCABundleRef:        ingress.MTLSCABundleRef,
+					mtlsCABundle:           ingress.MTLSCABundle,
+					auth:                   ingress.Auth,
+					strictHosts:            ingress.StrictHosts,
+					sourceRanges:           ingress.SourceRanges,
+					sourceRangesHash:       aws.SourceRangesHash(ingress.SourceRanges),
+					nlbSecurityGroup:       ingress.NLBSecurityGroup,
+					originHeaderRef:        ingress.OriginHeaderRef,
+					originHeader:           ingress.OriginHeader,
+					maintenance:            ingress.Maintenance,
+					fleetWeights:           ingress.FleetWeights,
+					targetGroupAttributes:  ingress.TargetGroupAttributes,
+					loadBalancerAttributes: ingress.LoadBalancerAttributes,
+					responseHeaders:        ingress.ResponseHeaders,
 				},
 			)
 		}
@@ -511,6 +901,54 @@ func attachCloudWatchAlarms(loadBalancers []*loadBalancer, cwAlarms aws.CloudWat
 	}
 }
 
+// attachMaintenanceHosts sets the hosts in maintenance of each application
+// load balancer in the list.
+func attachMaintenanceHosts(loadBalancers []*loadBalancer, maintenanceHosts map[string]bool) {
+	for _, loadBalancer := range loadBalancers {
+		if loadBalancer.loadBalancerType != aws.LoadBalancerTypeApplication {
+			continue
+		}
+
+		var hosts []string
+		for _, hostname := range loadBalancer.Hostnames() {
+			hostname = strings.ToLower(hostname)
+			if maintenanceHosts[hostname] && !slices.Contains(hosts, hostname) {
+				hosts = append(hosts, hostname)
+			}
+		}
+		sort.Strings(hosts)
+		loadBalancer.maintenanceHosts = hosts
+	}
+}
+
+// attachCapacityReservations sets the capacity units reserved at the given
+// time for each load balancer in the list.
+func attachCapacityReservations(loadBalancers []*loadBalancer, reservations []aws.CapacityReservation, now time.Time) {
+	for _, loadBalancer := range loadBalancers {
+		loadBalancer.capacityUnits = aws.ReservedCapacityUnits(reservations, loadBalancer.Hostnames(), now)
+	}
+}
+
+// attachFleetWeights sets the fleet weights of each application load balancer
+// in the list whose ingresses don't define them. Load balancers restricting
+// forwarding keep forwarding all requests to the default targets.
+func attachFleetWeights(loadBalancers []*loadBalancer, fleetWeights string) {
+	if fleetWeights == "" {
+		return
+	}
+
+	for _, loadBalancer := range loadBalancers {
+		if loadBalancer.loadBalancerType != aws.LoadBalancerTypeApplication ||
+			loadBalancer.fleetWeights != "" ||
+			loadBalancer.strictHosts ||
+			loadBalancer.sourceRangesHash != "" ||
+			loadBalancer.originHeaderRef != "" {
+			continue
+		}
+		loadBalancer.fleetWeights = fleetWeights
+	}
+}
+
 func attachGlobalWAFACL(ings []*kubernetes.Ingress, globalWAFACL string) {
 	for _, ing := range ings {
 		if ing.WAFWebACLID != "" {
@@ -547,7 +985,7 @@ func (w *worker) createStack(ctx context.Context, lb *loadBalancer, problems *pr
 
 	log.Infof("Creating stack for certificates %q / ingress %q", certificates, lb.ingresses)
 
-	stackId, err := w.awsAdapter.CreateStack(ctx, certificates, lb.scheme, lb.securityGroup, lb.Owner(), lb.sslPolicy, lb.ipAddressType, lb.wafWebACLID, lb.cwAlarms, lb.loadBalancerType, lb.http2)
+	stackId, err := w.awsAdapter.CreateStack(ctx, certificates, lb.stackSettings())
 	if err != nil {
 		if isAlreadyExistsError(err) {
 			lb.stack, err = w.awsAdapter.GetStack(ctx, stackId)
@@ -567,7 +1005,7 @@ func (w *worker) updateStack(ctx context.Context, lb *loadBalancer, problems *pr
 
 	log.Infof("Updating %q stack for %d certificates / %d ingresses", lb.scheme, len(certificates), len(lb.ingresses))
 
-	stackId, err := w.awsAdapter.UpdateStack(ctx, lb.stack.Name, certificates, lb.scheme, lb.securityGroup, lb.Owner(), lb.sslPolicy, lb.ipAddressType, lb.wafWebACLID, lb.cwAlarms, lb.loadBalancerType, lb.http2)
+	stackId, err := w.awsAdapter.UpdateStack(ctx, lb.stack.Name, certificates, lb.stackSettings())
 	if isNoUpdatesToBePerformedError(err) {
 		log.Debugf("Stack(%q) is already up to date", certificates)
 	} else if err != nil {
@@ -654,6 +1092,70 @@ func (w *worker) deleteStack(ctx context.Context, lb *loadBalancer, problems *pr
 	}
 }
 
+// resolveCABundles sets the CA bundles of the ingresses that verify client
+// certificates. Ingresses whose CA bundle can't be retrieved keep their load
+// balancer, but its stack won't be updated until the CA bundle is available.
+func (w *worker) resolveCABundles(ingresses []*kubernetes.Ingress, problems *problem.List) {
+	caBundles := make(map[string]string)
+	for _, ingress := range ingresses {
+		if ingress.MTLSCABundleRef == "" {
+			continue
+		}
+
+		caBundle, ok := caBundles[ingress.MTLSCABundleRef]
+		if !ok {
+			var err error
+			caBundle, err = w.kubeAPI.GetCABundle(ingress.MTLSCABundleRef)
+			if err != nil {
+				problems.Add("failed to get CA bundle of %s: %w", ingress, err)
+			}
+			caBundles[ingress.MTLSCABundleRef] = caBundle
+		}
+		ingress.MTLSCABundle = caBundle
+	}
+}
+
+// resolveAuthConfigs sets the credentials of the authentication configs of
+// the ingresses. Ingresses whose credentials can't be retrieved keep their
+// load balancer, but its stack won't be updated until they are available.
+func (w *worker) resolveAuthConfigs(ingresses []*kubernetes.Ingress, problems *problem.List) {
+	for _, ingress := range ingresses {
+		if ingress.Auth == nil {
+			continue
+		}
+
+		auth, err := w.kubeAPI.GetAuthConfig(ingress)
+		if err != nil {
+			problems.Add("failed to get authentication config of %s: %w", ingress, err)
+			continue
+		}
+		ingress.Auth = auth
+	}
+}
+
+// resolveOriginHeaders sets the origin headers of the ingresses that require
+// them. Ingresses whose origin header can't be retrieved keep their load
+// balancer, but its stack won't be updated until it is available.
+func (w *worker) resolveOriginHeaders(ingresses []*kubernetes.Ingress, problems *problem.List) {
+	originHeaders := make(map[string]*aws.OriginHeader)
+	for _, ingress := range ingresses {
+		if ingress.OriginHeaderRef == "" {
+			continue
+		}
+
+		originHeader, ok := originHeaders[ingress.OriginHeaderRef]
+		if !ok {
+			var err error
+			originHeader, err = w.kubeAPI.GetOriginHeader(ingress.OriginHeaderRef)
+			if err != nil {
+				problems.Add("failed to get origin header of %s: %w", ingress, err)
+			}
+			originHeaders[ingress.OriginHeaderRef] = originHeader
+		}
+		ingress.OriginHeader = originHeader
+	}
+}
+
 // getCloudWatchAlarms retrieves CloudWatch Alarm configuration from a
 // ConfigMap described by [worker.cwAlarmConfig]. If [worker.cwAlarmConfig] is nil, an empty alarm
 // configuration will be returned. Returns any error that might occur while
@@ -671,6 +1173,67 @@ func (w *worker) getCloudWatchAlarms() (aws.CloudWatchAlarmList, error) {
 	return getCloudWatchAlarmsFromConfigMap(configMap), nil
 }
 
+// getMaintenanceHosts retrieves the hosts in maintenance from the key hosts
+// of the ConfigMap described by [worker.maintenanceConfig]. The hosts are
+// separated by commas or whitespace. If [worker.maintenanceConfig] is nil, no
+// hosts are in maintenance.
+func (w *worker) getMaintenanceHosts() (map[string]bool, error) {
+	if w.maintenanceConfig == nil {
+		return nil, nil
+	}
+
+	configMap, err := w.kubeAPI.GetConfigMap(w.maintenanceConfig.Namespace, w.maintenanceConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make(map[string]bool)
+	for _, host := range strings.FieldsFunc(configMap.Data[maintenanceHostsKey], func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		hosts[strings.ToLower(host)] = true
+	}
+	return hosts, nil
+}
+
+// getCapacityReservations retrieves the capacity reservations from the key
+// reservations of the ConfigMap described by
+// [worker.capacityReservationConfig]. If [worker.capacityReservationConfig]
+// is nil, no capacity is reserved.
+func (w *worker) getCapacityReservations() ([]aws.CapacityReservation, error) {
+	if w.capacityReservationConfig == nil {
+		return nil, nil
+	}
+
+	configMap, err := w.kubeAPI.GetConfigMap(w.capacityReservationConfig.Namespace, w.capacityReservationConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.NewCapacityReservationsFromYAML([]byte(configMap.Data[capacityReservationsKey]))
+}
+
+// getFleetWeights retrieves the fleet weights from the key weights of the
+// ConfigMap described by [worker.fleetWeightsConfig] in the format of
+// aws.FormatFleetWeights. If [worker.fleetWeightsConfig] is nil, no requests
+// are forwarded to fleets by weight.
+func (w *worker) getFleetWeights() (string, error) {
+	if w.fleetWeightsConfig == nil {
+		return "", nil
+	}
+
+	configMap, err := w.kubeAPI.GetConfigMap(w.fleetWeightsConfig.Namespace, w.fleetWeightsConfig.Name)
+	if err != nil {
+		return "", err
+	}
+
+	weights, err := aws.ParseFleetWeights(configMap.Data[fleetWeightsKey])
+	if err != nil {
+		return "", err
+	}
+	return aws.FormatFleetWeights(weights), nil
+}
+
 // getCloudWatchAlarmsFromConfigMap extracts cloudwatch alarm configuration
 // from ConfigMap data. It will collect alarm configuration from all ConfigMap
 // data keys it finds. If a ConfigMap data key contains invalid data, an error
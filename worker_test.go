@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -22,6 +23,7 @@ import (
 	"github.com/zalando-incubator/kube-ingress-aws-controller/aws"
 	"github.com/zalando-incubator/kube-ingress-aws-controller/certs"
 	cloudformation "github.com/zalando-incubator/kube-ingress-aws-controller/internal/aws/cloudformation"
+	kubemock "github.com/zalando-incubator/kube-ingress-aws-controller/internal/kubernetes/mock"
 	"github.com/zalando-incubator/kube-ingress-aws-controller/kubernetes"
 	"github.com/zalando/skipper/dataclients/kubernetes/kubernetestest"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -756,6 +758,21 @@ func TestResourceConversionOneToOne(tt *testing.T) {
 }
 
 func TestAddIngress(tt *testing.T) {
+	hostnames := func(prefix string, n int) []string {
+		hostnames := make([]string, 0, n)
+		for i := range n {
+			hostnames = append(hostnames, fmt.Sprintf("%s-%d.example.org", prefix, i))
+		}
+		return hostnames
+	}
+	tags := func(prefix string, n int) map[string]string {
+		tags := make(map[string]string, n)
+		for i := range n {
+			tags[fmt.Sprintf("%s-%d", prefix, i)] = "foo"
+		}
+		return tags
+	}
+
 	for _, test := range []struct {
 		name            string
 		loadBalancer    *loadBalancer
@@ -784,6 +801,339 @@ func TestAddIngress(tt *testing.T) {
 			},
 			added: false,
 		},
+		{
+			name: "access logs bucket not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:    map[string][]*kubernetes.Ingress{},
+				settingsHash: settingsHash(&kubernetes.Ingress{AccessLogsS3Bucket: "foo"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:             true,
+				AccessLogsS3Bucket: "bar",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "access logs disabled not matching",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{},
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:             true,
+				AccessLogsDisabled: true,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "subnet selector not matching",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{},
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:         true,
+				SubnetSelector: "az:eu-central-1a",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "http listener mode not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{HTTPListenerMode: aws.HTTPListenerModeDisabled}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				HTTPListenerMode: aws.HTTPListenerModeForward,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "mTLS CA bundle not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash: settingsHash(&kubernetes.Ingress{
+					MTLSMode:        aws.MTLSModeVerify,
+					MTLSCABundleRef: "configmap/default/foo/ca.crt",
+				}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				MTLSMode:         aws.MTLSModeVerify,
+				MTLSCABundleRef:  "configmap/default/bar/ca.crt",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "strict hosts not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				StrictHosts:      true,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "source ranges not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{SourceRanges: "10.0.0.0/8"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				SourceRanges:     "10.0.0.0/8,192.168.0.0/16",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "origin header not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{OriginHeaderRef: "default/foo"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				OriginHeaderRef:  "default/bar",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "load balancer attributes not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{LoadBalancerAttributes: "waf.fail_open.enabled=true"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "response headers not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{ResponseHeaders: "X-Frame-Options: DENY"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "target group attributes not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{TargetGroupAttributes: "stickiness.enabled=true"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "source ranges added to owned NLB",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				stack: &aws.Stack{
+					OwnerIngress: "foo/bar",
+				},
+			},
+			ingress: &kubernetes.Ingress{
+				Namespace:        "foo",
+				Name:             "bar",
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SourceRanges:     "10.0.0.0/8",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "source ranges changed on owned NLB",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				sourceRangesHash: aws.SourceRangesHash("10.0.0.0/8"),
+				stack: &aws.Stack{
+					OwnerIngress: "foo/bar",
+				},
+			},
+			ingress: &kubernetes.Ingress{
+				Namespace:        "foo",
+				Name:             "bar",
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SourceRanges:     "192.168.0.0/16",
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "security group annotation on NLB without security group",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				securityGroup:    "sg-custom",
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    "sg-custom",
+				NLBSecurityGroup: true,
+				HasSGAnnotation:  true,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "security group annotation on NLB with security group",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				securityGroup:    "sg-custom",
+				nlbSecurityGroup: true,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    "sg-custom",
+				NLBSecurityGroup: true,
+				HasSGAnnotation:  true,
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "default security group keeps NLB without security group",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				NLBSecurityGroup: true,
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "security group annotation not matching on NLB",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				securityGroup:    "sg-custom",
+				nlbSecurityGroup: true,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				SecurityGroup:    "sg-other",
+				NLBSecurityGroup: true,
+				HasSGAnnotation:  true,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "listeners not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{Listeners: "HTTPS:8443"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "target protocol version not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{TargetProtocolVersion: aws.TargetProtocolVersionGRPC}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "health check matcher not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{HealthCheckMatcher: "200-299"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:             true,
+				LoadBalancerType:   aws.LoadBalancerTypeApplication,
+				HealthCheckMatcher: "200",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "nlb zone affinity not matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				settingsHash:     settingsHash(&kubernetes.Ingress{NLBZoneAffinity: aws.ZoneAffinityAvailabilityZone}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "nlb cross zone matching",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeNetwork,
+				settingsHash:     settingsHash(&kubernetes.Ingress{NLBCrossZone: "true"}),
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeNetwork,
+				NLBCrossZone:     "true",
+			},
+			maxCerts: 5,
+			added:    true,
+		},
 		{
 			name: "ip address type not matching",
 			loadBalancer: &loadBalancer{
@@ -794,6 +1144,54 @@ func TestAddIngress(tt *testing.T) {
 			},
 			added: false,
 		},
+		{
+			name: "ip address type changed to dualstack without public IPv4 on ALB",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				ipAddressType:    aws.IPAddressTypeDualstack,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				IPAddressType:    aws.IPAddressTypeDualstackWithoutPublicIPv4,
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "ip address type annotation overriding the default on shared ALB",
+			loadBalancer: &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				ipAddressType:    aws.IPAddressTypeDualstack,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:                     true,
+				LoadBalancerType:           aws.LoadBalancerTypeApplication,
+				IPAddressType:              aws.IPAddressTypeDualstackWithoutPublicIPv4,
+				HasIPAddressTypeAnnotation: true,
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "ip address type annotation not matching on shared ALB",
+			loadBalancer: &loadBalancer{
+				ingresses:              map[string][]*kubernetes.Ingress{},
+				loadBalancerType:       aws.LoadBalancerTypeApplication,
+				ipAddressType:          aws.IPAddressTypeDualstack,
+				ipAddressTypeAnnotated: true,
+			},
+			ingress: &kubernetes.Ingress{
+				Shared:                     true,
+				LoadBalancerType:           aws.LoadBalancerTypeApplication,
+				IPAddressType:              aws.IPAddressTypeDualstackWithoutPublicIPv4,
+				HasIPAddressTypeAnnotation: true,
+			},
+			maxCerts: 5,
+			added:    false,
+		},
 		{
 			name: "don't add ingresses non-shared, non-owned load balancer",
 			loadBalancer: &loadBalancer{
@@ -908,25 +1306,172 @@ func TestAddIngress(tt *testing.T) {
 			added: false,
 		},
 		{
-			name: "Adding/changing WAF, SG or TLS settings on non-shared LB should work",
+			name: "merged tags within the tag limit",
 			loadBalancer: &loadBalancer{
-				ingresses: make(map[string][]*kubernetes.Ingress),
-				stack: &aws.Stack{
-					OwnerIngress: "foo/bar",
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, Tags: tags("a", 30)}},
 				},
-				sslPolicy: "ELBSecurityPolicy-2016-08",
 			},
+			certificateARNs: []string{"bar"},
 			ingress: &kubernetes.Ingress{
-				Name:          "bar",
-				Namespace:     "foo",
-				WAFWebACLID:   "WAFZXX",
-				SecurityGroup: "bar",
-				SSLPolicy:     "ELBSecurityPolicy-FS-2018-06",
-				Shared:        false,
+				Shared: true,
+				Tags:   tags("a", aws.MaxResourceTags(2)),
 			},
-			added: true,
+			maxCerts: 5,
+			added:    true,
 		},
-	} {
+		{
+			name: "merged tags exceeding the tag limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, Tags: tags("a", 30)}},
+				},
+			},
+			certificateARNs: []string{"bar"},
+			ingress: &kubernetes.Ingress{
+				Shared: true,
+				Tags:   tags("a", aws.MaxResourceTags(2)+1),
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			// the HTTP and HTTPS listeners have one forward rule per five
+			// hostnames and a rule denying internal traffic.
+			name: "merged hostnames within the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, StrictHosts: true, Hostnames: hostnames("a", 240)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{StrictHosts: true}),
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Hostnames:        hostnames("b", 5),
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "merged hostnames exceeding the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, StrictHosts: true, Hostnames: hostnames("a", 240)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{StrictHosts: true}),
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Hostnames:        hostnames("b", 6),
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			// the rules match three hostnames and both source ranges.
+			name: "merged hostnames with source ranges within the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, StrictHosts: true, Hostnames: hostnames("a", 120)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{StrictHosts: true, SourceRanges: "10.0.0.0/8,192.168.0.0/16"}),
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Hostnames:        hostnames("b", 27),
+				SourceRanges:     "10.0.0.0/8,192.168.0.0/16",
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "merged hostnames with source ranges exceeding the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, StrictHosts: true, Hostnames: hostnames("a", 120)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				settingsHash:     settingsHash(&kubernetes.Ingress{StrictHosts: true, SourceRanges: "10.0.0.0/8,192.168.0.0/16"}),
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				StrictHosts:      true,
+				Hostnames:        hostnames("b", 30),
+				SourceRanges:     "10.0.0.0/8,192.168.0.0/16",
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "merged fleet hostnames within the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, Fleet: "canary", Hostnames: hostnames("a", 240)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				Fleet:            "canary",
+				Hostnames:        hostnames("b", 5),
+			},
+			maxCerts: 5,
+			added:    true,
+		},
+		{
+			name: "merged fleet hostnames exceeding the listener rule limit",
+			loadBalancer: &loadBalancer{
+				ingresses: map[string][]*kubernetes.Ingress{
+					"foo": {{Shared: true, Fleet: "canary", Hostnames: hostnames("a", 240)}},
+				},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+			},
+			certificateARNs: []string{"foo"},
+			ingress: &kubernetes.Ingress{
+				Shared:           true,
+				LoadBalancerType: aws.LoadBalancerTypeApplication,
+				Fleet:            "canary",
+				Hostnames:        hostnames("b", 6),
+			},
+			maxCerts: 5,
+			added:    false,
+		},
+		{
+			name: "Adding/changing WAF, SG or TLS settings on non-shared LB should work",
+			loadBalancer: &loadBalancer{
+				ingresses: make(map[string][]*kubernetes.Ingress),
+				stack: &aws.Stack{
+					OwnerIngress: "foo/bar",
+				},
+				sslPolicy: "ELBSecurityPolicy-2016-08",
+			},
+			ingress: &kubernetes.Ingress{
+				Name:          "bar",
+				Namespace:     "foo",
+				WAFWebACLID:   "WAFZXX",
+				SecurityGroup: "bar",
+				SSLPolicy:     "ELBSecurityPolicy-FS-2018-06",
+				Shared:        false,
+			},
+			added: true,
+		},
+	} {
 		tt.Run(test.name, func(t *testing.T) {
 			assert.Equal(
 				t,
@@ -937,6 +1482,61 @@ func TestAddIngress(tt *testing.T) {
 	}
 }
 
+func TestAddIngressIPAddressTypeOrder(t *testing.T) {
+	annotated := &kubernetes.Ingress{
+		Shared:                     true,
+		LoadBalancerType:           aws.LoadBalancerTypeApplication,
+		IPAddressType:              aws.IPAddressTypeDualstackWithoutPublicIPv4,
+		HasIPAddressTypeAnnotation: true,
+	}
+	unannotated := &kubernetes.Ingress{
+		Shared:           true,
+		LoadBalancerType: aws.LoadBalancerTypeApplication,
+		IPAddressType:    aws.IPAddressTypeDualstack,
+	}
+
+	for _, test := range []struct {
+		name      string
+		ingresses []*kubernetes.Ingress
+	}{
+		{
+			name:      "annotated first",
+			ingresses: []*kubernetes.Ingress{annotated, unannotated},
+		},
+		{
+			name:      "unannotated first",
+			ingresses: []*kubernetes.Ingress{unannotated, annotated},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			lb := &loadBalancer{
+				ingresses:        map[string][]*kubernetes.Ingress{},
+				loadBalancerType: aws.LoadBalancerTypeApplication,
+				ipAddressType:    aws.IPAddressTypeDualstack,
+			}
+			for _, ingress := range test.ingresses {
+				require.True(t, lb.addIngress([]string{"foo"}, ingress, 5))
+			}
+			assert.Equal(t, aws.IPAddressTypeDualstackWithoutPublicIPv4, lb.ipAddressType)
+		})
+	}
+
+	t.Run("conflicting annotations", func(t *testing.T) {
+		lb := &loadBalancer{
+			ingresses:        map[string][]*kubernetes.Ingress{},
+			loadBalancerType: aws.LoadBalancerTypeApplication,
+			ipAddressType:    aws.IPAddressTypeDualstack,
+		}
+		require.True(t, lb.addIngress([]string{"foo"}, annotated, 5))
+		assert.False(t, lb.addIngress([]string{"foo"}, &kubernetes.Ingress{
+			Shared:                     true,
+			LoadBalancerType:           aws.LoadBalancerTypeApplication,
+			IPAddressType:              aws.IPAddressTypeDualstack,
+			HasIPAddressTypeAnnotation: true,
+		}, 5))
+	})
+}
+
 func TestSortStacks(tt *testing.T) {
 	testTime := time.Now()
 
@@ -1294,6 +1894,131 @@ func TestAttachCloudWatchAlarmsCopy(t *testing.T) {
 	assert.Equal(t, cloudformation.String("baz"), lbTwo.cwAlarms[0].AlarmName)
 }
 
+func TestAttachMaintenanceHosts(t *testing.T) {
+	alb := &loadBalancer{
+		loadBalancerType: aws.LoadBalancerTypeApplication,
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Hostnames: []string{"b.org", "A.org", "c.org"}}},
+			"bar": {{Hostnames: []string{"a.org"}}},
+		},
+	}
+	nlb := &loadBalancer{
+		loadBalancerType: aws.LoadBalancerTypeNetwork,
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Hostnames: []string{"a.org"}}},
+		},
+	}
+
+	attachMaintenanceHosts([]*loadBalancer{alb, nlb}, map[string]bool{"a.org": true, "b.org": true, "d.org": true})
+
+	assert.Equal(t, []string{"a.org", "b.org"}, alb.maintenanceHosts)
+	assert.Empty(t, nlb.maintenanceHosts)
+}
+
+func TestGetMaintenanceHosts(t *testing.T) {
+	kubeAPI := &kubemock.API{}
+	kubeAPI.On("GetConfigMap", "kube-system", "maintenance").Return(&kubernetes.ConfigMap{
+		Data: map[string]string{"hosts": "a.org, B.org\nc.org"},
+	}, nil)
+
+	w := &worker{kubeAPI: kubeAPI}
+	hosts, err := w.getMaintenanceHosts()
+	require.NoError(t, err)
+	assert.Nil(t, hosts)
+
+	w.maintenanceConfig = &kubernetes.ResourceLocation{Namespace: "kube-system", Name: "maintenance"}
+	hosts, err = w.getMaintenanceHosts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a.org": true, "b.org": true, "c.org": true}, hosts)
+}
+
+func TestAttachCapacityReservations(t *testing.T) {
+	now := time.Date(2026, 11, 28, 0, 0, 0, 0, time.UTC)
+	reservations := []aws.CapacityReservation{
+		{Hosts: []string{"a.org"}, CapacityUnits: 1500, From: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		{Hosts: []string{"b.org"}, CapacityUnits: 500, From: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour)},
+	}
+	reserved := &loadBalancer{
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Hostnames: []string{"a.org", "b.org"}}},
+		},
+	}
+	expired := &loadBalancer{
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Hostnames: []string{"b.org"}}},
+		},
+		capacityUnits: 500,
+	}
+
+	attachCapacityReservations([]*loadBalancer{reserved, expired}, reservations, now)
+
+	assert.Equal(t, 1500, reserved.capacityUnits)
+	assert.Equal(t, 0, expired.capacityUnits)
+}
+
+func TestGetCapacityReservations(t *testing.T) {
+	kubeAPI := &kubemock.API{}
+	kubeAPI.On("GetConfigMap", "kube-system", "capacity").Return(&kubernetes.ConfigMap{
+		Data: map[string]string{"reservations": "- {hosts: [a.org], capacityUnits: 100, from: 2026-11-27T00:00:00Z, until: 2026-11-30T00:00:00Z}"},
+	}, nil)
+	kubeAPI.On("GetConfigMap", "kube-system", "invalid").Return(&kubernetes.ConfigMap{
+		Data: map[string]string{"reservations": "- {hosts: [a.org], capacityUnits: 100}"},
+	}, nil)
+
+	w := &worker{kubeAPI: kubeAPI}
+	reservations, err := w.getCapacityReservations()
+	require.NoError(t, err)
+	assert.Nil(t, reservations)
+
+	w.capacityReservationConfig = &kubernetes.ResourceLocation{Namespace: "kube-system", Name: "capacity"}
+	reservations, err = w.getCapacityReservations()
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	assert.Equal(t, 100, reservations[0].CapacityUnits)
+
+	w.capacityReservationConfig = &kubernetes.ResourceLocation{Namespace: "kube-system", Name: "invalid"}
+	_, err = w.getCapacityReservations()
+	assert.Error(t, err)
+}
+
+func TestAttachFleetWeights(t *testing.T) {
+	alb := &loadBalancer{loadBalancerType: aws.LoadBalancerTypeApplication}
+	annotated := &loadBalancer{loadBalancerType: aws.LoadBalancerTypeApplication, fleetWeights: "canary=50"}
+	restricted := &loadBalancer{loadBalancerType: aws.LoadBalancerTypeApplication, sourceRangesHash: aws.SourceRangesHash("10.0.0.0/8")}
+	nlb := &loadBalancer{loadBalancerType: aws.LoadBalancerTypeNetwork}
+
+	attachFleetWeights([]*loadBalancer{alb, annotated, restricted, nlb}, "canary=10")
+
+	assert.Equal(t, "canary=10", alb.fleetWeights)
+	assert.Equal(t, "canary=50", annotated.fleetWeights)
+	assert.Empty(t, restricted.fleetWeights)
+	assert.Empty(t, nlb.fleetWeights)
+}
+
+func TestGetFleetWeights(t *testing.T) {
+	kubeAPI := &kubemock.API{}
+	kubeAPI.On("GetConfigMap", "kube-system", "weights").Return(&kubernetes.ConfigMap{
+		Data: map[string]string{"weights": "edge=5\ncanary=10"},
+	}, nil)
+	kubeAPI.On("GetConfigMap", "kube-system", "invalid").Return(&kubernetes.ConfigMap{
+		Data: map[string]string{"weights": "canary=60, edge=50"},
+	}, nil)
+
+	w := &worker{kubeAPI: kubeAPI}
+	weights, err := w.getFleetWeights()
+	require.NoError(t, err)
+	assert.Empty(t, weights)
+
+	w.fleetWeightsConfig = &kubernetes.ResourceLocation{Namespace: "kube-system", Name: "weights"}
+	weights, err = w.getFleetWeights()
+	require.NoError(t, err)
+	assert.Equal(t, "canary=10,edge=5", weights)
+
+	w.fleetWeightsConfig = &kubernetes.ResourceLocation{Namespace: "kube-system", Name: "invalid"}
+	_, err = w.getFleetWeights()
+	assert.Error(t, err)
+}
+
 func TestIsLBInSync(t *testing.T) {
 	for _, test := range []struct {
 		title  string
@@ -1358,6 +2083,331 @@ func TestIsLBInSync(t *testing.T) {
 			cwAlarms:    aws.CloudWatchAlarmList{{}},
 			wafWebACLID: "foo-bar",
 		},
+	}, {
+		title: "not matching access logs",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				SettingsHash:      aws.SettingsHash(&aws.StackSettings{AccessLogsS3Bucket: "foo-bucket"}),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: aws.SettingsHash(&aws.StackSettings{AccessLogsS3Bucket: "bar-bucket"}),
+		},
+	}, {
+		title: "not matching resource tags",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{Tags: map[string]string{"team": "bar"}}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				ResourceTagsHash:  aws.ResourceTagsHash(map[string]string{"team": "foo"}),
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+	}, {
+		title: "not matching EIP allocations",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				SettingsHash:      aws.SettingsHash(&aws.StackSettings{EIPAllocations: []string{"eipalloc-1", "eipalloc-2"}}),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: aws.SettingsHash(&aws.StackSettings{EIPAllocations: []string{"eipalloc-1", "eipalloc-3"}}),
+		},
+	}, {
+		title: "not matching HTTP listener mode",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{HTTPListenerMode: aws.HTTPListenerModeForward}),
+		},
+	}, {
+		title: "not matching mTLS CA bundle",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				MTLSCABundleHash:  aws.CABundleHash("old"),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			mtlsCABundle: "new",
+		},
+	}, {
+		title: "not matching authentication config",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				AuthConfigHash:    (&aws.AuthConfig{Type: aws.AuthTypeOIDC, ClientSecret: "old"}).Hash(),
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+			auth:     &aws.AuthConfig{Type: aws.AuthTypeOIDC, ClientSecret: "new"},
+		},
+	}, {
+		title: "not matching hostnames in strict hosts mode",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{Hostnames: []string{"foo.org", "bar.org"}}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				HostnamesHash:     aws.HostnamesHash([]string{"foo.org"}),
+			},
+			cwAlarms:    aws.CloudWatchAlarmList{{}},
+			strictHosts: true,
+		},
+	}, {
+		title: "not matching source ranges",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				SettingsHash:      aws.SettingsHash(&aws.StackSettings{SourceRanges: "10.0.0.0/8"}),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{SourceRanges: "10.0.0.0/8,192.168.0.0/16"}),
+		},
+	}, {
+		title: "rotated origin header",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				OriginHeaderHash:  (&aws.OriginHeader{Name: "X-Origin-Secret", Value: "old"}).Hash(),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			originHeader: &aws.OriginHeader{Name: "X-Origin-Secret", Value: "new", PreviousValue: "old"},
+		},
+	}, {
+		title: "hosts put into maintenance",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms:         aws.CloudWatchAlarmList{{}},
+			maintenanceHosts: []string{"foo.org"},
+		},
+	}, {
+		title: "maintenance mode removed",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				Maintenance:       true,
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+	}, {
+		title: "not matching target group attributes",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				SettingsHash:      aws.SettingsHash(&aws.StackSettings{TargetGroupAttributes: "stickiness.enabled=true"}),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{TargetGroupAttributes: "stickiness.enabled=false"}),
+		},
+	}, {
+		title: "not matching load balancer attributes",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				SettingsHash:      aws.SettingsHash(&aws.StackSettings{LoadBalancerAttributes: "waf.fail_open.enabled=true"}),
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+	}, {
+		title: "not matching response headers",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{ResponseHeaders: "X-Frame-Options: DENY"}),
+		},
+	}, {
+		title: "not matching capacity units",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				CapacityUnits:     1500,
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+	}, {
+		title: "not matching listeners",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{Listeners: "HTTP:80,HTTPS:8443"}),
+		},
+	}, {
+		title: "not matching ip address type",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				IpAddressType:     aws.IPAddressTypeDualstack,
+			},
+			cwAlarms:      aws.CloudWatchAlarmList{{}},
+			ipAddressType: aws.IPAddressTypeDualstackWithoutPublicIPv4,
+		},
+	}, {
+		title: "hosts moved to a fleet",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{Hostnames: []string{"foo.org"}, Fleet: "canary"}, {Hostnames: []string{"bar.org"}}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+	}, {
+		title: "in sync with fleets",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{Hostnames: []string{"foo.org"}, Fleet: "canary"}, {Hostnames: []string{"bar.org"}}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				FleetsHash:        aws.FleetsHash(map[string][]string{"canary": {"foo.org"}}),
+			},
+			cwAlarms: aws.CloudWatchAlarmList{{}},
+		},
+		expect: true,
+	}, {
+		title: "target protocol version changed",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			settingsHash: settingsHash(&kubernetes.Ingress{TargetProtocolVersion: aws.TargetProtocolVersionHTTP2}),
+		},
+	}, {
+		title: "fleet weights changed",
+		lb: &loadBalancer{
+			ingresses: map[string][]*kubernetes.Ingress{
+				"foo": {{}},
+			},
+			stack: &aws.Stack{
+				CertificateARNs: map[string]time.Time{
+					"foo": {},
+				},
+				CWAlarmConfigHash: aws.CloudWatchAlarmList{{}}.Hash(),
+				FleetWeightsHash:  aws.FleetWeightsHash("canary=10"),
+			},
+			cwAlarms:     aws.CloudWatchAlarmList{{}},
+			fleetWeights: "canary=50",
+		},
 	}, {
 		title: "in sync",
 		lb: &loadBalancer{
@@ -1386,6 +2436,59 @@ func TestIsLBInSync(t *testing.T) {
 	}
 }
 
+func TestLoadBalancerTags(t *testing.T) {
+	for _, test := range []struct {
+		title     string
+		ingresses map[string][]*kubernetes.Ingress
+		expect    map[string]string
+	}{{
+		title: "no tags",
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Namespace: "a"}},
+		},
+	}, {
+		title: "single ingress",
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {{Namespace: "a", Tags: map[string]string{"team": "x"}}},
+			"bar": {{Namespace: "a", Tags: map[string]string{"team": "x"}}},
+		},
+		expect: map[string]string{"team": "x"},
+	}, {
+		title: "aggregated values are sorted",
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {
+				{Namespace: "b", Tags: map[string]string{"team": "y", "app": "z"}},
+				{Namespace: "a", Tags: map[string]string{"team": "x"}},
+			},
+			"bar": {{Namespace: "c", Tags: map[string]string{"team": "y"}}},
+		},
+		expect: map[string]string{"team": "x y", "app": "z"},
+	}, {
+		title: "aggregated values are truncated",
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {
+				{Tags: map[string]string{"team": strings.Repeat("a", 200)}},
+				{Tags: map[string]string{"team": strings.Repeat("b", 200)}},
+			},
+		},
+		expect: map[string]string{"team": strings.Repeat("a", 200) + " " + strings.Repeat("b", 55)},
+	}, {
+		title: "aggregated values are truncated on character boundaries",
+		ingresses: map[string][]*kubernetes.Ingress{
+			"foo": {
+				{Tags: map[string]string{"team": strings.Repeat("a", 200)}},
+				{Tags: map[string]string{"team": strings.Repeat("ü", 200)}},
+			},
+		},
+		expect: map[string]string{"team": strings.Repeat("a", 200) + " " + strings.Repeat("ü", 55)},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			lb := &loadBalancer{ingresses: test.ingresses}
+			require.Equal(t, test.expect, lb.Tags())
+		})
+	}
+}
+
 func TestMatchIngressesToLoadbalancers(t *testing.T) {
 	defaultMaxCertsPerLB := 3
 	defaultCerts := certsfake.NewCert(